@@ -0,0 +1,109 @@
+package main
+
+// clientImports are the packages clientSource uses.
+var clientImports = map[string]bool{
+	"context": true, "encoding/json": true, "errors": true, "io": true,
+	"net/http": true, "net/url": true, "strings": true,
+}
+
+// clientSource is the part of every client that doesn't depend on the document; %s is
+// the document's title.
+const clientSource = `// Client calls the %s.
+type Client struct {
+	// BaseURL is where the service is, such as http://localhost:8080
+	BaseURL string
+	// HTTPClient sends the requests; http.DefaultClient when nil
+	HTTPClient *http.Client
+	// Editors change every request before it's sent, such as to add credentials
+	Editors []func(*http.Request)
+	// Inspect, when set, sees every response before it's read, such as to pass its
+	// cookies on
+	Inspect func(*http.Response)
+}
+
+// Error is a response with an error status.
+type Error struct {
+	StatusCode int
+	// Problem is the service's account of what went wrong; when the body isn't a
+	// problem, its Detail is the body
+	Problem Problem
+}
+
+func (e *Error) Error() string {
+	if e.Problem.Detail == "" {
+		return http.StatusText(e.StatusCode)
+	}
+	return e.Problem.Detail
+}
+
+// StatusCode is the status of an *Error in err's chain, or 0 when there isn't one.
+func StatusCode(err error) int {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// Code is the problem code of an *Error in err's chain, such as trip_not_found, or ""
+// when there isn't one.
+func Code(err error) string {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Problem.Code
+	}
+	return ""
+}
+
+// send makes a request and returns the response when it succeeded, or an *Error.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, contentType string, body io.Reader) (*http.Response, error) {
+	target := strings.TrimSuffix(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for _, edit := range c.Editors {
+		edit(req)
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if c.Inspect != nil {
+		c.Inspect(resp)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		apiErr := &Error{StatusCode: resp.StatusCode}
+		if json.Unmarshal(b, &apiErr.Problem) != nil || apiErr.Problem.Code == "" {
+			apiErr.Problem = Problem{Status: resp.StatusCode, Detail: strings.TrimSpace(string(b))}
+		}
+		return nil, apiErr
+	}
+	return resp, nil
+}
+
+// do makes a request and decodes its JSON response into out, unless out is nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, contentType string, body io.Reader, out any) error {
+	resp, err := c.send(ctx, method, path, query, contentType, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+`
@@ -0,0 +1,462 @@
+// Command clientgen writes a typed Go client from a service's OpenAPI document, so the
+// frontend calls the trips and users services through the same contract they check
+// themselves against. Run it through go generate:
+//
+//	//go:generate go run ../../cmd/clientgen -spec ../../../trips/internal/api/openapi.json -out client_gen.go
+//
+// Every component schema becomes a struct, or a string type with constants when it's
+// an enum. Every operation becomes a method named after its operationId, taking its
+// path parameters, a <Method>Params struct for its query and its request body, and
+// returning its first JSON success response. Operations that only answer with files
+// or calendars return the *http.Response for the caller to read and close.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+func main() {
+	spec := flag.String("spec", "openapi.json", "OpenAPI document")
+	out := flag.String("out", "client_gen.go", "generated Go file")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "package of the generated file")
+	flag.Parse()
+
+	b, err := os.ReadFile(*spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var doc document
+	if err = json.Unmarshal(b, &doc); err != nil {
+		log.Fatalf("%s: %v", *spec, err)
+	}
+
+	g := &generator{doc: &doc, imports: map[string]bool{}}
+	g.p(clientSource, doc.Info.Title)
+	for _, name := range doc.Components.Schemas.Keys {
+		if err = g.schema(name, doc.Components.Schemas.Values[name]); err != nil {
+			log.Fatalf("%s: schema %s: %v", *spec, name, err)
+		}
+	}
+	for _, path := range doc.Paths.Keys {
+		ops := doc.Paths.Values[path]
+		for _, method := range ops.Keys {
+			if err = g.operation(path, strings.ToUpper(method), ops.Values[method]); err != nil {
+				log.Fatalf("%s: %s %s: %v", *spec, strings.ToUpper(method), path, err)
+			}
+		}
+	}
+	var file bytes.Buffer
+	fmt.Fprintf(&file, "// Code generated by clientgen from %s; DO NOT EDIT.\n\n", *spec)
+	fmt.Fprintf(&file, "package %s\n\nimport (\n", *pkg)
+	for _, imp := range []string{"bytes", "context", "encoding/json", "errors", "io", "net/http", "net/url", "strconv", "strings"} {
+		if clientImports[imp] || g.imports[imp] {
+			fmt.Fprintf(&file, "%q\n", imp)
+		}
+	}
+	file.WriteString(")\n\n")
+	file.Write(g.buf.Bytes())
+	src, err := format.Source(file.Bytes())
+	if err != nil {
+		log.Fatalf("formatting generated code: %v", err)
+	}
+	if err = os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type generator struct {
+	doc *document
+	buf bytes.Buffer
+	// imports are the packages the operations need beyond the client's own
+	imports map[string]bool
+}
+
+func (g *generator) p(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+	g.buf.WriteByte('\n')
+}
+
+// comment writes text as a doc comment, or nothing when it's empty.
+func (g *generator) comment(text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if line != "" {
+			g.p("// %s", line)
+		}
+	}
+}
+
+func (g *generator) schema(name string, s *schema) error {
+	doc := ""
+	if s.Description != "" {
+		doc = name + " is " + lowerFirst(s.Description)
+	}
+	switch {
+	case len(s.Enum) > 0:
+		g.comment(doc)
+		g.p("type %s string\n", name)
+		g.p("const (")
+		for _, v := range s.Enum {
+			g.p("%s%s %s = %q", name, goName(v), name, v)
+		}
+		g.p(")\n")
+	case s.Type.main() == "object":
+		g.comment(doc)
+		g.p("type %s struct {", name)
+		for _, prop := range s.Properties.Keys {
+			p := s.Properties.Values[prop]
+			required := slices.Contains(s.Required, prop)
+			typ, err := g.goType(p, required)
+			if err != nil {
+				return fmt.Errorf("%s: %w", prop, err)
+			}
+			g.comment(p.Description)
+			tag := prop
+			if !required {
+				tag += ",omitempty"
+			}
+			g.p("%s %s `json:%q`", goName(prop), typ, tag)
+		}
+		g.p("}\n")
+	default:
+		typ, err := g.goType(s, true)
+		if err != nil {
+			return err
+		}
+		g.comment(doc)
+		g.p("type %s %s\n", name, typ)
+	}
+	return nil
+}
+
+// goType is the Go type for a schema. Optional objects are pointers so they can be left
+// out; nullable scalars are pointers so null and zero stay apart.
+func (g *generator) goType(s *schema, required bool) (string, error) {
+	if s.Ref != "" {
+		name := refName(s.Ref)
+		target, ok := g.doc.Components.Schemas.Values[name]
+		if !ok {
+			return "", fmt.Errorf("unknown schema %s", s.Ref)
+		}
+		if target.Type.main() == "object" && !required {
+			return "*" + name, nil
+		}
+		return name, nil
+	}
+	if len(s.OneOf) > 0 {
+		return "json.RawMessage", nil
+	}
+	var typ string
+	switch s.Type.main() {
+	case "string":
+		typ = "string"
+	case "integer":
+		typ = "int"
+		if s.Format == "int64" {
+			typ = "int64"
+		}
+	case "number":
+		typ = "float64"
+	case "boolean":
+		typ = "bool"
+	case "array":
+		if s.Items == nil {
+			return "[]any", nil
+		}
+		item, err := g.goType(s.Items, true)
+		return "[]" + item, err
+	case "object":
+		if s.AdditionalProperties != nil {
+			item, err := g.goType(s.AdditionalProperties, true)
+			return "map[string]" + item, err
+		}
+		return "map[string]any", nil
+	case "":
+		return "any", nil
+	default:
+		return "", fmt.Errorf("unsupported type %v", s.Type)
+	}
+	if s.Type.is("null") {
+		typ = "*" + typ
+	}
+	return typ, nil
+}
+
+func (g *generator) operation(path, method string, op *operation) error {
+	if op.OperationID == "" {
+		return fmt.Errorf("no operationId")
+	}
+	name := upperFirst(op.OperationID)
+
+	var args, pathParams []string
+	var query []parameter
+	args = append(args, "ctx context.Context")
+	for _, prm := range op.Parameters {
+		switch prm.In {
+		case "path":
+			pathParams = append(pathParams, prm.Name)
+			args = append(args, lowerName(prm.Name)+" string")
+		case "query":
+			query = append(query, prm)
+		default:
+			return fmt.Errorf("unsupported %s parameter %s", prm.In, prm.Name)
+		}
+	}
+	if len(query) > 0 {
+		g.p("// %sParams is the query of %s.", name, name)
+		g.p("type %sParams struct {", name)
+		for _, prm := range query {
+			typ, err := g.goType(prm.Schema, true)
+			if err != nil {
+				return fmt.Errorf("%s: %w", prm.Name, err)
+			}
+			desc := prm.Description
+			if prm.Required {
+				desc = strings.TrimSpace(desc + "\nRequired.")
+			}
+			g.comment(desc)
+			g.p("%s %s", goName(prm.Name), typ)
+		}
+		g.p("}\n")
+		args = append(args, "params "+name+"Params")
+	}
+
+	// the request body: JSON is typed, forms get a struct of their own, and anything
+	// else, such as uploads and imports, is passed through as it is
+	var bodyKind, formType string
+	var form *schema
+	if rb := op.RequestBody; rb != nil {
+		switch {
+		case rb.Content.Values["application/json"].Schema != nil:
+			typ, err := g.goType(rb.Content.Values["application/json"].Schema, true)
+			if err != nil {
+				return fmt.Errorf("request body: %w", err)
+			}
+			bodyKind = "json"
+			args = append(args, "body "+typ)
+		case rb.Content.Values["application/x-www-form-urlencoded"].Schema != nil:
+			form = rb.Content.Values["application/x-www-form-urlencoded"].Schema
+			formType = name + "Form"
+			bodyKind = "form"
+			g.p("// %s is the form %s sends.", formType, name)
+			g.p("type %s struct {", formType)
+			for _, prop := range form.Properties.Keys {
+				p := form.Properties.Values[prop]
+				typ, err := g.goType(p, true)
+				if err != nil {
+					return fmt.Errorf("form %s: %w", prop, err)
+				}
+				g.comment(p.Description)
+				g.p("%s %s", goName(prop), typ)
+			}
+			g.p("}\n")
+			args = append(args, "body "+formType)
+		default:
+			bodyKind = "raw"
+			args = append(args, "contentType string", "body io.Reader")
+		}
+	}
+
+	// the result is the first success response that's JSON
+	result, resultKind := "", "raw"
+	for _, code := range op.Responses.Keys {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		mt, ok := op.Responses.Values[code].Content.Values["application/json"]
+		if !ok || mt.Schema == nil {
+			continue
+		}
+		switch {
+		case mt.Schema.Type.main() == "" && mt.Schema.Type.is("null"):
+			resultKind = "none"
+		case mt.Schema.Ref != "":
+			result, resultKind = refName(mt.Schema.Ref), "pointer"
+		default:
+			typ, err := g.goType(mt.Schema, true)
+			if err != nil {
+				return fmt.Errorf("response %s: %w", code, err)
+			}
+			result, resultKind = typ, "value"
+		}
+		break
+	}
+
+	summary := lowerFirst(strings.TrimSuffix(op.Summary, "."))
+	g.p("// %s calls %s %s: %s.", name, method, path, summary)
+	if resultKind == "raw" {
+		g.p("// The caller reads and closes the response body.")
+	}
+	var returns string
+	switch resultKind {
+	case "none":
+		returns = "error"
+	case "pointer":
+		returns = "(*" + result + ", error)"
+	case "value":
+		returns = "(" + result + ", error)"
+	default:
+		returns = "(*http.Response, error)"
+	}
+	g.p("func (c *Client) %s(%s) %s {", name, strings.Join(args, ", "), returns)
+
+	fail := "return nil, err"
+	switch resultKind {
+	case "none":
+		fail = "return err"
+	case "value":
+		fail = "var zero " + result + "\nreturn zero, err"
+	}
+
+	// path
+	target := path
+	var pathExpr []string
+	for _, prm := range pathParams {
+		before, after, _ := strings.Cut(target, "{"+prm+"}")
+		pathExpr = append(pathExpr, fmt.Sprintf("%q", before), "url.PathEscape("+lowerName(prm)+")")
+		target = after
+	}
+	if target != "" || len(pathExpr) == 0 {
+		pathExpr = append(pathExpr, fmt.Sprintf("%q", target))
+	}
+	g.p("path := %s", strings.Join(pathExpr, " + "))
+
+	// query
+	queryArg := "nil"
+	if len(query) > 0 {
+		queryArg = "query"
+		g.p("query := url.Values{}")
+		for _, prm := range query {
+			typ, _ := g.goType(prm.Schema, true)
+			g.setValue("query", prm.Name, "params."+goName(prm.Name), typ, prm.Schema)
+		}
+	}
+
+	// body
+	contentType, bodyArg := `""`, "nil"
+	switch bodyKind {
+	case "json":
+		g.imports["bytes"] = true
+		g.p("b, err := json.Marshal(body)")
+		g.p("if err != nil {\n%s\n}", fail)
+		contentType, bodyArg = `"application/json"`, "bytes.NewReader(b)"
+	case "form":
+		g.p("form := url.Values{}")
+		for _, prop := range form.Properties.Keys {
+			p := form.Properties.Values[prop]
+			typ, _ := g.goType(p, true)
+			if slices.Contains(form.Required, prop) && typ == "string" {
+				g.p("form.Set(%q, body.%s)", prop, goName(prop))
+				continue
+			}
+			g.setValue("form", prop, "body."+goName(prop), typ, p)
+		}
+		contentType, bodyArg = `"application/x-www-form-urlencoded"`, "strings.NewReader(form.Encode())"
+	case "raw":
+		contentType, bodyArg = "contentType", "body"
+	}
+
+	call := fmt.Sprintf("http.Method%s, path, %s, %s, %s", methodConst(method), queryArg, contentType, bodyArg)
+	switch resultKind {
+	case "none":
+		g.p("return c.do(ctx, %s, nil)", call)
+	case "pointer":
+		g.p("var out %s", result)
+		g.p("if err := c.do(ctx, %s, &out); err != nil {\nreturn nil, err\n}", call)
+		g.p("return &out, nil")
+	case "value":
+		g.p("var out %s", result)
+		g.p("err := c.do(ctx, %s, &out)", call)
+		g.p("return out, err")
+	default:
+		g.p("return c.send(ctx, %s)", call)
+	}
+	g.p("}\n")
+	return nil
+}
+
+// setValue writes the code that adds a field to url.Values when it isn't the zero value.
+func (g *generator) setValue(values, key, field, typ string, s *schema) {
+	switch {
+	case typ == "string":
+		g.p("if %s != \"\" {\n%s.Set(%q, %s)\n}", field, values, key, field)
+	case typ == "int":
+		g.imports["strconv"] = true
+		g.p("if %s != 0 {\n%s.Set(%q, strconv.Itoa(%s))\n}", field, values, key, field)
+	case typ == "bool":
+		g.p("if %s {\n%s.Set(%q, \"true\")\n}", field, values, key)
+	case s.Ref != "":
+		// string enums
+		g.p("if %s != \"\" {\n%s.Set(%q, string(%s))\n}", field, values, key, field)
+	default:
+		log.Fatalf("%s: can't send a %s in a query or form", key, typ)
+	}
+}
+
+func methodConst(method string) string {
+	return string(method[0]) + strings.ToLower(method[1:])
+}
+
+// initialisms are spelled in capitals in Go names.
+var initialisms = map[string]string{
+	"id": "ID", "ids": "IDs", "url": "URL", "uri": "URI", "usd": "USD", "mfa": "MFA",
+	"totp": "TOTP", "oidc": "OIDC", "api": "API", "csv": "CSV", "json": "JSON",
+	"ndjson": "NDJSON", "geojson": "GeoJSON", "http": "HTTP", "ip": "IP", "rrule": "RRule",
+	"bbox": "BBox", "utc": "UTC",
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// goName is the exported Go name for a JSON name such as trip_id.
+func goName(s string) string {
+	var b strings.Builder
+	for _, w := range words(s) {
+		if i, ok := initialisms[strings.ToLower(w)]; ok {
+			b.WriteString(i)
+			continue
+		}
+		b.WriteString(upperFirst(w))
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "V" + name
+	}
+	return name
+}
+
+// lowerName is the unexported Go name for a JSON name, for arguments.
+func lowerName(s string) string {
+	w := words(s)
+	name := strings.ToLower(w[0])
+	if len(w) > 1 {
+		name += goName(strings.Join(w[1:], "_"))
+	}
+	return name
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// lowerFirst lowers the first letter of a sentence, unless it starts an acronym.
+func lowerFirst(s string) string {
+	if len(s) > 1 && unicode.IsUpper(rune(s[1])) {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
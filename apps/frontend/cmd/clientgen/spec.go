@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ordered is a JSON object that remembers its key order, so generated code follows the
+// order of the document rather than the alphabet.
+type ordered[T any] struct {
+	Keys   []string
+	Values map[string]T
+}
+
+func (o *ordered[T]) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return fmt.Errorf("expected an object")
+	}
+	o.Values = map[string]T{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := tok.(string)
+		var v T
+		if err = dec.Decode(&v); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		o.Keys = append(o.Keys, key)
+		o.Values[key] = v
+	}
+	return nil
+}
+
+// document is the part of an OpenAPI 3.1 document the generator understands.
+type document struct {
+	Info struct {
+		Title string `json:"title"`
+	} `json:"info"`
+	Paths      ordered[ordered[*operation]] `json:"paths"`
+	Components struct {
+		Schemas ordered[*schema] `json:"schemas"`
+	} `json:"components"`
+}
+
+type operation struct {
+	OperationID string      `json:"operationId"`
+	Summary     string      `json:"summary"`
+	Parameters  []parameter `json:"parameters"`
+	RequestBody *struct {
+		Required bool               `json:"required"`
+		Content  ordered[mediaType] `json:"content"`
+	} `json:"requestBody"`
+	Responses ordered[struct {
+		Content ordered[mediaType] `json:"content"`
+	}] `json:"responses"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *schema `json:"schema"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref                  string           `json:"$ref"`
+	Description          string           `json:"description"`
+	Type                 schemaType       `json:"type"`
+	Format               string           `json:"format"`
+	Enum                 []string         `json:"enum"`
+	Items                *schema          `json:"items"`
+	Properties           ordered[*schema] `json:"properties"`
+	AdditionalProperties *schema          `json:"additionalProperties"`
+	Required             []string         `json:"required"`
+	OneOf                []*schema        `json:"oneOf"`
+}
+
+// schemaType is a type name, or a list of them such as ["integer", "null"].
+type schemaType []string
+
+func (t *schemaType) UnmarshalJSON(data []byte) error {
+	var one string
+	if json.Unmarshal(data, &one) == nil {
+		*t = schemaType{one}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// is reports whether the schema allows the type.
+func (t schemaType) is(name string) bool {
+	for _, n := range t {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// main is the schema's type other than null.
+func (t schemaType) main() string {
+	for _, n := range t {
+		if n != "null" {
+			return n
+		}
+	}
+	return ""
+}
+
+func refName(ref string) string {
+	return strings.TrimPrefix(ref, "#/components/schemas/")
+}
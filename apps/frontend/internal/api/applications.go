@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Taiterbase/vtrips/apps/frontend/internal/tripsclient"
+	"github.com/Taiterbase/vtrips/apps/frontend/web/views"
+	"github.com/a-h/templ"
+	"github.com/labstack/echo/v4"
+)
+
+// tripsRequest calls the trips service on behalf of the signed-in user by forwarding
+// their auth cookie, which the trips service validates itself.
+func tripsRequest(c echo.Context, method, path string, body io.Reader) (*http.Response, error) {
+	return tripsRequestType(c, method, path, "application/x-www-form-urlencoded", body)
+}
+
+// tripsRequestType is tripsRequest for bodies that aren't form-encoded.
+func tripsRequestType(c echo.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, tripsBaseURL()+path, body)
+	if err != nil {
+		return nil, err
+	}
+	forwardAuth(c)(req)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return serviceHTTPClient.Do(req)
+}
+
+// tripsJSON performs a trips request and decodes its JSON object response.
+func tripsJSON(c echo.Context, method, path string, body io.Reader) (map[string]any, int, error) {
+	return decodeTripsResponse(tripsRequest(c, method, path, body))
+}
+
+// decodeTripsResponse decodes a JSON object response, turning error statuses into an
+// error carrying the detail of the trips service's problem.
+func decodeTripsResponse(resp *http.Response, err error) (map[string]any, int, error) {
+	if err != nil {
+		return nil, http.StatusBadGateway, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, http.StatusBadGateway, err
+	}
+	if resp.StatusCode >= 400 {
+		var problem tripsclient.Problem
+		if json.Unmarshal(raw, &problem) != nil || problem.Detail == "" {
+			problem.Detail = strings.TrimSpace(string(raw))
+		}
+		return nil, resp.StatusCode, fmt.Errorf("%s", problem.Detail)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, http.StatusBadGateway, err
+	}
+	return payload, resp.StatusCode, nil
+}
+
+// fetchApplications lists the signed-in org member's applications; the trips service
+// scopes them to the org in the session token.
+func fetchApplications(c echo.Context, status string) ([]views.ApplicationSummary, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	payload, _, err := tripsJSON(c, http.MethodGet, "/v1/applications?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	list, _ := payload["applications"].([]any)
+	summaries := make([]views.ApplicationSummary, 0, len(list))
+	for _, entry := range list {
+		if app, ok := entry.(map[string]any); ok {
+			summaries = append(summaries, views.NewApplicationSummaryFromPayload(app))
+		}
+	}
+	return summaries, nil
+}
+
+func orgDashboardHandler(c echo.Context) error {
+	pending, err := fetchApplications(c, "pending")
+	if err != nil {
+		c.Logger().Error(err)
+	}
+	templ.Handler(views.OrgDashboardPage(len(pending))).ServeHTTP(c.Response().Writer, c.Request())
+	return nil
+}
+
+func applicationsIndexHandler(c echo.Context) error {
+	status := c.QueryParam("status")
+	apps, err := fetchApplications(c, status)
+	if err != nil {
+		return c.JSON(http.StatusBadGateway, err.Error())
+	}
+	templ.Handler(views.ApplicationsIndexPage(apps, status)).ServeHTTP(c.Response().Writer, c.Request())
+	return nil
+}
+
+func renderApplication(c echo.Context, applicationID, errMsg string) error {
+	payload, status, err := tripsJSON(c, http.MethodGet, "/v1/applications/"+url.PathEscape(applicationID), nil)
+	if err != nil {
+		return c.JSON(status, err.Error())
+	}
+	detail := views.NewApplicationDetailFromPayload(payload)
+	detail.Error = errMsg
+	templ.Handler(views.ApplicationShowPage(detail)).ServeHTTP(c.Response().Writer, c.Request())
+	return nil
+}
+
+func applicationShowHandler(c echo.Context) error {
+	return renderApplication(c, c.Param("application_id"), "")
+}
+
+// applicationGradeHandler forwards the org's decision and shows the application again,
+// with the trips service's message when the decision was refused (e.g. the trip is full).
+func applicationGradeHandler(c echo.Context) error {
+	applicationID := c.Param("application_id")
+	form := url.Values{
+		"decision": {c.FormValue("decision")},
+		"note":     {c.FormValue("note")},
+	}
+	_, status, err := tripsJSON(c, http.MethodPost, "/v1/applications/"+url.PathEscape(applicationID)+"/decision", strings.NewReader(form.Encode()))
+	if err != nil {
+		if status >= 500 {
+			return c.JSON(status, err.Error())
+		}
+		return renderApplication(c, applicationID, err.Error())
+	}
+	return c.Redirect(http.StatusSeeOther, "/org/applications/"+applicationID)
+}
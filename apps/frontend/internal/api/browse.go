@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Taiterbase/vtrips/apps/frontend/web/views"
+	"github.com/a-h/templ"
+	"github.com/labstack/echo/v4"
+)
+
+// fetchBrowse loads one page of listed trips for the filters, marking the ones the
+// signed-in user has liked.
+func fetchBrowse(c echo.Context, filters views.BrowseFilters, after string) ([]views.TripCard, string, error) {
+	query := filters.Query()
+	if after != "" {
+		query.Set("after", after)
+	}
+	payload, _, err := tripsJSON(c, http.MethodGet, "/v1/public/trips?"+query.Encode(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	liked := likedTripIDs(c)
+	list, _ := payload["trips"].([]any)
+	cards := make([]views.TripCard, 0, len(list))
+	for _, entry := range list {
+		if trip, ok := entry.(map[string]any); ok {
+			id, _ := trip["id"].(string)
+			cards = append(cards, views.NewTripCardFromPayload(trip, liked[id]))
+		}
+	}
+	next, _ := payload["next"].(string)
+	return cards, next, nil
+}
+
+// likedTripIDs returns the trips the signed-in user has liked. A signed-out visitor, or a
+// stale or rejected session, only costs the filled-in hearts.
+func likedTripIDs(c echo.Context) map[string]bool {
+	liked := map[string]bool{}
+	if !isLoggedIn(c) {
+		return liked
+	}
+	payload, _, err := tripsJSON(c, http.MethodGet, "/v1/users/me/likes/ids", nil)
+	if err != nil {
+		return liked
+	}
+	list, _ := payload["trip_ids"].([]any)
+	for _, id := range list {
+		if s, ok := id.(string); ok {
+			liked[s] = true
+		}
+	}
+	return liked
+}
+
+// browseHandler renders the public browse page, or just the next page of cards when the
+// infinite-scroll sentinel asks for one with ?after=.
+func browseHandler(c echo.Context) error {
+	filters := views.BrowseFiltersFromQuery(c.QueryParams())
+	after := c.QueryParam("after")
+	cards, next, err := fetchBrowse(c, filters, after)
+	if err != nil {
+		return c.JSON(http.StatusBadGateway, err.Error())
+	}
+	cmp := views.BrowsePage(isLoggedIn(c), filters, tripEnums(c), cards, next)
+	if after != "" {
+		cmp = views.BrowseCards(filters, cards, next)
+	}
+	templ.Handler(cmp).ServeHTTP(c.Response().Writer, c.Request())
+	return nil
+}
+
+// browseMapHandler returns the browse map's GeoJSON for the filters and the map's visible
+// ?bbox= and ?zoom=; the trips service clusters nearby trips for the zoom.
+func browseMapHandler(c echo.Context) error {
+	query := views.BrowseFiltersFromQuery(c.QueryParams()).Query()
+	query.Set("format", "geojson")
+	for _, key := range []string{"bbox", "zoom"} {
+		if v := c.QueryParam(key); v != "" {
+			query.Set(key, v)
+		}
+	}
+	payload, status, err := tripsJSON(c, http.MethodGet, "/v1/public/trips?"+query.Encode(), nil)
+	if err != nil {
+		return c.JSON(status, err.Error())
+	}
+	return c.JSON(http.StatusOK, views.BrowseMapFromPayload(payload))
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/labstack/echo/v4"
+)
+
+// calendarHandler serves an iCalendar file or feed from the trips service. Calendar apps
+// fetch feeds on their own, so these routes never need a session.
+func calendarHandler(path func(c echo.Context) string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		resp, err := tripsRequest(c, http.MethodGet, path(c), nil)
+		if err != nil {
+			return c.JSON(http.StatusBadGateway, err.Error())
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return c.NoContent(resp.StatusCode)
+		}
+		if cd := resp.Header.Get(echo.HeaderContentDisposition); cd != "" {
+			c.Response().Header().Set(echo.HeaderContentDisposition, cd)
+		}
+		return c.Stream(resp.StatusCode, resp.Header.Get("Content-Type"), resp.Body)
+	}
+}
+
+// tripCalendarPath only reaches listed trips; no org_id is passed along.
+func tripCalendarPath(c echo.Context) string {
+	return fmt.Sprintf("/v1/trips/%s.ics", url.PathEscape(c.Param("trip_id")))
+}
+
+func orgCalendarPath(c echo.Context) string {
+	return fmt.Sprintf("/v1/orgs/%s/calendar.ics", url.PathEscape(c.Param("org_id")))
+}
+
+func userCalendarPath(c echo.Context) string {
+	return "/v1/calendars/" + url.PathEscape(c.Param("token"))
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/frontend/internal/tripsclient"
+	"github.com/Taiterbase/vtrips/apps/frontend/internal/usersclient"
+	"github.com/labstack/echo/v4"
+)
+
+// serviceHTTPClient sends the generated clients' requests.
+var serviceHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// forwardAuth passes the browser's auth cookie on, which the services validate themselves.
+func forwardAuth(c echo.Context) func(*http.Request) {
+	return func(req *http.Request) {
+		if ck, err := c.Cookie("auth_token"); err == nil {
+			req.AddCookie(&http.Cookie{Name: ck.Name, Value: ck.Value})
+		}
+	}
+}
+
+// tripsClient calls the trips service on behalf of the signed-in user, like tripsRequest.
+func tripsClient(c echo.Context) *tripsclient.Client {
+	return &tripsclient.Client{
+		BaseURL:    tripsBaseURL(),
+		HTTPClient: serviceHTTPClient,
+		Editors:    []func(*http.Request){forwardAuth(c)},
+	}
+}
+
+// usersClient calls the users service on behalf of the browser, handing back any
+// cookies the users service sets, such as a new session.
+func usersClient(c echo.Context) *usersclient.Client {
+	return &usersclient.Client{
+		BaseURL:    usersBaseURL(),
+		HTTPClient: serviceHTTPClient,
+		Editors:    []func(*http.Request){forwardAuth(c)},
+		Inspect: func(resp *http.Response) {
+			for _, v := range resp.Header.Values("Set-Cookie") {
+				c.Response().Header().Add("Set-Cookie", v)
+			}
+		},
+	}
+}
+
+// clientStatus is the status to answer a failed service call with: the service's own
+// for the errors it returned, and 502 when it couldn't be reached.
+func clientStatus(err error) int {
+	if status := tripsclient.StatusCode(err); status != 0 {
+		return status
+	}
+	if status := usersclient.StatusCode(err); status != 0 {
+		return status
+	}
+	return http.StatusBadGateway
+}
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"github.com/Taiterbase/vtrips/apps/frontend/internal/graph"
+	"github.com/labstack/echo/v4"
+)
+
+// graphqlEndpoint is built once, as building it loads the schema.
+var graphqlEndpoint = graph.NewHandler()
+
+// graphqlHandler answers the GraphQL API for the signed-in user, calling the services
+// with their auth cookie like the pages do.
+func graphqlHandler(c echo.Context) error {
+	ctx := graph.WithServices(c.Request().Context(), graph.Services{
+		Trips:    tripsClient(c),
+		Users:    usersClient(c),
+		SignedIn: isLoggedIn(c),
+	})
+	graphqlEndpoint.ServeHTTP(c.Response(), c.Request().WithContext(ctx))
+	return nil
+}
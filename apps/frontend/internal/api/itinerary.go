@@ -0,0 +1,197 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Taiterbase/vtrips/apps/frontend/internal/tripsclient"
+	"github.com/Taiterbase/vtrips/apps/frontend/web/views"
+	"github.com/labstack/echo/v4"
+)
+
+func tripItineraryPath(tripID, orgID string) string {
+	return fmt.Sprintf("/v1/trips/%s/itinerary?org_id=%s", url.PathEscape(tripID), url.QueryEscape(orgID))
+}
+
+// renderItineraryStep renders the wizard's itinerary step, with errMsg shown above the
+// days when the last change was rejected.
+func renderItineraryStep(c echo.Context, tripID, errMsg string) error {
+	orgID := currentOrgID(c)
+	trip, err := tripsClient(c).GetTrip(c.Request().Context(), tripID, tripsclient.GetTripParams{OrgID: orgID})
+	if err != nil {
+		return c.JSON(clientStatus(err), err.Error())
+	}
+	itinerary, status, err := tripsJSON(c, http.MethodGet, tripItineraryPath(tripID, orgID), nil)
+	if err != nil {
+		return c.JSON(status, err.Error())
+	}
+
+	summaries, err := fetchTripSummaries(c, orgID)
+	if err != nil {
+		summaries = nil
+	}
+	data := views.TripsWizardData{Step: views.TripWizardStepItinerary, TripID: tripID, OrgID: orgID, Form: views.TripFormFromTrip(trip)}
+	wizard := views.TripsWizardItinerary(data, views.NewItineraryFromPayload(itinerary), errMsg)
+	summary := views.TripsSummaryList(summaries)
+	if isHXRequest(c) {
+		return renderWizardPartial(c, wizard, summary)
+	}
+	return renderTripsPage(c, "Friend", wizard, summary)
+}
+
+// editItinerary applies edit to the trip's days and saves the whole itinerary back; the
+// trips service only replaces it as a unit. The step is re-rendered either way, showing
+// the trips service's message if it rejected the change.
+func editItinerary(c echo.Context, edit func(days []map[string]any) ([]map[string]any, error)) error {
+	if !isLoggedIn(c) {
+		return c.JSON(http.StatusUnauthorized, "unauthorized")
+	}
+	tripID := c.Param("trip_id")
+	path := tripItineraryPath(tripID, currentOrgID(c))
+	itinerary, status, err := tripsJSON(c, http.MethodGet, path, nil)
+	if err != nil {
+		return c.JSON(status, err.Error())
+	}
+	var days []map[string]any
+	list, _ := itinerary["days"].([]any)
+	for _, entry := range list {
+		if day, ok := entry.(map[string]any); ok {
+			days = append(days, day)
+		}
+	}
+	if days, err = edit(days); err != nil {
+		return renderItineraryStep(c, tripID, err.Error())
+	}
+	if days == nil {
+		days = []map[string]any{}
+	}
+	body, err := json.Marshal(map[string]any{"days": days})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	if _, _, err = decodeTripsResponse(tripsRequestType(c, http.MethodPut, path, "application/json", bytes.NewReader(body))); err != nil {
+		return renderItineraryStep(c, tripID, err.Error())
+	}
+	return renderItineraryStep(c, tripID, "")
+}
+
+// formPlace builds a place from the optional <prefix>name and <prefix>address fields, plus
+// latitude and longitude when withCoords is set. It returns nil if they're all blank.
+func formPlace(c echo.Context, prefix string, withCoords bool) (map[string]any, error) {
+	place := map[string]any{}
+	if v := strings.TrimSpace(c.FormValue(prefix + "name")); v != "" {
+		place["name"] = v
+	}
+	if v := strings.TrimSpace(c.FormValue(prefix + "address")); v != "" {
+		place["address"] = v
+	}
+	if withCoords {
+		for _, key := range []string{"latitude", "longitude"} {
+			v := strings.TrimSpace(c.FormValue(key))
+			if v == "" {
+				continue
+			}
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be a number", key)
+			}
+			place[key] = f
+		}
+	}
+	if len(place) == 0 {
+		return nil, nil
+	}
+	return place, nil
+}
+
+func findDay(days []map[string]any, date string) int {
+	return slices.IndexFunc(days, func(day map[string]any) bool { return day["date"] == date })
+}
+
+func tripsItineraryHandler(c echo.Context) error {
+	if !isLoggedIn(c) {
+		return c.JSON(http.StatusUnauthorized, "unauthorized")
+	}
+	return renderItineraryStep(c, c.Param("trip_id"), "")
+}
+
+// itineraryDaySaveHandler adds a day, or updates the title and housing of an existing one.
+func itineraryDaySaveHandler(c echo.Context) error {
+	return editItinerary(c, func(days []map[string]any) ([]map[string]any, error) {
+		date := c.FormValue("date")
+		housing, err := formPlace(c, "housing_", false)
+		if err != nil {
+			return nil, err
+		}
+		i := findDay(days, date)
+		if i == -1 {
+			days = append(days, map[string]any{"date": date, "items": []any{}})
+			i = len(days) - 1
+		}
+		days[i]["title"] = strings.TrimSpace(c.FormValue("title"))
+		if housing != nil {
+			days[i]["housing"] = housing
+		} else {
+			delete(days[i], "housing")
+		}
+		return days, nil
+	})
+}
+
+func itineraryDayDeleteHandler(c echo.Context) error {
+	return editItinerary(c, func(days []map[string]any) ([]map[string]any, error) {
+		if i := findDay(days, c.Param("date")); i != -1 {
+			days = slices.Delete(days, i, i+1)
+		}
+		return days, nil
+	})
+}
+
+// itineraryItemAddHandler appends an item to the end of a day.
+func itineraryItemAddHandler(c echo.Context) error {
+	return editItinerary(c, func(days []map[string]any) ([]map[string]any, error) {
+		i := findDay(days, c.Param("date"))
+		if i == -1 {
+			return nil, fmt.Errorf("That day is no longer on the itinerary")
+		}
+		item := map[string]any{
+			"kind":        c.FormValue("kind"),
+			"title":       strings.TrimSpace(c.FormValue("title")),
+			"description": strings.TrimSpace(c.FormValue("description")),
+		}
+		if t := c.FormValue("time"); t != "" {
+			item["time"] = t
+		}
+		place, err := formPlace(c, "place_", true)
+		if err != nil {
+			return nil, err
+		}
+		if place != nil {
+			item["place"] = place
+		}
+		items, _ := days[i]["items"].([]any)
+		days[i]["items"] = append(items, item)
+		return days, nil
+	})
+}
+
+func itineraryItemDeleteHandler(c echo.Context) error {
+	return editItinerary(c, func(days []map[string]any) ([]map[string]any, error) {
+		i := findDay(days, c.Param("date"))
+		index, err := strconv.Atoi(c.Param("index"))
+		if i == -1 || err != nil {
+			return days, nil
+		}
+		items, _ := days[i]["items"].([]any)
+		if index >= 0 && index < len(items) {
+			days[i]["items"] = slices.Delete(items, index, index+1)
+		}
+		return days, nil
+	})
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Taiterbase/vtrips/apps/frontend/web/views"
+	"github.com/a-h/templ"
+	"github.com/labstack/echo/v4"
+)
+
+// fetchLikes loads one page of the signed-in user's liked trips and the cursor for the next.
+func fetchLikes(c echo.Context, after string) ([]views.TripCard, string, error) {
+	query := url.Values{}
+	if after != "" {
+		query.Set("after", after)
+	}
+	payload, _, err := tripsJSON(c, http.MethodGet, "/v1/users/me/likes?"+query.Encode(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	list, _ := payload["trips"].([]any)
+	cards := make([]views.TripCard, 0, len(list))
+	for _, entry := range list {
+		if trip, ok := entry.(map[string]any); ok {
+			cards = append(cards, views.NewTripCardFromPayload(trip, true))
+		}
+	}
+	next, _ := payload["next"].(string)
+	return cards, next, nil
+}
+
+// likesHandler renders the likes page, or just the next page of cards when the
+// infinite-scroll sentinel asks for one with ?after=.
+func likesHandler(c echo.Context) error {
+	after := c.QueryParam("after")
+	cards, next, err := fetchLikes(c, after)
+	if err != nil {
+		return c.JSON(http.StatusBadGateway, err.Error())
+	}
+	cmp := views.LikesPage(cards, next)
+	if after != "" {
+		cmp = views.LikesCards(cards, next)
+	}
+	templ.Handler(cmp).ServeHTTP(c.Response().Writer, c.Request())
+	return nil
+}
+
+// likeToggleHandler forwards a heart click to the trips service and swaps in the updated
+// button. Signed-out visitors get the login modal instead.
+func likeToggleHandler(c echo.Context) error {
+	if !isLoggedIn(c) {
+		// the button selects its own id from responses; point htmx at the modal instead
+		c.Response().Header().Set("HX-Reselect", "#modal-overlay")
+		return renderModal(c, views.LoginModal())
+	}
+	payload, status, err := tripsJSON(c, c.Request().Method, "/v1/users/me/likes/"+url.PathEscape(c.Param("trip_id")), nil)
+	if err != nil {
+		return c.JSON(status, err.Error())
+	}
+	templ.Handler(views.LikeButton(views.NewLikeStateFromPayload(payload))).ServeHTTP(c.Response().Writer, c.Request())
+	return nil
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Taiterbase/vtrips/apps/frontend/internal/tripsclient"
+	"github.com/Taiterbase/vtrips/apps/frontend/web/views"
+	"github.com/labstack/echo/v4"
+)
+
+func tripMediaPath(tripID, orgID string) string {
+	return fmt.Sprintf("/v1/trips/%s/media?org_id=%s", url.PathEscape(tripID), url.QueryEscape(orgID))
+}
+
+func photoPath(tripID, mediaID, orgID string) string {
+	return fmt.Sprintf("/v1/trips/%s/media/%s?org_id=%s", url.PathEscape(tripID), url.PathEscape(mediaID), url.QueryEscape(orgID))
+}
+
+// renderMediaStep renders the wizard's photo step, with errMsg shown under the upload form
+// when the last action failed.
+func renderMediaStep(c echo.Context, tripID, errMsg string) error {
+	orgID := currentOrgID(c)
+	trip, err := tripsClient(c).GetTrip(c.Request().Context(), tripID, tripsclient.GetTripParams{OrgID: orgID})
+	if err != nil {
+		return c.JSON(clientStatus(err), err.Error())
+	}
+	list, status, err := tripsJSON(c, http.MethodGet, tripMediaPath(tripID, orgID), nil)
+	if err != nil {
+		return c.JSON(status, err.Error())
+	}
+
+	summaries, err := fetchTripSummaries(c, orgID)
+	if err != nil {
+		summaries = nil
+	}
+	data := views.TripsWizardData{Step: views.TripWizardStepMedia, TripID: tripID, OrgID: orgID, Form: views.TripFormFromTrip(trip)}
+	wizard := views.TripsWizardMedia(data, views.NewPhotosFromPayload(list), errMsg)
+	summary := views.TripsSummaryList(summaries)
+	if isHXRequest(c) {
+		return renderWizardPartial(c, wizard, summary)
+	}
+	return renderTripsPage(c, "Friend", wizard, summary)
+}
+
+func tripsMediaHandler(c echo.Context) error {
+	if !isLoggedIn(c) {
+		return c.JSON(http.StatusUnauthorized, "unauthorized")
+	}
+	return renderMediaStep(c, c.Param("trip_id"), "")
+}
+
+// tripsMediaUploadHandler streams the multipart upload straight through to the trips
+// service, which does the sniffing, size checks and resizing.
+func tripsMediaUploadHandler(c echo.Context) error {
+	if !isLoggedIn(c) {
+		return c.JSON(http.StatusUnauthorized, "unauthorized")
+	}
+	tripID := c.Param("trip_id")
+	_, _, err := decodeTripsResponse(tripsRequestType(c, http.MethodPost, tripMediaPath(tripID, currentOrgID(c)), c.Request().Header.Get("Content-Type"), c.Request().Body))
+	if err != nil {
+		return renderMediaStep(c, tripID, err.Error())
+	}
+	return renderMediaStep(c, tripID, "")
+}
+
+// tripsMediaUpdateHandler saves alt text, moves a photo or makes it the cover; only the
+// fields present in the form are sent.
+func tripsMediaUpdateHandler(c echo.Context) error {
+	if !isLoggedIn(c) {
+		return c.JSON(http.StatusUnauthorized, "unauthorized")
+	}
+	tripID := c.Param("trip_id")
+	form, err := c.FormParams()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	update := map[string]any{}
+	if form.Has("alt") {
+		update["alt"] = form.Get("alt")
+	}
+	if form.Has("position") {
+		position, err := strconv.Atoi(form.Get("position"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, "position must be a number")
+		}
+		update["position"] = max(position, 0)
+	}
+	if form.Get("cover") == "true" {
+		update["cover"] = true
+	}
+	body, err := json.Marshal(update)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+	_, _, err = decodeTripsResponse(tripsRequestType(c, http.MethodPatch, photoPath(tripID, c.Param("media_id"), currentOrgID(c)), "application/json", bytes.NewReader(body)))
+	if err != nil {
+		return renderMediaStep(c, tripID, err.Error())
+	}
+	return renderMediaStep(c, tripID, "")
+}
+
+func tripsMediaDeleteHandler(c echo.Context) error {
+	if !isLoggedIn(c) {
+		return c.JSON(http.StatusUnauthorized, "unauthorized")
+	}
+	tripID := c.Param("trip_id")
+	resp, err := tripsRequest(c, http.MethodDelete, photoPath(tripID, c.Param("media_id"), currentOrgID(c)), nil)
+	if err != nil {
+		return c.JSON(http.StatusBadGateway, err.Error())
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 && resp.StatusCode != http.StatusNotFound {
+		return renderMediaStep(c, tripID, "Couldn't remove the photo, try again.")
+	}
+	return renderMediaStep(c, tripID, "")
+}
+
+// mediaFileHandler serves photo bytes from the trips service, keeping its cache headers
+// so browsers hold on to the immutable variants.
+func mediaFileHandler(c echo.Context) error {
+	path := fmt.Sprintf("/v1/trips/%s/media/%s/%s", url.PathEscape(c.Param("trip_id")), url.PathEscape(c.Param("media_id")), url.PathEscape(c.Param("variant")))
+	resp, err := tripsRequest(c, http.MethodGet, path, nil)
+	if err != nil {
+		return c.JSON(http.StatusBadGateway, err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return c.NoContent(resp.StatusCode)
+	}
+	if cc := resp.Header.Get("Cache-Control"); cc != "" {
+		c.Response().Header().Set("Cache-Control", cc)
+	}
+	return c.Stream(resp.StatusCode, resp.Header.Get("Content-Type"), resp.Body)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/frontend/web/views"
+	"github.com/a-h/templ"
+	"github.com/labstack/echo/v4"
+)
+
+// publicBaseURL is the site origin used in canonical URLs and the sitemap. Set
+// PUBLIC_BASE_URL in production so links don't depend on the request's Host header.
+func publicBaseURL(c echo.Context) string {
+	if v := os.Getenv("PUBLIC_BASE_URL"); v != "" {
+		return strings.TrimSuffix(v, "/")
+	}
+	return c.Scheme() + "://" + c.Request().Host
+}
+
+// tripPublicHandler renders a listed trip's public page. Requests with a missing or
+// outdated slug are redirected to the canonical URL.
+func tripPublicHandler(c echo.Context) error {
+	payload, status, err := tripsJSON(c, http.MethodGet, "/v1/public/trips/"+url.PathEscape(c.Param("trip_id")), nil)
+	if status == http.StatusNotFound {
+		cmp := views.StaticPage(isLoggedIn(c), "Trip not found", "This trip doesn't exist or is no longer listed.")
+		templ.Handler(cmp, templ.WithStatus(http.StatusNotFound)).ServeHTTP(c.Response().Writer, c.Request())
+		return nil
+	}
+	if err != nil {
+		return c.JSON(status, err.Error())
+	}
+
+	page := views.NewTripPageFromPayload(payload, publicBaseURL(c))
+	if canonical := views.TripPublicPath(page.ID, page.Name); c.Request().URL.Path != canonical {
+		return c.Redirect(http.StatusMovedPermanently, canonical)
+	}
+	page.Liked = likedTripIDs(c)[page.ID]
+	templ.Handler(views.TripPublicPage(isLoggedIn(c), page)).ServeHTTP(c.Response().Writer, c.Request())
+	return nil
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemapHandler lists the public pages and every listed trip for search engines.
+func sitemapHandler(c echo.Context) error {
+	base := publicBaseURL(c)
+	set := sitemapURLSet{
+		XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs:  []sitemapURL{{Loc: base + "/"}, {Loc: base + "/browse"}},
+	}
+	query := url.Values{"limit": {"100"}}
+	for {
+		payload, status, err := tripsJSON(c, http.MethodGet, "/v1/public/trips?"+query.Encode(), nil)
+		if err != nil {
+			return c.JSON(status, err.Error())
+		}
+		list, _ := payload["trips"].([]any)
+		for _, entry := range list {
+			trip, ok := entry.(map[string]any)
+			if !ok {
+				continue
+			}
+			id, _ := trip["id"].(string)
+			name, _ := trip["name"].(string)
+			u := sitemapURL{Loc: base + views.TripPublicPath(id, name)}
+			if updated, ok := trip["updated_at"].(float64); ok && updated > 0 {
+				u.LastMod = time.Unix(int64(updated), 0).UTC().Format("2006-01-02")
+			}
+			set.URLs = append(set.URLs, u)
+		}
+		next, _ := payload["next"].(string)
+		if next == "" {
+			break
+		}
+		query.Set("after", next)
+	}
+
+	body, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, err.Error())
+	}
+	return c.Blob(http.StatusOK, echo.MIMEApplicationXMLCharsetUTF8, append([]byte(xml.Header), body...))
+}
+
+func robotsHandler(c echo.Context) error {
+	return c.String(http.StatusOK, "User-agent: *\nAllow: /\nSitemap: "+publicBaseURL(c)+"/sitemap.xml\n")
+}
@@ -1,16 +1,18 @@
 package api
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
-	"strings"
+	"strconv"
 	"time"
 
+	"github.com/Taiterbase/vtrips/apps/frontend/internal/tripsclient"
+	"github.com/Taiterbase/vtrips/apps/frontend/internal/usersclient"
 	"github.com/Taiterbase/vtrips/apps/frontend/web/views"
 	"github.com/a-h/templ"
 	"github.com/labstack/echo/v4"
@@ -25,16 +27,22 @@ func registerRoutes(e *echo.Echo) {
 		h.ServeHTTP(c.Response().Writer, c.Request())
 		return nil
 	})
-	e.GET("/browse", func(c echo.Context) error {
-		h := templ.Handler(views.BrowsePage(isLoggedIn(c)))
-		h.ServeHTTP(c.Response().Writer, c.Request())
-		return nil
-	})
-	e.GET("/likes", func(c echo.Context) error {
-		h := templ.Handler(views.LikesPage(isLoggedIn(c)))
-		h.ServeHTTP(c.Response().Writer, c.Request())
-		return nil
-	})
+	e.GET("/browse", browseHandler)
+	e.GET("/browse/map", browseMapHandler)
+	e.GET("/t/:trip_id", tripPublicHandler)
+	e.GET("/t/:trip_id/:slug", tripPublicHandler)
+	e.GET("/t/:trip_id/calendar.ics", calendarHandler(tripCalendarPath))
+	e.GET("/orgs/:org_id/calendar.ics", calendarHandler(orgCalendarPath))
+	e.GET("/calendars/:token", calendarHandler(userCalendarPath))
+	e.GET("/sitemap.xml", sitemapHandler)
+	e.GET("/robots.txt", robotsHandler)
+	e.GET("/likes", authWrapper(likesHandler, views.UnauthPage(), views.UnauthPartial()))
+	e.PUT("/likes/:trip_id", likeToggleHandler)
+	e.DELETE("/likes/:trip_id", likeToggleHandler)
+
+	// the API apps use; see internal/graph
+	e.GET("/graphql", graphqlHandler)
+	e.POST("/graphql", graphqlHandler)
 
 	e.GET("/modal/login", componentHandler(views.LoginModal()))
 	e.GET("/modal/sign-up", componentHandler(views.SignupModal()))
@@ -52,6 +60,9 @@ func registerRoutes(e *echo.Echo) {
 	e.POST("/auth/login", proxyUsersLogin)
 	e.PUT("/auth/login", proxyUsersLogin) // support hx-put from modal
 	e.POST("/auth/logout", proxyUsersLogout)
+	e.POST("/auth/login/totp", proxyUsersLoginTOTP)
+	e.GET("/auth/second-step", authSecondStepPage)
+	e.POST("/auth/totp/activate", proxyUsersTOTPActivate)
 	// Additional pages (full pages render their body inside #partial)
 	e.GET("/about", func(c echo.Context) error {
 		h := templ.Handler(views.StaticPage(isLoggedIn(c), "About", "About page (placeholder)"))
@@ -80,6 +91,22 @@ func registerRoutes(e *echo.Echo) {
 	e.POST("/trips", tripsCreateHandler)
 	e.PUT("/trips/:trip_id", tripsUpdateHandler)
 	e.GET("/trips/:trip_id", tripsShowHandler)
+	e.GET("/trips/:trip_id/itinerary", tripsItineraryHandler)
+	e.POST("/trips/:trip_id/itinerary/days", itineraryDaySaveHandler)
+	e.DELETE("/trips/:trip_id/itinerary/days/:date", itineraryDayDeleteHandler)
+	e.POST("/trips/:trip_id/itinerary/days/:date/items", itineraryItemAddHandler)
+	e.DELETE("/trips/:trip_id/itinerary/days/:date/items/:index", itineraryItemDeleteHandler)
+	e.GET("/trips/:trip_id/media", tripsMediaHandler)
+	e.POST("/trips/:trip_id/media", tripsMediaUploadHandler)
+	e.PATCH("/trips/:trip_id/media/:media_id", tripsMediaUpdateHandler)
+	e.DELETE("/trips/:trip_id/media/:media_id", tripsMediaDeleteHandler)
+	e.GET("/media/:trip_id/:media_id/:variant", mediaFileHandler)
+
+	// Org pages require authentication; the trips service checks org membership
+	e.GET("/org", authWrapper(orgDashboardHandler, views.UnauthPage(), views.UnauthPartial()))
+	e.GET("/org/applications", authWrapper(applicationsIndexHandler, views.UnauthPage(), views.UnauthPartial()))
+	e.GET("/org/applications/:application_id", authWrapper(applicationShowHandler, views.UnauthPage(), views.UnauthPartial()))
+	e.POST("/org/applications/:application_id/grade", authWrapper(applicationGradeHandler, views.UnauthPage(), views.UnauthPartial()))
 }
 
 func tripsIndexHandler(c echo.Context) error {
@@ -87,11 +114,11 @@ func tripsIndexHandler(c echo.Context) error {
 		return c.Redirect(http.StatusFound, "/")
 	}
 	orgID := currentOrgID(c)
-	summaries, err := fetchTripSummaries(orgID)
+	summaries, err := fetchTripSummaries(c, orgID)
 	if err != nil {
-		return c.JSON(http.StatusBadGateway, err.Error())
+		return c.JSON(clientStatus(err), err.Error())
 	}
-	data := views.TripsWizardData{Step: views.TripWizardStepBasics, OrgID: orgID, Form: map[string]string{}}
+	data := views.TripsWizardData{Step: views.TripWizardStepBasics, OrgID: orgID, Form: map[string]string{}, Enums: tripEnums(c)}
 	return renderTripsPage(c, "Friend", views.TripsWizardBasics(data), views.TripsSummaryList(summaries))
 }
 
@@ -100,8 +127,8 @@ func tripsNewHandler(c echo.Context) error {
 		return c.Redirect(http.StatusFound, "/")
 	}
 	orgID := currentOrgID(c)
-	data := views.TripsWizardData{Step: views.TripWizardStepBasics, OrgID: orgID, Form: map[string]string{}}
-	summaries, err := fetchTripSummaries(orgID)
+	data := views.TripsWizardData{Step: views.TripWizardStepBasics, OrgID: orgID, Form: map[string]string{}, Enums: tripEnums(c)}
+	summaries, err := fetchTripSummaries(c, orgID)
 	if err != nil {
 		summaries = nil
 	}
@@ -115,97 +142,82 @@ func tripsNewHandler(c echo.Context) error {
 
 type tripsPayload map[string]string
 
-func fetchTripSummaries(orgID string) ([]views.TripSummary, error) {
-	req, err := http.NewRequest(http.MethodGet, tripsBaseURL()+"/v1/trips?org_id="+url.QueryEscape(orgID), nil)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+// fetchTripSummaries lists the org's trips for the dashboard.
+func fetchTripSummaries(c echo.Context, orgID string) ([]views.TripSummary, error) {
+	list, err := tripsClient(c).ListTrips(c.Request().Context(), tripsclient.ListTripsParams{OrgID: orgID})
 	if err != nil {
 		return nil, err
 	}
-
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("trips service error: %s", strings.TrimSpace(string(body)))
+	summaries := make([]views.TripSummary, 0, len(list.Trips))
+	for i := range list.Trips {
+		summaries = append(summaries, views.NewTripSummary(&list.Trips[i]))
 	}
-
-	return parseTripSummaries(body)
+	return summaries, nil
 }
 
-func parseTripSummaries(body []byte) ([]views.TripSummary, error) {
-	trimmed := bytes.TrimSpace(body)
-	if len(trimmed) == 0 {
-		return nil, nil
+// tripFromForm reads a wizard step's fields into a trip. Blank fields stay out of the
+// request, so a step only changes the fields it shows.
+func tripFromForm(form tripsPayload) (tripsclient.Trip, error) {
+	trip := tripsclient.Trip{
+		OrgID:       form["org_id"],
+		Name:        form["name"],
+		City:        form["city"],
+		Country:     form["country"],
+		Currency:    form["currency"],
+		Mission:     form["mission"],
+		Description: form["description"],
+		TripType:    tripsclient.TripType(form["trip_type"]),
+		HousingType: tripsclient.HousingType(form["housing_type"]),
+		PrivacyType: tripsclient.PrivacyType(form["privacy_type"]),
+		Status:      tripsclient.TripStatus(form["status"]),
 	}
-
-	if trimmed[0] == '[' {
-		var list []map[string]any
-		if err := json.Unmarshal(trimmed, &list); err == nil {
-			return buildTripSummaries(list), nil
+	var err error
+	if v := form["volunteer_limit"]; v != "" {
+		if trip.VolunteerLimit, err = strconv.Atoi(v); err != nil {
+			return trip, fmt.Errorf("Volunteer limit must be a whole number")
 		}
-		var generic []any
-		if err := json.Unmarshal(trimmed, &generic); err != nil {
-			return nil, err
+	}
+	if v := form["price"]; v != "" {
+		// every currency the wizard offers counts in hundredths
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil || price < 0 {
+			return trip, fmt.Errorf("Fee must be an amount such as 450 or 449.99")
 		}
-		return buildTripSummariesFromAny(generic), nil
+		trip.Price = int64(math.Round(price * 100))
 	}
-
-	var payload map[string]any
-	if err := json.Unmarshal(trimmed, &payload); err != nil {
-		return nil, err
+	if trip.Latitude, err = formFloat(form, "latitude"); err != nil {
+		return trip, fmt.Errorf("Latitude must be a number")
 	}
-
-	if tripsVal, ok := payload["trips"]; ok {
-		return buildTripSummariesFromInterface(tripsVal)
+	if trip.Longitude, err = formFloat(form, "longitude"); err != nil {
+		return trip, fmt.Errorf("Longitude must be a number")
 	}
-
-	if dataVal, ok := payload["data"]; ok {
-		switch d := dataVal.(type) {
-		case []any:
-			return buildTripSummariesFromAny(d), nil
-		case map[string]any:
-			if inner, ok := d["trips"]; ok {
-				return buildTripSummariesFromInterface(inner)
-			}
-		}
+	if trip.StartDate, err = formDate(form, "start_date"); err != nil {
+		return trip, err
 	}
-
-	return nil, nil
-}
-
-func buildTripSummaries(list []map[string]any) []views.TripSummary {
-	summaries := make([]views.TripSummary, 0, len(list))
-	for _, entry := range list {
-		summaries = append(summaries, views.NewTripSummaryFromPayload(entry))
+	if trip.EndDate, err = formDate(form, "end_date"); err != nil {
+		return trip, err
 	}
-	return summaries
+	return trip, nil
 }
 
-func buildTripSummariesFromAny(raw []any) []views.TripSummary {
-	summaries := make([]views.TripSummary, 0, len(raw))
-	for _, entry := range raw {
-		if tripMap, ok := entry.(map[string]any); ok {
-			summaries = append(summaries, views.NewTripSummaryFromPayload(tripMap))
-		}
+// formFloat reads a number field, which is 0 when blank.
+func formFloat(form tripsPayload, key string) (float64, error) {
+	if form[key] == "" {
+		return 0, nil
 	}
-	return summaries
+	return strconv.ParseFloat(form[key], 64)
 }
 
-func buildTripSummariesFromInterface(value any) ([]views.TripSummary, error) {
-	switch v := value.(type) {
-	case []any:
-		return buildTripSummariesFromAny(v), nil
-	case []map[string]any:
-		return buildTripSummaries(v), nil
-	default:
-		return nil, nil
+// formDate reads a date field as Unix seconds at midnight UTC, which is 0 when blank.
+func formDate(form tripsPayload, key string) (int64, error) {
+	if form[key] == "" {
+		return 0, nil
+	}
+	day, err := time.Parse("2006-01-02", form[key])
+	if err != nil {
+		return 0, fmt.Errorf("Dates must look like 2026-05-01")
 	}
+	return day.Unix(), nil
 }
 
 func renderTripsPage(c echo.Context, userName string, wizard templ.Component, summary templ.Component) error {
@@ -233,32 +245,24 @@ func tripsCreateHandler(c echo.Context) error {
 	}
 	payload["org_id"] = orgID
 	payload["status"] = defaultTripStatus(payload["status"])
-	payload["step"] = string(views.TripWizardStepBasics)
-
-	resp, err := postJSON(tripsBaseURL()+"/v1/trips", payload)
+	body, err := tripFromForm(payload)
 	if err != nil {
-		return c.JSON(http.StatusBadGateway, err.Error())
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		return c.JSON(resp.StatusCode, string(body))
+		return c.JSON(http.StatusBadRequest, err.Error())
 	}
 
-	var trip map[string]any
-	if err := json.NewDecoder(resp.Body).Decode(&trip); err != nil {
-		return c.JSON(http.StatusBadGateway, err.Error())
+	trip, err := tripsClient(c).CreateTrip(c.Request().Context(), body)
+	if err != nil {
+		return c.JSON(clientStatus(err), err.Error())
 	}
-
-	tripID, _ := trip["id"].(string)
 	data := views.TripsWizardData{
 		Step:   views.TripWizardStepLogistics,
-		TripID: tripID,
+		TripID: trip.ID,
 		OrgID:  orgID,
-		Form:   views.TripFormFromPayload(trip),
+		Form:   views.TripFormFromTrip(trip),
+		Enums:  tripEnums(c),
 	}
 
-	summaries, err := fetchTripSummaries(orgID)
+	summaries, err := fetchTripSummaries(c, orgID)
 	if err != nil {
 		summaries = nil
 	}
@@ -280,30 +284,27 @@ func tripsUpdateHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, err.Error())
 	}
 	payload["org_id"] = currentOrgID(c)
+	trip, err := tripFromForm(payload)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
 
-	resp, err := putJSON(tripsBaseURL()+"/v1/trips/"+tripID+"?org_id="+url.QueryEscape(payload["org_id"]), payload)
+	err = tripsClient(c).UpdateTrip(c.Request().Context(), tripID, tripsclient.UpdateTripParams{OrgID: trip.OrgID}, trip)
 	if err != nil {
-		return c.JSON(http.StatusBadGateway, err.Error())
+		return c.JSON(clientStatus(err), err.Error())
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		return c.JSON(resp.StatusCode, string(body))
+	if payload["next_step"] == string(views.TripWizardStepItinerary) {
+		return renderItineraryStep(c, tripID, "")
 	}
 
-	summaries, err := fetchTripSummaries(payload["org_id"])
+	summaries, err := fetchTripSummaries(c, trip.OrgID)
 	if err != nil {
 		summaries = nil
 	}
-	summary := views.NewTripSummaryFromPayload(map[string]any{
-		"id":      tripID,
-		"name":    payload["name"],
-		"city":    payload["city"],
-		"country": payload["country"],
-		"status":  payload["status"],
-	})
+	trip.ID = tripID
+	summary := views.NewTripSummary(&trip)
 
-	data := views.TripsWizardData{Step: views.TripWizardStepReview, TripID: tripID, OrgID: payload["org_id"], Form: payload}
+	data := views.TripsWizardData{Step: views.TripWizardStepReview, TripID: tripID, OrgID: trip.OrgID, Form: payload}
 	wizard := views.TripsWizardReview(data, summary)
 	summaryList := views.TripsSummaryList(summaries)
 	if isHXRequest(c) {
@@ -318,27 +319,13 @@ func tripsShowHandler(c echo.Context) error {
 	}
 	tripID := c.Param("trip_id")
 	orgID := currentOrgID(c)
-	req, err := http.NewRequest(http.MethodGet, tripsBaseURL()+"/v1/trips/"+tripID+"?org_id="+url.QueryEscape(orgID), nil)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
-	}
-	resp, err := http.DefaultClient.Do(req)
+	trip, err := tripsClient(c).GetTrip(c.Request().Context(), tripID, tripsclient.GetTripParams{OrgID: orgID})
 	if err != nil {
-		return c.JSON(http.StatusBadGateway, err.Error())
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		return c.JSON(resp.StatusCode, string(body))
-	}
-
-	var trip map[string]any
-	if err := json.NewDecoder(resp.Body).Decode(&trip); err != nil {
-		return c.JSON(http.StatusBadGateway, err.Error())
+		return c.JSON(clientStatus(err), err.Error())
 	}
 
-	summary := views.NewTripSummaryFromPayload(trip)
-	data := views.TripsWizardData{Step: views.TripWizardStepReview, TripID: tripID, OrgID: orgID, Form: views.TripFormFromPayload(trip)}
+	summary := views.NewTripSummary(trip)
+	data := views.TripsWizardData{Step: views.TripWizardStepReview, TripID: tripID, OrgID: orgID, Form: views.TripFormFromTrip(trip)}
 	wizard := views.TripsWizardReview(data, summary)
 	summaryList := views.TripsSummaryList([]views.TripSummary{summary})
 	if isHXRequest(c) {
@@ -373,34 +360,6 @@ func defaultTripStatus(requested string) string {
 	}
 }
 
-func postJSON(url string, payload any) (*http.Response, error) {
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return nil, err
-	}
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{Timeout: 10 * time.Second}
-	return client.Do(req)
-}
-
-func putJSON(url string, payload any) (*http.Response, error) {
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return nil, err
-	}
-	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{Timeout: 10 * time.Second}
-	return client.Do(req)
-}
-
 // proxy target for users service inside cluster
 func usersBaseURL() string {
 	if v := os.Getenv("USERS_BASE_URL"); v != "" {
@@ -467,6 +426,14 @@ func proxyUsersLogin(c echo.Context) error {
 		return c.JSON(http.StatusBadGateway, err.Error())
 	}
 	defer resp.Body.Close()
+	// 202 means the password was right but a second factor is still owed
+	if resp.StatusCode == http.StatusAccepted {
+		var step usersclient.LoginStep
+		if err := json.NewDecoder(resp.Body).Decode(&step); err != nil {
+			return c.JSON(http.StatusBadGateway, err.Error())
+		}
+		return renderLoginSecondStep(c, step)
+	}
 	if ct := resp.Header.Get("Content-Type"); ct != "" {
 		c.Response().Header().Set("Content-Type", ct)
 	}
@@ -481,6 +448,90 @@ func proxyUsersLogin(c echo.Context) error {
 	return nil
 }
 
+// renderModal swaps cmp into the modal portal regardless of the triggering element's hx-target.
+func renderModal(c echo.Context, cmp templ.Component) error {
+	c.Response().Header().Set("HX-Retarget", "#modal-portal")
+	c.Response().Header().Set("HX-Reswap", "innerHTML")
+	templ.Handler(cmp).ServeHTTP(c.Response().Writer, c.Request())
+	return nil
+}
+
+func renderLoginSecondStep(c echo.Context, step usersclient.LoginStep) error {
+	cmp, status, err := secondStepModal(c, step)
+	if err != nil {
+		return c.JSON(status, err.Error())
+	}
+	return renderModal(c, cmp)
+}
+
+// secondStepModal picks the TOTP prompt or, when the org requires 2FA the user hasn't set
+// up yet, starts enrollment and returns the enrollment modal.
+func secondStepModal(c echo.Context, step usersclient.LoginStep) (templ.Component, int, error) {
+	if step.TOTPRequired {
+		return views.TOTPModal(step.MFAToken), http.StatusOK, nil
+	}
+	if !step.TOTPEnrollmentRequired {
+		return nil, http.StatusBadGateway, fmt.Errorf("unexpected login response")
+	}
+
+	enrollment, err := usersClient(c).EnrollTOTP(c.Request().Context(), usersclient.EnrollTOTPForm{MFAToken: step.MFAToken})
+	if err != nil {
+		return nil, clientStatus(err), err
+	}
+	return views.TOTPEnrollModal(step.MFAToken, enrollment.Secret, enrollment.ProvisioningURI), http.StatusOK, nil
+}
+
+// authSecondStepPage is where the users service sends browsers after a social sign-in
+// that still needs a second factor.
+func authSecondStepPage(c echo.Context) error {
+	step := usersclient.LoginStep{
+		TOTPRequired:           c.QueryParam("kind") == "totp",
+		TOTPEnrollmentRequired: c.QueryParam("kind") == "enroll",
+		MFAToken:               c.QueryParam("mfa_token"),
+	}
+	cmp, status, err := secondStepModal(c, step)
+	if err != nil {
+		return c.JSON(status, err.Error())
+	}
+	templ.Handler(views.AuthStepPage(cmp)).ServeHTTP(c.Response().Writer, c.Request())
+	return nil
+}
+
+func proxyUsersLoginTOTP(c echo.Context) error {
+	values := url.Values{}
+	values.Set("mfa_token", c.FormValue("mfa_token"))
+	values.Set("code", c.FormValue("code"))
+	values.Set("recovery_code", c.FormValue("recovery_code"))
+	resp, err := postFormUsers("/v1/users/auth/login/totp", values)
+	if err != nil {
+		return c.JSON(http.StatusBadGateway, err.Error())
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		c.Response().Header().Set("Content-Type", ct)
+	}
+	for _, v := range resp.Header.Values("Set-Cookie") {
+		c.Response().Header().Add("Set-Cookie", v)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		c.Response().Header().Set("HX-Redirect", "/")
+	}
+	c.Response().WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(c.Response().Writer, resp.Body)
+	return nil
+}
+
+func proxyUsersTOTPActivate(c echo.Context) error {
+	activated, err := usersClient(c).ActivateTOTP(c.Request().Context(), usersclient.ActivateTOTPForm{
+		MFAToken: c.FormValue("mfa_token"),
+		Code:     c.FormValue("code"),
+	})
+	if err != nil {
+		return c.JSON(clientStatus(err), err.Error())
+	}
+	return renderModal(c, views.RecoveryCodesModal(activated.RecoveryCodes))
+}
+
 func proxyUsersLogout(c echo.Context) error {
 	req, _ := http.NewRequest(http.MethodPost, usersBaseURL()+"/v1/users/auth/logout", nil)
 	resp, err := http.DefaultClient.Do(req)
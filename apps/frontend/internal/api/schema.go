@@ -0,0 +1,37 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/frontend/web/views"
+	"github.com/labstack/echo/v4"
+)
+
+// tripEnumsTTL is how long the trips service's enum options are reused before they're
+// fetched again; they only change when the service is redeployed.
+const tripEnumsTTL = 10 * time.Minute
+
+var tripEnumsCache struct {
+	sync.Mutex
+	enums   views.TripEnums
+	fetched time.Time
+}
+
+// tripEnums returns the trip enum options for the selects, from GET /v1/trips/schema.
+// When the trips service can't be reached it keeps using the last options it fetched.
+func tripEnums(c echo.Context) views.TripEnums {
+	tripEnumsCache.Lock()
+	defer tripEnumsCache.Unlock()
+	if tripEnumsCache.enums != nil && time.Since(tripEnumsCache.fetched) < tripEnumsTTL {
+		return tripEnumsCache.enums
+	}
+	schema, err := tripsClient(c).GetTripSchema(c.Request().Context())
+	if err != nil {
+		c.Logger().Warnf("fetching trip schema: %v", err)
+		return tripEnumsCache.enums
+	}
+	tripEnumsCache.enums = views.TripEnumsFromSchema(schema)
+	tripEnumsCache.fetched = time.Now()
+	return tripEnumsCache.enums
+}
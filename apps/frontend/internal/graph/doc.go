@@ -0,0 +1,6 @@
+// Package graph is the frontend's GraphQL API, which apps use in place of the pages:
+// schema.graphqls is the schema, and resolvers answer it by calling the trips and users
+// services through their generated clients.
+package graph
+
+//go:generate go tool gqlgen generate --config gqlgen.yml
@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// batchWait is how long a loader collects keys before fetching them; resolvers for
+	// the items of one list all ask within it.
+	batchWait = 2 * time.Millisecond
+	// maxBatch is the most keys a loader fetches at once.
+	maxBatch = 100
+)
+
+// loader batches the keys resolvers ask for within batchWait of each other into one
+// fetch, and remembers what it found for the rest of the request, so resolving a field
+// on every item of a list costs one fetch rather than one per item.
+type loader[K comparable, V any] struct {
+	ctx context.Context
+	// fetch finds the values for keys; a key missing from the map has no value
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	results map[K]*loaded[V]
+	batch   []K
+}
+
+// loaded is what a loader found for one key, once done is closed.
+type loaded[V any] struct {
+	done  chan struct{}
+	value V
+	found bool
+	err   error
+}
+
+func newLoader[K comparable, V any](ctx context.Context, fetch func(context.Context, []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{ctx: ctx, fetch: fetch, results: map[K]*loaded[V]{}}
+}
+
+// load returns the value for key, and false when there isn't one.
+func (l *loader[K, V]) load(ctx context.Context, key K) (V, bool, error) {
+	l.mu.Lock()
+	r, ok := l.results[key]
+	if !ok {
+		r = &loaded[V]{done: make(chan struct{})}
+		l.results[key] = r
+		l.batch = append(l.batch, key)
+		switch len(l.batch) {
+		case 1:
+			time.AfterFunc(batchWait, l.dispatch)
+		case maxBatch:
+			go l.dispatch()
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.value, r.found, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, false, ctx.Err()
+	}
+}
+
+// prime remembers value for key, such as a trip that came in a list, so loading it
+// later doesn't fetch it again.
+func (l *loader[K, V]) prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.results[key]; ok {
+		return
+	}
+	r := &loaded[V]{done: make(chan struct{}), value: value, found: true}
+	close(r.done)
+	l.results[key] = r
+}
+
+// dispatch fetches the keys collected so far.
+func (l *loader[K, V]) dispatch() {
+	l.mu.Lock()
+	keys := l.batch
+	l.batch = nil
+	l.mu.Unlock()
+	if len(keys) == 0 {
+		// a full batch already went
+		return
+	}
+
+	values, err := l.fetch(l.ctx, keys)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		r := l.results[key]
+		r.value, r.found = values[key]
+		r.err = err
+		close(r.done)
+	}
+}
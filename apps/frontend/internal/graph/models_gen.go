@@ -0,0 +1,41 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package graph
+
+import (
+	"github.com/Taiterbase/vtrips/apps/frontend/internal/tripsclient"
+)
+
+type PageInfo struct {
+	HasNextPage bool `json:"hasNextPage"`
+	// Pass as after to fetch the following page; null on the last.
+	EndCursor *string `json:"endCursor,omitempty"`
+}
+
+type Query struct {
+}
+
+// One page of trips.
+type TripConnection struct {
+	Nodes    []*tripsclient.Trip `json:"nodes"`
+	PageInfo *PageInfo           `json:"pageInfo"`
+}
+
+// Narrows trips; every field set must match.
+type TripFilter struct {
+	TripType    *tripsclient.TripType    `json:"tripType,omitempty"`
+	HousingType *tripsclient.HousingType `json:"housingType,omitempty"`
+	PrivacyType *tripsclient.PrivacyType `json:"privacyType,omitempty"`
+	Country     *string                  `json:"country,omitempty"`
+	City        *string                  `json:"city,omitempty"`
+	// Only trips running on or after this date, such as 2026-05-01.
+	From *string `json:"from,omitempty"`
+	// Only trips running on or before this date.
+	To *string `json:"to,omitempty"`
+	// Lowest price, in major units of currency, such as 450 or 449.99.
+	PriceMin *string `json:"priceMin,omitempty"`
+	// Highest price, in major units of currency.
+	PriceMax *string `json:"priceMax,omitempty"`
+	// ISO 4217 currency of the price range, USD by default.
+	Currency *string `json:"currency,omitempty"`
+}
@@ -0,0 +1,278 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/Taiterbase/vtrips/apps/frontend/internal/tripsclient"
+	"github.com/Taiterbase/vtrips/apps/frontend/internal/usersclient"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	// maxComplexity is the most a query may cost: a field costs 1, and a list costs its
+	// items' fields once for every item it may hold.
+	maxComplexity = 5000
+	// applicationsComplexity is how many items applications is charged for, as it isn't
+	// paged.
+	applicationsComplexity = 50
+	// defaultTripsPage and defaultLikesPage are the trips service's page sizes when first
+	// is null, and maxPage the largest it pages by.
+	defaultTripsPage = 24
+	defaultLikesPage = 20
+	maxPage          = 100
+	// fetchParallelism is how many calls a loader makes at once when a service can only
+	// be asked for one thing per call.
+	fetchParallelism = 8
+)
+
+// Resolver answers the schema's fields; what it needs for a request comes from the
+// request's context, see WithServices.
+type Resolver struct{}
+
+// NewHandler returns the GraphQL endpoint. It answers GET and POST queries, including
+// introspection, and refuses queries costing more than maxComplexity.
+func NewHandler() http.Handler {
+	cfg := Config{Resolvers: &Resolver{}}
+	cfg.Complexity.Query.Trips = func(child int, _ *TripFilter, first *int, _ *string) int {
+		return child * pageSize(first, defaultTripsPage)
+	}
+	cfg.Complexity.Query.Likes = func(child int, first *int, _ *string) int {
+		return child * pageSize(first, defaultLikesPage)
+	}
+	cfg.Complexity.Query.Applications = func(child int, _ *tripsclient.ApplicationStatus, _ *bool) int {
+		return child * applicationsComplexity
+	}
+
+	srv := handler.New(NewExecutableSchema(cfg))
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.SetQueryCache(lru.New[*ast.QueryDocument](100))
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.FixedComplexityLimit(maxComplexity))
+	srv.SetErrorPresenter(presentError)
+	return srv
+}
+
+// pageSize is the page a first argument asks for: fallback when it's missing, and at
+// most maxPage.
+func pageSize(first *int, fallback int) int {
+	if first == nil {
+		return fallback
+	}
+	return min(max(*first, 0), maxPage)
+}
+
+// presentError adds the status and problem code of a failed service call to the error's
+// extensions, so apps can tell errors apart the way the services' clients do.
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	status, code := tripsclient.StatusCode(err), tripsclient.Code(err)
+	if status == 0 {
+		status, code = usersclient.StatusCode(err), usersclient.Code(err)
+	}
+	if status != 0 {
+		gqlErr.Extensions = map[string]any{"status": status, "code": code}
+	}
+	return gqlErr
+}
+
+// Services are the clients a request's resolvers call, acting for its user.
+type Services struct {
+	Trips *tripsclient.Client
+	Users *usersclient.Client
+	// SignedIn is whether the request carries a session; without one, resolvers don't
+	// ask the services for the user's own things
+	SignedIn bool
+}
+
+// tripKey is a trip to load: a listed one when orgID is empty, and otherwise one of
+// that org's.
+type tripKey struct {
+	orgID, tripID string
+}
+
+// request is what the resolvers answering one request share.
+type request struct {
+	Services
+	trips *loader[tripKey, tripsclient.Trip]
+	users *loader[string, usersclient.PublicProfile]
+
+	likedOnce sync.Once
+	liked     map[string]bool
+}
+
+type requestKey struct{}
+
+// WithServices returns ctx for answering a request with services.
+func WithServices(ctx context.Context, services Services) context.Context {
+	req := &request{Services: services}
+	req.trips = newLoader(ctx, func(ctx context.Context, keys []tripKey) (map[tripKey]tripsclient.Trip, error) {
+		return fetchTrips(ctx, services.Trips, keys)
+	})
+	req.users = newLoader(ctx, func(ctx context.Context, keys []string) (map[string]usersclient.PublicProfile, error) {
+		return fetchEach(ctx, keys, services.Users.GetUser)
+	})
+	return context.WithValue(ctx, requestKey{}, req)
+}
+
+func forRequest(ctx context.Context) *request {
+	return ctx.Value(requestKey{}).(*request)
+}
+
+// fetchTrips gets the trips for keys with one batchGet per org, and one for the listed
+// trips. Only an org's members get its trips in any status; anyone else, such as a
+// volunteer loading the trip of an application, gets them only while they are listed.
+// Trips the trips service doesn't find are left out.
+func fetchTrips(ctx context.Context, trips *tripsclient.Client, keys []tripKey) (map[tripKey]tripsclient.Trip, error) {
+	byOrg := map[string][]string{}
+	for _, key := range keys {
+		byOrg[key.orgID] = append(byOrg[key.orgID], key.tripID)
+	}
+	values := make(map[tripKey]tripsclient.Trip, len(keys))
+	for orgID, ids := range byOrg {
+		batch, err := trips.BatchGetTrips(ctx, tripsclient.BatchGetTripsParams{OrgID: orgID}, tripsclient.TripBatchGetRequest{IDs: ids})
+		if code := tripsclient.StatusCode(err); orgID != "" && (code == http.StatusUnauthorized || code == http.StatusForbidden) {
+			batch, err = trips.BatchGetTrips(ctx, tripsclient.BatchGetTripsParams{}, tripsclient.TripBatchGetRequest{IDs: ids})
+		}
+		if err != nil {
+			return nil, err
+		}
+		// org members get trips whole and anyone else their public view, which leaves the
+		// org's fields empty
+		for _, raw := range batch.Trips {
+			var trip tripsclient.Trip
+			if err = json.Unmarshal(raw, &trip); err != nil {
+				return nil, err
+			}
+			values[tripKey{orgID: orgID, tripID: trip.ID}] = trip
+		}
+	}
+	return values, nil
+}
+
+// fetchEach gets every key with its own call, fetchParallelism at a time, for services
+// that can't be asked for several at once. Keys the service doesn't know are left out.
+func fetchEach[K comparable, V any](ctx context.Context, keys []K, get func(context.Context, K) (*V, error)) (map[K]V, error) {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		values = make(map[K]V, len(keys))
+		errs   []error
+		slots  = make(chan struct{}, fetchParallelism)
+	)
+	for _, key := range keys {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer func() { <-slots; wg.Done() }()
+			value, err := get(ctx, key)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				values[key] = *value
+			case !notFound(err):
+				errs = append(errs, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return values, errors.Join(errs...)
+}
+
+// notFound reports whether a service call failed because there's no such thing.
+func notFound(err error) bool {
+	return tripsclient.StatusCode(err) == http.StatusNotFound || usersclient.StatusCode(err) == http.StatusNotFound
+}
+
+// likedTrips returns the trips the signed-in user has liked, asking the trips service
+// once per request however many trips are resolved. Like the browse page, a stale or
+// rejected session only costs the likes.
+func (req *request) likedTrips(ctx context.Context) map[string]bool {
+	req.likedOnce.Do(func() {
+		req.liked = map[string]bool{}
+		if !req.SignedIn {
+			return
+		}
+		ids, err := req.Trips.ListLikedTripIDs(ctx)
+		if err != nil {
+			return
+		}
+		for _, id := range ids.TripIDs {
+			req.liked[id] = true
+		}
+	})
+	return req.liked
+}
+
+// loadTrip loads the trip for key, which is nil when there's no such trip.
+func loadTrip(ctx context.Context, key tripKey) (*tripsclient.Trip, error) {
+	trip, found, err := forRequest(ctx).trips.load(ctx, key)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &trip, nil
+}
+
+// tripConnection is a page of trips. The trips are remembered for the request, so asking
+// for one of them again doesn't call the trips service.
+func tripConnection(req *request, list *tripsclient.TripList) *TripConnection {
+	conn := &TripConnection{
+		Nodes:    make([]*tripsclient.Trip, 0, len(list.Trips)),
+		PageInfo: &PageInfo{HasNextPage: list.Next != ""},
+	}
+	if list.Next != "" {
+		conn.PageInfo.EndCursor = &list.Next
+	}
+	for i := range list.Trips {
+		trip := &list.Trips[i]
+		req.trips.prime(tripKey{orgID: trip.OrgID, tripID: trip.ID}, *trip)
+		if trip.Status == tripsclient.TripStatusListed {
+			req.trips.prime(tripKey{tripID: trip.ID}, *trip)
+		}
+		conn.Nodes = append(conn.Nodes, trip)
+	}
+	return conn
+}
+
+// publicTrips reads a page of listed trips as Trips: the trips service leaves the org's
+// fields out of them, and otherwise they are the same.
+func publicTrips(list *tripsclient.PublicTripList) (*tripsclient.TripList, error) {
+	b, err := json.Marshal(list)
+	if err != nil {
+		return nil, err
+	}
+	var trips tripsclient.TripList
+	if err = json.Unmarshal(b, &trips); err != nil {
+		return nil, err
+	}
+	return &trips, nil
+}
+
+// checkFirst refuses a page size the trips service would refuse.
+func checkFirst(first *int) error {
+	if first != nil && (*first < 1 || *first > maxPage) {
+		return fmt.Errorf("first must be between 1 and %d", maxPage)
+	}
+	return nil
+}
+
+// deref is the value p points to, or the zero value when it's nil.
+func deref[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+	return *p
+}
@@ -0,0 +1,155 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Taiterbase/vtrips/apps/frontend/internal/tripsclient"
+	"github.com/Taiterbase/vtrips/apps/frontend/internal/usersclient"
+	"github.com/Taiterbase/vtrips/apps/frontend/web/views"
+)
+
+// Trip is the resolver for the trip field.
+func (r *applicationResolver) Trip(ctx context.Context, obj *tripsclient.Application) (*tripsclient.Trip, error) {
+	return loadTrip(ctx, tripKey{orgID: obj.OrgID, tripID: obj.TripID})
+}
+
+// Applicant is the resolver for the applicant field.
+func (r *applicationResolver) Applicant(ctx context.Context, obj *tripsclient.Application) (*usersclient.PublicProfile, error) {
+	user, found, err := forRequest(ctx).users.load(ctx, obj.UserID)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// URL is the resolver for the url field.
+func (r *photoResolver) URL(ctx context.Context, obj *tripsclient.Media, variant string) (string, error) {
+	return views.MediaPath(obj.TripID, obj.ID, variant), nil
+}
+
+// Trip is the resolver for the trip field.
+func (r *queryResolver) Trip(ctx context.Context, id string, orgID *string) (*tripsclient.Trip, error) {
+	key := tripKey{tripID: id}
+	if orgID != nil {
+		key.orgID = *orgID
+	}
+	return loadTrip(ctx, key)
+}
+
+// Trips is the resolver for the trips field.
+func (r *queryResolver) Trips(ctx context.Context, filter *TripFilter, first *int, after *string) (*TripConnection, error) {
+	if err := checkFirst(first); err != nil {
+		return nil, err
+	}
+	params := tripsclient.ListPublicTripsParams{Limit: pageSize(first, defaultTripsPage), After: deref(after)}
+	if filter != nil {
+		params.TripType = deref(filter.TripType)
+		params.HousingType = deref(filter.HousingType)
+		params.PrivacyType = deref(filter.PrivacyType)
+		params.Country = deref(filter.Country)
+		params.City = deref(filter.City)
+		params.From = deref(filter.From)
+		params.To = deref(filter.To)
+		params.PriceMin = deref(filter.PriceMin)
+		params.PriceMax = deref(filter.PriceMax)
+		params.Currency = deref(filter.Currency)
+	}
+	req := forRequest(ctx)
+	public, err := req.Trips.ListPublicTrips(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	list, err := publicTrips(public)
+	if err != nil {
+		return nil, err
+	}
+	return tripConnection(req, list), nil
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*usersclient.Profile, error) {
+	req := forRequest(ctx)
+	if !req.SignedIn {
+		return nil, nil
+	}
+	me, err := req.Users.GetMe(ctx)
+	if usersclient.StatusCode(err) == http.StatusUnauthorized {
+		// a stale session is as good as none
+		return nil, nil
+	}
+	return me, err
+}
+
+// Likes is the resolver for the likes field.
+func (r *queryResolver) Likes(ctx context.Context, first *int, after *string) (*TripConnection, error) {
+	if err := checkFirst(first); err != nil {
+		return nil, err
+	}
+	req := forRequest(ctx)
+	list, err := req.Trips.ListLikes(ctx, tripsclient.ListLikesParams{Limit: pageSize(first, defaultLikesPage), After: deref(after)})
+	if err != nil {
+		return nil, err
+	}
+	return tripConnection(req, list), nil
+}
+
+// Applications is the resolver for the applications field.
+func (r *queryResolver) Applications(ctx context.Context, status *tripsclient.ApplicationStatus, mine *bool) ([]*tripsclient.Application, error) {
+	req := forRequest(ctx)
+	var (
+		list *tripsclient.ApplicationList
+		err  error
+	)
+	if deref(mine) {
+		list, err = req.Trips.ListMyApplications(ctx)
+	} else {
+		list, err = req.Trips.ListApplications(ctx, tripsclient.ListApplicationsParams{Status: deref(status)})
+	}
+	if err != nil {
+		return nil, err
+	}
+	apps := make([]*tripsclient.Application, 0, len(list.Applications))
+	for i := range list.Applications {
+		// the volunteer's own list isn't filtered by the trips service
+		if status != nil && list.Applications[i].Status != *status {
+			continue
+		}
+		apps = append(apps, &list.Applications[i])
+	}
+	return apps, nil
+}
+
+// OrgID is the resolver for the orgID field.
+func (r *tripResolver) OrgID(ctx context.Context, obj *tripsclient.Trip) (*string, error) {
+	if obj.OrgID == "" {
+		return nil, nil
+	}
+	return &obj.OrgID, nil
+}
+
+// Liked is the resolver for the liked field.
+func (r *tripResolver) Liked(ctx context.Context, obj *tripsclient.Trip) (bool, error) {
+	return forRequest(ctx).likedTrips(ctx)[obj.ID], nil
+}
+
+// Application returns ApplicationResolver implementation.
+func (r *Resolver) Application() ApplicationResolver { return &applicationResolver{r} }
+
+// Photo returns PhotoResolver implementation.
+func (r *Resolver) Photo() PhotoResolver { return &photoResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Trip returns TripResolver implementation.
+func (r *Resolver) Trip() TripResolver { return &tripResolver{r} }
+
+type applicationResolver struct{ *Resolver }
+type photoResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type tripResolver struct{ *Resolver }
@@ -0,0 +1,5 @@
+// Package tripsclient calls the trips service through a client generated from the
+// service's OpenAPI document.
+package tripsclient
+
+//go:generate go run ../../cmd/clientgen -spec ../../../trips/internal/api/openapi.json -out client_gen.go
@@ -0,0 +1,5 @@
+// Package usersclient calls the users service through a client generated from the
+// service's OpenAPI document.
+package usersclient
+
+//go:generate go run ../../cmd/clientgen -spec ../../../users/internal/api/openapi.json -out client_gen.go
@@ -0,0 +1,117 @@
+// Trip map: any element with data-trip-map="<url>" becomes a Leaflet map that loads GeoJSON
+// from <url>, adding the visible bbox and zoom every time the view settles. The server
+// clusters nearby trips, so each feature is either one trip or a cluster with a count.
+(function () {
+  const LEAFLET = "https://unpkg.com/leaflet@1.9.4/dist/";
+  let leaflet;
+
+  // loadLeaflet adds Leaflet to the page the first time a map needs it.
+  function loadLeaflet() {
+    if (!leaflet) {
+      leaflet = new Promise(function (resolve, reject) {
+        const css = document.createElement("link");
+        css.rel = "stylesheet";
+        css.href = LEAFLET + "leaflet.css";
+        css.integrity = "sha256-p4NxAoJBhIIN+hmNHrzRCf9tD/miZyoHS5obTRR9BMY=";
+        css.crossOrigin = "";
+        document.head.appendChild(css);
+
+        const js = document.createElement("script");
+        js.src = LEAFLET + "leaflet.js";
+        js.integrity = "sha256-20nQCchB9co0qIjJZRGuk2/Z9VM+kNiyxNV1lvTlZBo=";
+        js.crossOrigin = "";
+        js.onload = function () { resolve(window.L); };
+        js.onerror = reject;
+        document.head.appendChild(js);
+      });
+    }
+    return leaflet;
+  }
+
+  function escapeHTML(s) {
+    const div = document.createElement("div");
+    div.textContent = s || "";
+    return div.innerHTML;
+  }
+
+  function popup(props) {
+    let html = '<a href="' + escapeHTML(props.url) + '" class="font-semibold">' + escapeHTML(props.name) + "</a>";
+    if (props.location) html += "<br>" + escapeHTML(props.location);
+    if (props.dates) html += "<br>" + escapeHTML(props.dates);
+    return html;
+  }
+
+  function clusterIcon(L, count) {
+    const size = count < 10 ? 32 : count < 100 ? 40 : 48;
+    // styled inline: the prebuilt stylesheet only has classes the templates use
+    return L.divIcon({
+      html: '<span style="display:flex;align-items:center;justify-content:center;width:100%;height:100%;border-radius:9999px;' +
+        'background:rgba(99,102,241,.9);box-shadow:0 0 0 4px rgba(99,102,241,.3);color:#fafafa;font:600 13px sans-serif">' + count + "</span>",
+      className: "",
+      iconSize: [size, size],
+    });
+  }
+
+  function mount(el, L) {
+    const map = L.map(el, { worldCopyJump: true }).setView([15, 0], 2);
+    L.tileLayer("https://tile.openstreetmap.org/{z}/{x}/{y}.png", {
+      maxZoom: 19,
+      attribution: '&copy; <a href="https://www.openstreetmap.org/copyright">OpenStreetMap</a> contributors',
+    }).addTo(map);
+    const layer = L.layerGroup().addTo(map);
+    let pending;
+
+    function refresh() {
+      const b = map.getBounds();
+      const url = new URL(el.dataset.tripMap, window.location.origin);
+      url.searchParams.set("bbox", [b.getWest(), b.getSouth(), b.getEast(), b.getNorth()].map(function (v) { return v.toFixed(5); }).join(","));
+      url.searchParams.set("zoom", map.getZoom());
+      if (pending) pending.abort();
+      pending = new AbortController();
+      fetch(url, { signal: pending.signal })
+        .then(function (res) { return res.ok ? res.json() : { features: [] }; })
+        .then(function (data) {
+          layer.clearLayers();
+          data.features.forEach(function (f) {
+            const at = [f.geometry.coordinates[1], f.geometry.coordinates[0]];
+            const props = f.properties || {};
+            if (props.cluster) {
+              L.marker(at, { icon: clusterIcon(L, props.point_count) })
+                .on("click", function () {
+                  const bb = f.bbox;
+                  if (bb && (bb[0] !== bb[2] || bb[1] !== bb[3])) {
+                    map.fitBounds([[bb[1], bb[0]], [bb[3], bb[2]]], { padding: [24, 24] });
+                  } else {
+                    map.setView(at, map.getZoom() + 2);
+                  }
+                })
+                .addTo(layer);
+              return;
+            }
+            L.circleMarker(at, { radius: 7, color: "#6366f1", weight: 2, fillOpacity: 0.8 })
+              .bindPopup(popup(props))
+              .addTo(layer);
+          });
+        })
+        .catch(function (err) {
+          if (err.name !== "AbortError") console.error(err);
+        });
+    }
+
+    map.on("moveend", refresh);
+    refresh();
+  }
+
+  htmx.onLoad(function (root) {
+    const maps = root.querySelectorAll ? root.querySelectorAll("[data-trip-map]") : [];
+    if (maps.length === 0) return;
+    loadLeaflet().then(function (L) {
+      maps.forEach(function (el) {
+        if (!el.dataset.tripMapMounted) {
+          el.dataset.tripMapMounted = "true";
+          mount(el, L);
+        }
+      });
+    });
+  });
+})();
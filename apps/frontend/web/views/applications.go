@@ -0,0 +1,139 @@
+package views
+
+import (
+	"fmt"
+	"time"
+)
+
+// ApplicationFilters are the status tabs on the org applications page; "" shows all.
+var ApplicationFilters = []string{"", "pending", "waitlisted", "offered", "approved", "rejected", "withdrawn"}
+
+type ApplicationSummary struct {
+	ID          string
+	TripID      string
+	Applicant   string
+	Status      string
+	StatusClass string
+	Submitted   string
+}
+
+type ApplicationAnswer struct {
+	Prompt string
+	Answer string
+}
+
+type ApplicationAuditLine struct {
+	When   string
+	Actor  string
+	Action string
+	Note   string
+}
+
+type ApplicationDetail struct {
+	ApplicationSummary
+	Note    string
+	Answers []ApplicationAnswer
+	Audit   []ApplicationAuditLine
+	// Error is shown above the decision buttons, e.g. when the trip is full.
+	Error string
+}
+
+// CanDecide reports whether the org can still approve, reject or waitlist the application.
+func (d ApplicationDetail) CanDecide() bool {
+	return d.Status == "pending" || d.Status == "waitlisted" || d.Status == "offered" || d.Status == "approved"
+}
+
+func applicationStatusBadge(status string) string {
+	switch status {
+	case "approved":
+		return "px-2 py-1 rounded-full text-xs font-medium bg-green-100 text-green-800"
+	case "rejected":
+		return "px-2 py-1 rounded-full text-xs font-medium bg-red-100 text-red-800"
+	case "waitlisted", "offered":
+		return "px-2 py-1 rounded-full text-xs font-medium bg-yellow-100 text-yellow-800"
+	default:
+		return "px-2 py-1 rounded-full text-xs font-medium bg-gray-100 text-gray-800"
+	}
+}
+
+func applicationFilterLabel(status string) string {
+	if status == "" {
+		return "All"
+	}
+	return status
+}
+
+func applicationFilterHref(status string) string {
+	if status == "" {
+		return "/org/applications"
+	}
+	return "/org/applications?status=" + status
+}
+
+func formatTimestamp(ts int64) string {
+	if ts == 0 {
+		return ""
+	}
+	return time.Unix(ts, 0).UTC().Format("Jan 2, 2006 15:04")
+}
+
+func NewApplicationSummaryFromPayload(data map[string]any) ApplicationSummary {
+	status := fmt.Sprint(data["status"])
+	applicant, _ := data["username"].(string)
+	if applicant == "" {
+		applicant = fmt.Sprint(data["user_id"])
+	}
+	return ApplicationSummary{
+		ID:          fmt.Sprint(data["id"]),
+		TripID:      fmt.Sprint(data["trip_id"]),
+		Applicant:   applicant,
+		Status:      status,
+		StatusClass: applicationStatusBadge(status),
+		Submitted:   formatTimestamp(toInt64(data["created_at"])),
+	}
+}
+
+// NewApplicationDetailFromPayload builds the show page from the trips service's
+// {"application", "questions", "audit"} response.
+func NewApplicationDetailFromPayload(data map[string]any) ApplicationDetail {
+	app, _ := data["application"].(map[string]any)
+	detail := ApplicationDetail{ApplicationSummary: NewApplicationSummaryFromPayload(app)}
+	detail.Note, _ = app["note"].(string)
+
+	prompts := map[string]string{}
+	questions, _ := data["questions"].([]any)
+	for _, raw := range questions {
+		if q, ok := raw.(map[string]any); ok {
+			prompts[fmt.Sprint(q["id"])] = fmt.Sprint(q["prompt"])
+		}
+	}
+	answers, _ := app["answers"].([]any)
+	for _, raw := range answers {
+		a, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		id := fmt.Sprint(a["question_id"])
+		prompt := prompts[id]
+		if prompt == "" {
+			prompt = id
+		}
+		detail.Answers = append(detail.Answers, ApplicationAnswer{Prompt: prompt, Answer: fmt.Sprint(a["answer"])})
+	}
+
+	audit, _ := data["audit"].([]any)
+	for _, raw := range audit {
+		e, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		note, _ := e["note"].(string)
+		detail.Audit = append(detail.Audit, ApplicationAuditLine{
+			When:   formatTimestamp(toInt64(e["at"])),
+			Actor:  fmt.Sprint(e["actor_id"]),
+			Action: fmt.Sprint(e["action"]),
+			Note:   note,
+		})
+	}
+	return detail
+}
@@ -0,0 +1,106 @@
+package views
+
+import (
+	"net/url"
+	"strings"
+)
+
+// BrowseFilters are the browse page's search controls. Each maps straight onto a query
+// parameter of the trips service's public listing, so the page URL is the search.
+type BrowseFilters struct {
+	TripType    string
+	HousingType string
+	PrivacyType string
+	Country     string
+	From        string
+	To          string
+	PriceMin    string
+	PriceMax    string
+	// Currency is the price range's currency; trips priced in others are converted
+	Currency string
+}
+
+type browseOption struct {
+	Value string
+	Label string
+}
+
+var browseCurrencies = []browseOption{
+	{"", "USD"}, {"EUR", "EUR"}, {"GBP", "GBP"}, {"CAD", "CAD"}, {"MXN", "MXN"},
+}
+
+func BrowseFiltersFromQuery(q url.Values) BrowseFilters {
+	return BrowseFilters{
+		TripType:    q.Get("trip_type"),
+		HousingType: q.Get("housing_type"),
+		PrivacyType: q.Get("privacy_type"),
+		Country:     strings.ToUpper(strings.TrimSpace(q.Get("country"))),
+		From:        q.Get("from"),
+		To:          q.Get("to"),
+		PriceMin:    q.Get("price_min"),
+		PriceMax:    q.Get("price_max"),
+		Currency:    strings.ToUpper(q.Get("currency")),
+	}
+}
+
+// Query returns the non-empty filters as query parameters.
+func (f BrowseFilters) Query() url.Values {
+	q := url.Values{}
+	for key, value := range map[string]string{
+		"trip_type":    f.TripType,
+		"housing_type": f.HousingType,
+		"privacy_type": f.PrivacyType,
+		"country":      f.Country,
+		"from":         f.From,
+		"to":           f.To,
+		"price_min":    f.PriceMin,
+		"price_max":    f.PriceMax,
+		"currency":     f.Currency,
+	} {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+	return q
+}
+
+func browsePageHref(f BrowseFilters, after string) string {
+	q := f.Query()
+	q.Set("after", after)
+	return "/browse?" + q.Encode()
+}
+
+// browseMapHref is where the browse map loads its features, with the page's filters. The
+// map script adds the visible bbox and zoom.
+func browseMapHref(f BrowseFilters) string {
+	return "/browse/map?" + f.Query().Encode()
+}
+
+// BrowseMapFromPayload trims a trips service FeatureCollection down to what the map's
+// markers and popups show, so the browser doesn't download every trip's description.
+func BrowseMapFromPayload(data map[string]any) map[string]any {
+	features := []any{}
+	list, _ := data["features"].([]any)
+	for _, entry := range list {
+		feature, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		props, _ := feature["properties"].(map[string]any)
+		if cluster, _ := props["cluster"].(bool); cluster {
+			feature["properties"] = map[string]any{"cluster": true, "point_count": props["point_count"]}
+		} else {
+			card := NewTripCardFromPayload(props, false)
+			feature["properties"] = map[string]any{
+				"id":       card.ID,
+				"name":     card.Name,
+				"location": card.Location,
+				"dates":    card.DateRange,
+				"url":      TripPublicPath(card.ID, card.Name),
+				"thumb":    card.Cover.URL,
+			}
+		}
+		features = append(features, feature)
+	}
+	return map[string]any{"type": "FeatureCollection", "features": features}
+}
@@ -0,0 +1,23 @@
+package views
+
+import "github.com/Taiterbase/vtrips/apps/frontend/internal/tripsclient"
+
+// TripEnums are the options for each of a trip's enum fields, keyed by field, as the
+// trips service's GET /v1/trips/schema lists them.
+type TripEnums map[string][]browseOption
+
+// TripEnumsFromSchema reads the schema endpoint's response.
+func TripEnumsFromSchema(schema *tripsclient.TripSchema) TripEnums {
+	enums := TripEnums{}
+	for _, enum := range schema.Enums {
+		for _, v := range enum.Values {
+			enums[enum.Field] = append(enums[enum.Field], browseOption{v.Name, v.Label})
+		}
+	}
+	return enums
+}
+
+// withAny puts an option matching every value ahead of the field's options, for filters.
+func (e TripEnums) withAny(field, label string) []browseOption {
+	return append([]browseOption{{"", label}}, e[field]...)
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/Taiterbase/vtrips/apps/frontend/internal/tripsclient"
 	"github.com/a-h/templ"
 )
 
@@ -14,13 +15,17 @@ type TripWizardStep string
 const (
 	TripWizardStepBasics    TripWizardStep = "basics"
 	TripWizardStepLogistics TripWizardStep = "logistics"
+	TripWizardStepItinerary TripWizardStep = "itinerary"
+	TripWizardStepMedia     TripWizardStep = "media"
 	TripWizardStepReview    TripWizardStep = "review"
 )
 
 var wizardOrder = map[TripWizardStep]int{
 	TripWizardStepBasics:    1,
 	TripWizardStepLogistics: 2,
-	TripWizardStepReview:    3,
+	TripWizardStepItinerary: 3,
+	TripWizardStepMedia:     4,
+	TripWizardStepReview:    5,
 }
 
 type TripsWizardData struct {
@@ -28,6 +33,8 @@ type TripsWizardData struct {
 	TripID string
 	OrgID  string
 	Form   map[string]string
+	// Enums are the options for the wizard's enum selects
+	Enums TripEnums
 }
 
 type TripSummary struct {
@@ -215,8 +222,9 @@ func TripFormFromPayload(data map[string]any) map[string]string {
 	if val, ok := data["volunteer_limit"]; ok {
 		form["volunteer_limit"] = intString(val)
 	}
-	if val, ok := data["price"]; ok {
-		form["price"] = floatString(val)
+	// price is in minor units, so forms show the trips service's decimal rendering
+	if val, ok := data["price_decimal"].(string); ok && val != "" {
+		form["price"] = val
 	}
 	if val, ok := data["latitude"]; ok {
 		form["latitude"] = floatString(val)
@@ -235,6 +243,54 @@ func TripFormFromPayload(data map[string]any) map[string]string {
 	return form
 }
 
+// TripFormFromTrip fills the wizard's fields from a trip, like TripFormFromPayload.
+func TripFormFromTrip(t *tripsclient.Trip) map[string]string {
+	form := make(map[string]string)
+	set := func(key, val string) {
+		if val != "" {
+			form[key] = val
+		}
+	}
+	set("name", t.Name)
+	set("privacy_type", string(t.PrivacyType))
+	set("housing_type", string(t.HousingType))
+	set("trip_type", string(t.TripType))
+	set("city", t.City)
+	set("country", t.Country)
+	set("currency", t.Currency)
+	set("description", t.Description)
+	set("mission", t.Mission)
+	set("status", string(t.Status))
+	form["volunteer_limit"] = strconv.Itoa(t.VolunteerLimit)
+	// price is in minor units, so forms show the trips service's decimal rendering
+	set("price", t.PriceDecimal)
+	form["latitude"] = strconv.FormatFloat(t.Latitude, 'f', -1, 64)
+	form["longitude"] = strconv.FormatFloat(t.Longitude, 'f', -1, 64)
+	set("start_date", formatDateInput(t.StartDate))
+	set("end_date", formatDateInput(t.EndDate))
+	return form
+}
+
+// NewTripSummary summarizes a trip for the dashboard's list.
+func NewTripSummary(t *tripsclient.Trip) TripSummary {
+	status := string(t.Status)
+	if status == "" {
+		status = "draft"
+	}
+	summary := TripSummary{
+		ID:          t.ID,
+		Name:        t.Name,
+		Location:    FormatLocation(t.City, t.Country),
+		Status:      status,
+		StatusClass: tripStatusBadge(status),
+		DateRange:   FormatTripDateRange(t.StartDate, t.EndDate),
+	}
+	if summary.Name == "" {
+		summary.Name = "Untitled Trip"
+	}
+	return summary
+}
+
 func NewTripSummaryFromPayload(data map[string]any) TripSummary {
 	form := TripFormFromPayload(data)
 	status := fmt.Sprint(data["status"])
@@ -0,0 +1,112 @@
+package views
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// ItineraryDay is one day of a trip's plan as the itinerary step shows it.
+type ItineraryDay struct {
+	TripID  string
+	Date    string
+	Title   string
+	Housing string
+	Items   []ItineraryItem
+}
+
+type ItineraryItem struct {
+	Index       int
+	Time        string
+	Kind        string
+	Title       string
+	Description string
+	Place       string
+	Coordinates string
+}
+
+// itineraryItemKinds are the item kinds the trips service accepts, with their labels.
+var itineraryItemKinds = []browseOption{
+	{Value: "activity", Label: "Activity"},
+	{Value: "meeting_point", Label: "Meeting point"},
+	{Value: "travel", Label: "Travel"},
+	{Value: "meal", Label: "Meal"},
+	{Value: "free_time", Label: "Free time"},
+}
+
+// NewItineraryFromPayload reads the trips service's itinerary document.
+func NewItineraryFromPayload(data map[string]any) []ItineraryDay {
+	tripID, _ := data["trip_id"].(string)
+	list, _ := data["days"].([]any)
+	days := make([]ItineraryDay, 0, len(list))
+	for _, entry := range list {
+		raw, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		day := ItineraryDay{TripID: tripID}
+		day.Date, _ = raw["date"].(string)
+		day.Title, _ = raw["title"].(string)
+		if housing, ok := raw["housing"].(map[string]any); ok {
+			day.Housing = placeLabel(housing)
+		}
+		items, _ := raw["items"].([]any)
+		for i, item := range items {
+			if m, ok := item.(map[string]any); ok {
+				day.Items = append(day.Items, newItineraryItem(i, m))
+			}
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+func newItineraryItem(index int, data map[string]any) ItineraryItem {
+	item := ItineraryItem{Index: index}
+	item.Time, _ = data["time"].(string)
+	item.Kind, _ = data["kind"].(string)
+	item.Title, _ = data["title"].(string)
+	item.Description, _ = data["description"].(string)
+	if place, ok := data["place"].(map[string]any); ok {
+		item.Place = placeLabel(place)
+		lat, latOK := place["latitude"].(float64)
+		long, longOK := place["longitude"].(float64)
+		if latOK && longOK {
+			item.Coordinates = fmt.Sprintf("%.5f, %.5f", lat, long)
+		}
+	}
+	return item
+}
+
+func placeLabel(place map[string]any) string {
+	name, _ := place["name"].(string)
+	address, _ := place["address"].(string)
+	switch {
+	case name != "" && address != "":
+		return name + ", " + address
+	case name != "":
+		return name
+	default:
+		return address
+	}
+}
+
+func itemKindLabel(kind string) string {
+	for _, opt := range itineraryItemKinds {
+		if opt.Value == kind {
+			return opt.Label
+		}
+	}
+	return kind
+}
+
+func HXTripItineraryPath(tripID string) string {
+	return fmt.Sprintf("/trips/%s/itinerary", tripID)
+}
+
+func HXItineraryDayPath(day ItineraryDay) string {
+	return fmt.Sprintf("/trips/%s/itinerary/days/%s", day.TripID, url.PathEscape(day.Date))
+}
+
+func HXItineraryItemPath(day ItineraryDay, item ItineraryItem) string {
+	return fmt.Sprintf("%s/items/%d", HXItineraryDayPath(day), item.Index)
+}
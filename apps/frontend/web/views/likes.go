@@ -0,0 +1,67 @@
+package views
+
+import "fmt"
+
+// TripCard is a trip tile on the browse and likes pages.
+type TripCard struct {
+	ID        string
+	Name      string
+	Location  string
+	DateRange string
+	Liked     bool
+	LikeCount int64
+	Cover     Cover
+}
+
+// LikeState is what the heart button needs to render itself after a toggle.
+type LikeState struct {
+	TripID    string
+	Liked     bool
+	LikeCount int64
+}
+
+func NewTripCardFromPayload(data map[string]any, liked bool) TripCard {
+	summary := NewTripSummaryFromPayload(data)
+	return TripCard{
+		ID:        summary.ID,
+		Name:      summary.Name,
+		Location:  summary.Location,
+		DateRange: summary.DateRange,
+		Liked:     liked,
+		LikeCount: toInt64(data["like_count"]),
+		Cover:     coverFromPayload(data, "card"),
+	}
+}
+
+// NewLikeStateFromPayload reads the trips service's {"trip_id", "liked", "like_count"} response.
+func NewLikeStateFromPayload(data map[string]any) LikeState {
+	liked, _ := data["liked"].(bool)
+	return LikeState{
+		TripID:    fmt.Sprint(data["trip_id"]),
+		Liked:     liked,
+		LikeCount: toInt64(data["like_count"]),
+	}
+}
+
+func (card TripCard) LikeState() LikeState {
+	return LikeState{TripID: card.ID, Liked: card.Liked, LikeCount: card.LikeCount}
+}
+
+func likeButtonID(tripID string) string {
+	return "like-" + tripID
+}
+
+func likeFill(liked bool) string {
+	if liked {
+		return "currentColor"
+	}
+	return "none"
+}
+
+func likePath(tripID string) string {
+	return "/likes/" + tripID
+}
+
+func likesPageHref(after string) string {
+	return "/likes?after=" + after
+}
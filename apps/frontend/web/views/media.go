@@ -0,0 +1,88 @@
+package views
+
+import "fmt"
+
+// Photo is one of a trip's uploaded photos as the media step shows it.
+type Photo struct {
+	ID       string
+	TripID   string
+	Alt      string
+	Position int
+	Cover    bool
+}
+
+// Cover is the image shown for a trip on cards and its public page.
+type Cover struct {
+	URL string
+	Alt string
+}
+
+func NewPhotoFromPayload(data map[string]any) Photo {
+	cover, _ := data["cover"].(bool)
+	alt, _ := data["alt"].(string)
+	return Photo{
+		ID:       fmt.Sprint(data["id"]),
+		TripID:   fmt.Sprint(data["trip_id"]),
+		Alt:      alt,
+		Position: int(toInt64(data["position"])),
+		Cover:    cover,
+	}
+}
+
+// NewPhotosFromPayload reads the trips service's {"media": [...]} list response.
+func NewPhotosFromPayload(data map[string]any) []Photo {
+	list, _ := data["media"].([]any)
+	photos := make([]Photo, 0, len(list))
+	for _, entry := range list {
+		if m, ok := entry.(map[string]any); ok {
+			photos = append(photos, NewPhotoFromPayload(m))
+		}
+	}
+	return photos
+}
+
+// coverFromPayload picks the given size of a trip's cover photo, if it has one.
+func coverFromPayload(data map[string]any, variant string) Cover {
+	m, ok := data["cover"].(map[string]any)
+	if !ok {
+		return Cover{}
+	}
+	photo := NewPhotoFromPayload(m)
+	return Cover{URL: MediaPath(photo.TripID, photo.ID, variant), Alt: photo.Alt}
+}
+
+// MediaPath is where the frontend serves a photo variant; browsers can't reach the
+// trips service directly.
+func MediaPath(tripID, mediaID, variant string) string {
+	return fmt.Sprintf("/media/%s/%s/%s", tripID, mediaID, variant)
+}
+
+func (p Photo) ThumbURL() string {
+	return MediaPath(p.TripID, p.ID, "thumb")
+}
+
+func HXTripMediaPath(tripID string) string {
+	return fmt.Sprintf("/trips/%s/media", tripID)
+}
+
+func HXPhotoPath(p Photo) string {
+	return fmt.Sprintf("/trips/%s/media/%s", p.TripID, p.ID)
+}
+
+func hxPhotoMoveVals(position int) string {
+	return fmt.Sprintf("{\"position\":\"%d\"}", position)
+}
+
+func photoCount(n int) string {
+	if n == 1 {
+		return "1 photo"
+	}
+	return fmt.Sprintf("%d photos", n)
+}
+
+func photoAltLabel(p Photo) string {
+	if p.Alt == "" {
+		return "Trip photo"
+	}
+	return p.Alt
+}
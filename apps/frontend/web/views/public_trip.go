@@ -0,0 +1,175 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const siteName = "Voluntrips"
+
+// TripPage is the public, shareable page for one listed trip.
+type TripPage struct {
+	TripCard
+	Description  string
+	Mission      string
+	City         string
+	Country      string
+	Price        string
+	Seats        string
+	CanonicalURL string
+	// Image is the large cover photo; ImageURL is its absolute URL for share cards.
+	Image    Cover
+	ImageURL string
+	// CalendarPath downloads the trip as an .ics file; empty until the trip has dates.
+	CalendarPath string
+	// StructuredData is the schema.org Event rendered as JSON-LD.
+	StructuredData map[string]any
+}
+
+// TripSlug turns a trip name into the readable part of its public URL, e.g.
+// "Clean Water – Lima" becomes "clean-water-lima".
+func TripSlug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFKD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue // drop accents left over from decomposition
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(unicode.ToLower(r))
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return "trip"
+	}
+	return slug
+}
+
+// TripPublicPath is the canonical path of a trip's public page.
+func TripPublicPath(id, name string) string {
+	return fmt.Sprintf("/t/%s/%s", id, TripSlug(name))
+}
+
+// TripCalendarPath serves a listed trip as an iCalendar file.
+func TripCalendarPath(id string) string {
+	return "/t/" + id + "/calendar.ics"
+}
+
+// NewTripPageFromPayload builds the public page from a trips service trip. baseURL is the
+// site's absolute origin, used for canonical and share URLs.
+func NewTripPageFromPayload(data map[string]any, baseURL string) TripPage {
+	form := TripFormFromPayload(data)
+	page := TripPage{
+		TripCard:    NewTripCardFromPayload(data, false),
+		Description: form["description"],
+		Mission:     form["mission"],
+		City:        form["city"],
+		Country:     form["country"],
+		Image:       coverFromPayload(data, "large"),
+	}
+	page.CanonicalURL = baseURL + TripPublicPath(page.ID, page.Name)
+	if page.Image.URL != "" {
+		page.ImageURL = baseURL + page.Image.URL
+	}
+	// price is in minor units; the form holds it in major units, as the trips service formats it
+	if minor, _ := data["price"].(float64); minor > 0 {
+		page.Price = strings.TrimSpace(fmt.Sprintf("%s %s", form["price"], form["currency"]))
+	} else {
+		page.Price = "Free"
+	}
+	if start, _ := data["start_date"].(float64); start > 0 {
+		page.CalendarPath = TripCalendarPath(page.ID)
+	}
+	if remaining, ok := data["seats_remaining"].(float64); ok {
+		page.Seats = fmt.Sprintf("%d seats left", int(remaining))
+	}
+	page.StructuredData = tripEvent(page, data, form["price"])
+	return page
+}
+
+// tripEvent describes the trip as a schema.org Event so search engines can show dates,
+// place and price.
+func tripEvent(page TripPage, data map[string]any, price string) map[string]any {
+	event := map[string]any{
+		"@context":            "https://schema.org",
+		"@type":               "Event",
+		"name":                page.Name,
+		"url":                 page.CanonicalURL,
+		"eventStatus":         "https://schema.org/EventScheduled",
+		"eventAttendanceMode": "https://schema.org/OfflineEventAttendanceMode",
+	}
+	if page.Description != "" {
+		event["description"] = page.Description
+	}
+	if page.ImageURL != "" {
+		event["image"] = page.ImageURL
+	}
+	if start := toInt64(data["start_date"]); start != 0 {
+		event["startDate"] = time.Unix(start, 0).UTC().Format("2006-01-02")
+	}
+	if end := toInt64(data["end_date"]); end != 0 {
+		event["endDate"] = time.Unix(end, 0).UTC().Format("2006-01-02")
+	}
+	if page.Location != "" {
+		place := map[string]any{
+			"@type": "Place",
+			"name":  page.Location,
+			"address": map[string]any{
+				"@type":           "PostalAddress",
+				"addressLocality": page.City,
+				"addressCountry":  page.Country,
+			},
+		}
+		lat, _ := data["latitude"].(float64)
+		long, _ := data["longitude"].(float64)
+		if lat != 0 || long != 0 {
+			place["geo"] = map[string]any{"@type": "GeoCoordinates", "latitude": lat, "longitude": long}
+		}
+		event["location"] = place
+	}
+	availability := "https://schema.org/InStock"
+	if full, _ := data["full"].(bool); full {
+		availability = "https://schema.org/SoldOut"
+	}
+	offer := map[string]any{
+		"@type":        "Offer",
+		"url":          page.CanonicalURL,
+		"price":        price,
+		"availability": availability,
+	}
+	if currency, _ := data["currency"].(string); currency != "" {
+		offer["priceCurrency"] = currency
+	}
+	event["offers"] = offer
+	return event
+}
+
+// MetaDescription is the trip summary used for search snippets and share cards.
+func (p TripPage) MetaDescription() string {
+	desc := p.Description
+	if desc == "" {
+		desc = p.Mission
+	}
+	if desc == "" {
+		desc = "Volunteer with " + p.Name
+		if p.Location != "" {
+			desc += " in " + p.Location
+		}
+		if p.DateRange != "Dates TBD" {
+			desc += ", " + p.DateRange
+		}
+	}
+	if runes := []rune(desc); len(runes) > 200 {
+		desc = strings.TrimSpace(string(runes[:197])) + "..."
+	}
+	return desc
+}
@@ -0,0 +1,187 @@
+// Command enumgen writes Go enum types from a JSON definition, so each enum's names,
+// labels and JSON handling come from one list. Run it through go generate:
+//
+//	//go:generate go run ../../cmd/enumgen -in enums.json -out enums_gen.go
+//
+// Every enum gets String, MarshalJSON, an UnmarshalJSON that rejects unknown names,
+// Parse<Type> and <Type>Values, plus an entry in the package's Enums list. Int enums
+// count up from their first value, which is also what String falls back to.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"text/template"
+	"unicode"
+)
+
+type enumValue struct {
+	Const string `json:"const"`
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Doc   string `json:"doc"`
+}
+
+type enumDef struct {
+	Type string `json:"type"`
+	// Kind is the underlying type, int or string
+	Kind string `json:"kind"`
+	// Field is the JSON field the enum is usually found in, for error messages
+	Field  string      `json:"field"`
+	Doc    string      `json:"doc"`
+	Values []enumValue `json:"values"`
+}
+
+// Var is the unexported name of the enum's name list.
+func (d enumDef) Var() string {
+	r := []rune(d.Type)
+	r[0] = unicode.ToLower(r[0])
+	return string(r) + "Names"
+}
+
+func main() {
+	in := flag.String("in", "enums.json", "enum definitions")
+	out := flag.String("out", "enums_gen.go", "generated Go file")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "package of the generated file")
+	flag.Parse()
+
+	b, err := os.ReadFile(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var defs []enumDef
+	if err = json.Unmarshal(b, &defs); err != nil {
+		log.Fatalf("%s: %v", *in, err)
+	}
+	for _, d := range defs {
+		if err = check(d); err != nil {
+			log.Fatalf("%s: %v", *in, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	err = enumTemplate.Execute(&buf, map[string]any{"Package": *pkg, "Source": *in, "Enums": defs})
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("formatting generated code: %v", err)
+	}
+	if err = os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func check(d enumDef) error {
+	if d.Type == "" || d.Field == "" || len(d.Values) == 0 {
+		return fmt.Errorf("enums need a type, a field and values")
+	}
+	if d.Kind != "int" && d.Kind != "string" {
+		return fmt.Errorf("%s: kind must be int or string", d.Type)
+	}
+	seen := map[string]bool{}
+	for _, v := range d.Values {
+		if v.Const == "" || v.Name == "" || seen[v.Name] {
+			return fmt.Errorf("%s: every value needs a const and a unique name", d.Type)
+		}
+		seen[v.Name] = true
+	}
+	return nil
+}
+
+var enumTemplate = template.Must(template.New("enums").Funcs(template.FuncMap{
+	"quote": func(s string) string { return fmt.Sprintf("%q", s) },
+}).Parse(`// Code generated by enumgen from {{.Source}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import "encoding/json"
+
+// Enums describes every generated enum, in definition order.
+var Enums = []EnumSchema{
+{{- range .Enums}}
+	{Field: {{quote .Field}}, Default: {{quote (index .Values 0).Name}}, Values: []EnumValue{
+	{{- range .Values}}
+		{Name: {{quote .Name}}, Label: {{quote .Label}}},
+	{{- end}}
+	}},
+{{- end}}
+}
+{{range $e := .Enums}}
+// {{$e.Doc}}
+type {{$e.Type}} {{$e.Kind}}
+
+const (
+{{- range $i, $v := $e.Values}}
+	{{- if $v.Doc}}
+	// {{$v.Doc}}
+	{{- end}}
+	{{- if eq $e.Kind "int"}}
+	{{$v.Const}}{{if eq $i 0}} {{$e.Type}} = iota{{end}}
+	{{- else}}
+	{{$v.Const}} {{$e.Type}} = {{quote $v.Name}}
+	{{- end}}
+{{- end}}
+)
+
+var {{$e.Var}} = []string{ {{- range $i, $v := $e.Values}}{{if $i}}, {{end}}{{quote $v.Name}}{{end -}} }
+
+{{if eq $e.Kind "int" -}}
+// String returns the {{$e.Field}} name, or {{quote (index $e.Values 0).Name}} for values out of range.
+func (e {{$e.Type}}) String() string {
+	if e < 0 || int(e) >= len({{$e.Var}}) {
+		return {{$e.Var}}[0]
+	}
+	return {{$e.Var}}[e]
+}
+{{- else -}}
+// String returns the {{$e.Field}} name.
+func (e {{$e.Type}}) String() string {
+	return string(e)
+}
+{{- end}}
+
+// MarshalJSON writes the {{$e.Field}} name.
+func (e {{$e.Type}}) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.String())
+}
+
+// UnmarshalJSON reads a {{$e.Field}} name, rejecting unknown ones with an *EnumError.
+// null leaves the value as it is.
+func (e *{{$e.Type}}) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	v, err := Parse{{$e.Type}}(name)
+	if err != nil {
+		return err
+	}
+	*e = v
+	return nil
+}
+
+// Parse{{$e.Type}} returns the {{$e.Type}} called name.
+func Parse{{$e.Type}}(name string) ({{$e.Type}}, error) {
+	for i, n := range {{$e.Var}} {
+		if n == name {
+			return {{$e.Type}}({{if eq $e.Kind "int"}}i{{else}}{{$e.Var}}[i]{{end}}), nil
+		}
+	}
+	return {{if eq $e.Kind "int"}}0{{else}}""{{end}}, &EnumError{Field: {{quote $e.Field}}, Value: name, Allowed: {{$e.Var}}}
+}
+
+// {{$e.Type}}Values lists every {{$e.Type}}, in definition order.
+func {{$e.Type}}Values() []{{$e.Type}} {
+	return []{{$e.Type}}{ {{- range $i, $v := $e.Values}}{{if $i}}, {{end}}{{$v.Const}}{{end -}} }
+}
+{{end}}`))
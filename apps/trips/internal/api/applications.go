@@ -0,0 +1,237 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+)
+
+// decisions maps the org-facing decision names to the status they set.
+var decisions = map[string]models.ApplicationStatus{
+	"approve":  models.ApplicationApproved,
+	"reject":   models.ApplicationRejected,
+	"waitlist": models.ApplicationWaitlisted,
+}
+
+// applicationFilters are the query parameters list endpoints accept besides org_id.
+var applicationFilters = []string{"trip_id", "status", "user_id"}
+
+func ApplyHandler(c echo.Context) error {
+	s := session(c)
+	trip, err := storage.ReadTrip(c, c.Param("trip_id"))
+	switch err {
+	case nil:
+		break
+	case models.ErrTripNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if trip.GetStatus() != models.TripStatusListed {
+		return problem.JSON(c, http.StatusConflict, models.ErrTripNotOpen)
+	}
+
+	app := models.NewApplication(trip, s.UserID, s.Username)
+	var body struct {
+		Answers []models.Answer `json:"answers"`
+	}
+	if err = c.Bind(&body); err != nil {
+		return badBind(c, err)
+	}
+	app.Answers = body.Answers
+	if err = app.CheckAnswers(trip.GetQuestions()); err != nil {
+		return problem.JSON(c, http.StatusBadRequest, err)
+	}
+	if err = app.Validate(); err != nil {
+		return problem.JSON(c, http.StatusBadRequest, err)
+	}
+
+	err = storage.CreateApplication(c, app)
+	switch err {
+	case nil:
+		return c.JSON(http.StatusCreated, app)
+	case models.ErrAlreadyApplied:
+		return problem.JSON(c, http.StatusConflict, err)
+	default:
+		c.Logger().Error(err)
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+}
+
+// ListApplications lists an org's applications, filtered by trip_id, status or user_id.
+// Only members of the org (the session's tenant) may call it; org_id defaults to the tenant.
+func ListApplications(c echo.Context) error {
+	orgID := c.QueryParam("org_id")
+	if orgID == "" {
+		orgID = session(c).OrgID
+	}
+	if orgID == "" {
+		return problem.JSON(c, http.StatusBadRequest, models.ErrInvalidOrgID)
+	}
+	if session(c).OrgID != orgID {
+		return problem.Message(c, http.StatusForbidden, "forbidden")
+	}
+	filters := map[string][]string{"org_id": {orgID}}
+	for _, field := range applicationFilters {
+		if values := c.QueryParams()[field]; len(values) > 0 {
+			filters[field] = values
+		}
+	}
+	return listApplications(c, filters)
+}
+
+// ListTripApplications lists the applications for one trip to members of its org.
+func ListTripApplications(c echo.Context) error {
+	trip, err := storage.ReadTrip(c, c.Param("trip_id"))
+	switch err {
+	case nil:
+		break
+	case models.ErrTripNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if session(c).OrgID != trip.GetOrgID() {
+		return problem.Message(c, http.StatusForbidden, "forbidden")
+	}
+	filters := map[string][]string{"trip_id": {trip.GetID()}}
+	if values := c.QueryParams()["status"]; len(values) > 0 {
+		filters["status"] = values
+	}
+	return listApplications(c, filters)
+}
+
+// MyApplications lists the signed-in volunteer's own applications.
+func MyApplications(c echo.Context) error {
+	return listApplications(c, map[string][]string{"user_id": {session(c).UserID}})
+}
+
+func listApplications(c echo.Context, filters map[string][]string) error {
+	apps, scanned, err := storage.QueryApplications(c, filters)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if apps == nil {
+		apps = []*models.Application{}
+	}
+	return c.JSON(http.StatusOK, log.JSON{
+		"applications":  apps,
+		"count":         len(apps),
+		"scanned_count": scanned,
+	})
+}
+
+// readApplication loads an application the session may see: the applicant's own, or
+// any application to a trip run by the session's org.
+func readApplication(c echo.Context) (*models.Application, error) {
+	app, err := storage.ReadApplication(c, c.Param("application_id"))
+	if err != nil {
+		return nil, err
+	}
+	s := session(c)
+	if app.UserID != s.UserID && app.OrgID != s.OrgID {
+		return nil, models.ErrApplicationNotFound
+	}
+	return app, nil
+}
+
+// GetApplication returns an application with the trip's questions and its audit trail.
+func GetApplication(c echo.Context) error {
+	app, err := readApplication(c)
+	switch err {
+	case nil:
+		break
+	case models.ErrApplicationNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	var questions []models.Question
+	if trip, err := storage.ReadTrip(c, app.TripID); err == nil {
+		questions = trip.GetQuestions()
+	}
+	audit, err := storage.ReadAudit(app.ID)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"application": app,
+		"questions":   questions,
+		"audit":       audit,
+	})
+}
+
+// DecideApplication lets the trip's org approve, reject or waitlist an applicant.
+func DecideApplication(c echo.Context) error {
+	app, err := readApplication(c)
+	switch err {
+	case nil:
+		break
+	case models.ErrApplicationNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	s := session(c)
+	if app.OrgID != s.OrgID {
+		return problem.Message(c, http.StatusForbidden, "forbidden")
+	}
+	decision := c.FormValue("decision")
+	next, ok := decisions[decision]
+	if !ok {
+		return problem.Message(c, http.StatusBadRequest, "decision must be approve, reject or waitlist")
+	}
+	app, _, err = storage.TransitionApplication(c, app.ID, next, s.UserID, decision, c.FormValue("note"))
+	switch err {
+	case nil:
+		return c.JSON(http.StatusOK, app)
+	case models.ErrTripFull, models.ErrInvalidTransition:
+		return problem.JSON(c, http.StatusConflict, err)
+	default:
+		c.Logger().Error(err)
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+}
+
+// WithdrawApplication lets a volunteer pull their own application, or decline a seat offer.
+// A seat they held is offered to the next waitlisted volunteer.
+func WithdrawApplication(c echo.Context) error {
+	return applicantTransition(c, models.ApplicationWithdrawn, "withdraw")
+}
+
+// AcceptOfferHandler confirms a seat offered after a waitlist promotion.
+func AcceptOfferHandler(c echo.Context) error {
+	return applicantTransition(c, models.ApplicationApproved, "accept")
+}
+
+func applicantTransition(c echo.Context, next models.ApplicationStatus, action string) error {
+	app, err := readApplication(c)
+	switch err {
+	case nil:
+		break
+	case models.ErrApplicationNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	s := session(c)
+	if app.UserID != s.UserID {
+		return problem.Message(c, http.StatusForbidden, "forbidden")
+	}
+	if next == models.ApplicationApproved && app.Status != models.ApplicationOffered {
+		return problem.JSON(c, http.StatusConflict, models.ErrInvalidTransition)
+	}
+	app, _, err = storage.TransitionApplication(c, app.ID, next, s.UserID, action, c.FormValue("note"))
+	switch err {
+	case nil:
+		return c.JSON(http.StatusOK, app)
+	case models.ErrInvalidTransition:
+		return problem.JSON(c, http.StatusConflict, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+)
+
+// maxBatchGet is the most trips one batchGet may ask for.
+const maxBatchGet = 100
+
+type batchGetRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// BatchGetTrips returns up to maxBatchGet trips at once, read from one snapshot so they
+// agree with each other. Org members name their org with ?org_id= and get its trips;
+// anyone else only gets the public view of listed trips, like GetPublicTrip. IDs without
+// such a trip are answered in missing rather than failing the batch.
+func BatchGetTrips(c echo.Context) error {
+	orgID := c.QueryParam("org_id")
+	if orgID != "" {
+		if status, err := checkMember(c, orgID); err != nil {
+			return problem.JSON(c, status, err)
+		}
+	}
+	var req batchGetRequest
+	if err := c.Bind(&req); err != nil {
+		return badBind(c, err)
+	}
+	switch {
+	case len(req.IDs) == 0:
+		return problem.JSON(c, http.StatusBadRequest, problem.Invalid(fmt.Errorf("ids must name at least one trip"),
+			problem.FieldError{Field: "ids", Rule: "required", Message: "must name at least one trip"}))
+	case len(req.IDs) > maxBatchGet:
+		msg := fmt.Sprintf("must name at most %d trips", maxBatchGet)
+		return problem.JSON(c, http.StatusBadRequest, problem.Invalid(fmt.Errorf("ids %s", msg),
+			problem.FieldError{Field: "ids", Rule: "max", Message: msg}))
+	}
+
+	trips, _, err := storage.ReadTrips(c, req.IDs)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	visible := make([]any, 0, len(trips))
+	found := make(map[string]bool, len(trips))
+	for _, trip := range trips {
+		// someone else's trip is as good as no trip
+		switch {
+		case orgID == "" && (trip.GetStatus() != models.TripStatusListed || trip.GetDeletedAt() != 0),
+			orgID != "" && trip.GetOrgID() != orgID:
+			continue
+		case orgID == "":
+			visible = append(visible, trip.(*models.TripBase).Public())
+		default:
+			visible = append(visible, trip)
+		}
+		found[trip.GetID()] = true
+	}
+	missing := []string{}
+	for _, id := range req.IDs {
+		if !found[id] {
+			missing = append(missing, id)
+			// answer a repeated ID once
+			found[id] = true
+		}
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"trips":   visible,
+		"missing": missing,
+		"count":   len(visible),
+	})
+}
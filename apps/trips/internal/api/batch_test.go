@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/labstack/echo"
+)
+
+func batchGet(e *echo.Echo, target, token string, ids []string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(batchGetRequest{IDs: ids})
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(string(body)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestBatchGetTrips(t *testing.T) {
+	e, listed := newPublicTest(t)
+	withUsers(t)
+	draft := models.NewTrip().(*models.TripBase)
+	draft.OrgID = listed.OrgID
+	draft.Name = "Planning"
+	draft.Status = models.TripStatusDraft
+	if err := storage.CreateTrip(e.NewContext(nil, nil), draft); err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{listed.ID, draft.ID, "no-such-trip", listed.ID}
+
+	for _, tc := range []struct {
+		name    string
+		target  string
+		token   string
+		found   []string
+		missing []string
+		orgView bool
+	}{
+		{"anonymously", "/v1/trips:batchGet", "", []string{listed.ID}, []string{draft.ID, "no-such-trip"}, false},
+		{"as the org's member", "/v1/trips:batchGet?org_id=" + listed.OrgID, sessionToken(t, "member", listed.OrgID),
+			[]string{listed.ID, draft.ID}, []string{"no-such-trip"}, true},
+	} {
+		rec := batchGet(e, tc.target, tc.token, ids)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("batchGet %s: %d %s", tc.name, rec.Code, rec.Body)
+		}
+		var batch struct {
+			Trips   []map[string]any `json:"trips"`
+			Missing []string         `json:"missing"`
+			Count   int              `json:"count"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &batch); err != nil {
+			t.Fatal(err)
+		}
+		var found []string
+		for _, trip := range batch.Trips {
+			found = append(found, trip["id"].(string))
+			if _, ok := trip["org_id"]; ok != tc.orgView {
+				t.Errorf("batchGet %s: trip %s has org_id: %v", tc.name, trip["id"], ok)
+			}
+		}
+		if !slices.Equal(found, tc.found) || !slices.Equal(batch.Missing, tc.missing) || batch.Count != len(tc.found) {
+			t.Errorf("batchGet %s found %v and missed %v, want %v and %v", tc.name, found, batch.Missing, tc.found, tc.missing)
+		}
+	}
+
+	// the colon is part of the path, not the start of a parameter
+	if rec := batchGet(e, "/v1/tripsXbatchGet", "", ids); rec.Code != http.StatusNotFound {
+		t.Errorf("POST /v1/tripsXbatchGet: %d, want 404", rec.Code)
+	}
+
+	var tooMany []string
+	for i := range maxBatchGet + 1 {
+		tooMany = append(tooMany, fmt.Sprint("trip-", i))
+	}
+	if rec := batchGet(e, "/v1/trips:batchGet", "", tooMany[:maxBatchGet]); rec.Code != http.StatusOK {
+		t.Errorf("batchGet of %d trips: %d %s", maxBatchGet, rec.Code, rec.Body)
+	}
+	if rec := batchGet(e, "/v1/trips:batchGet", "", tooMany); rec.Code != http.StatusBadRequest {
+		t.Errorf("batchGet of %d trips: %d, want 400", len(tooMany), rec.Code)
+	}
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/trips/internal/ical"
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+)
+
+// tripEvent turns a trip into an all-day calendar event. Trips without a start date
+// can't be placed on a calendar and are left out.
+func tripEvent(trip models.Trip) (ical.Event, bool) {
+	t, ok := trip.(*models.TripBase)
+	if !ok || t.StartDate == 0 {
+		return ical.Event{}, false
+	}
+	ev := ical.Event{
+		UID:         t.ID + "@vtrips",
+		Sequence:    t.Sequence,
+		Created:     time.Unix(t.CreatedAt, 0),
+		Modified:    time.Unix(t.UpdatedAt, 0),
+		Start:       time.Unix(t.StartDate, 0),
+		End:         time.Unix(max(t.EndDate, t.StartDate), 0),
+		Summary:     t.Name,
+		Description: t.Description,
+		Status:      ical.StatusConfirmed,
+	}
+	if ev.Summary == "" {
+		ev.Summary = "Volunteer trip"
+	}
+	var where []string
+	for _, part := range []string{t.City, t.Country} {
+		if part != "" {
+			where = append(where, part)
+		}
+	}
+	ev.Location = strings.Join(where, ", ")
+	if t.Latitude != 0 || t.Longitude != 0 {
+		ev.Geo = &[2]float64{t.Latitude, t.Longitude}
+	}
+	if t.Status == models.TripStatusCancelled {
+		ev.Status = ical.StatusCancelled
+	}
+	return ev, true
+}
+
+// calendarResponse sends trips as a calendar, earliest first.
+func calendarResponse(c echo.Context, name string, trips []models.Trip) error {
+	cal := ical.Calendar{Name: name}
+	for _, trip := range trips {
+		if ev, ok := tripEvent(trip); ok {
+			cal.Events = append(cal.Events, ev)
+		}
+	}
+	slices.SortFunc(cal.Events, func(a, b ical.Event) int { return a.Start.Compare(b.Start) })
+	return c.Blob(http.StatusOK, ical.ContentType, cal.Encode())
+}
+
+// TripCalendar serves GET /v1/trips/:trip_id.ics: one trip as an event. Like the other
+// trip sub-resources, org callers pass ?org_id= and anyone else only sees listed trips.
+func TripCalendar(c echo.Context, tripID string) error {
+	trip, status, err := orgOrPublicTrip(c, tripID, false)
+	if err != nil {
+		return problem.JSON(c, status, err)
+	}
+	if trip.GetStartDate() == 0 {
+		return problem.Message(c, http.StatusNotFound, "Trip has no dates yet")
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.ics"`, tripID))
+	return calendarResponse(c, "", []models.Trip{trip})
+}
+
+// OrgCalendar is a subscribable feed of an org's listed trips. Listed trips are public
+// anyway, so it needs no credentials.
+func OrgCalendar(c echo.Context) error {
+	orgID := c.Param("org_id")
+	matched, _, err := queryTrips(map[string][]string{
+		"org_id": {orgID},
+		"status": {string(models.TripStatusListed)},
+	})
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	matches, err := storage.ReadIndexedTrips(c, matched.ToArray())
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	var trips []models.Trip
+	for _, t := range matches {
+		if t.GetStatus() == models.TripStatusListed && t.GetDeletedAt() == 0 {
+			trips = append(trips, t)
+		}
+	}
+	return calendarResponse(c, "Trips by "+orgID, trips)
+}
+
+func calendarFeedResponse(c echo.Context, feed *models.CalendarFeed) error {
+	return c.JSON(http.StatusOK, log.JSON{
+		"token":      feed.Token,
+		"path":       "/v1/calendars/" + feed.Token + ".ics",
+		"created_at": feed.CreatedAt,
+	})
+}
+
+// GetCalendarFeed returns the session's private feed of confirmed trips, creating it the
+// first time.
+func GetCalendarFeed(c echo.Context) error {
+	feed, err := storage.CalendarFeedFor(session(c).UserID, false)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return calendarFeedResponse(c, feed)
+}
+
+// ResetCalendarFeed gives the session a new feed token; the old URL stops working.
+func ResetCalendarFeed(c echo.Context) error {
+	feed, err := storage.CalendarFeedFor(session(c).UserID, true)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return calendarFeedResponse(c, feed)
+}
+
+// UserCalendar serves a volunteer's feed of the trips they're approved for. Calendar
+// apps can't log in, so the token in the URL is the credential. Cancelled trips stay in
+// the feed marked CANCELLED, so subscribers see the cancellation instead of the trip
+// silently disappearing.
+func UserCalendar(c echo.Context) error {
+	feed, err := storage.ReadCalendarFeed(strings.TrimSuffix(c.Param("token"), ".ics"))
+	switch err {
+	case nil:
+		break
+	case models.ErrCalendarNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	apps, _, err := storage.QueryApplications(c, map[string][]string{
+		"user_id": {feed.UserID},
+		"status":  {string(models.ApplicationApproved)},
+	})
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	var trips []models.Trip
+	for _, app := range apps {
+		t, err := storage.ReadTrip(c, app.TripID)
+		if err == models.ErrTripNotFound {
+			continue
+		}
+		if err != nil {
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+		if t.GetDeletedAt() != 0 {
+			continue
+		}
+		trips = append(trips, t)
+	}
+	return calendarResponse(c, "My volunteer trips", trips)
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"github.com/Taiterbase/vtrips/apps/trips/internal/auth"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+)
+
+// The codes clients see for the models' errors. Errors not listed get their status's
+// code, such as not_found.
+func init() {
+	problem.Register("trip_not_found", models.ErrTripNotFound)
+	problem.Register("org_not_found", models.ErrOrgNotFound)
+	problem.Register("application_not_found", models.ErrApplicationNotFound)
+	problem.Register("schedule_not_found", models.ErrScheduleNotFound)
+	problem.Register("media_not_found", models.ErrMediaNotFound)
+	problem.Register("calendar_not_found", models.ErrCalendarNotFound)
+	problem.Register("already_applied", models.ErrAlreadyApplied)
+	problem.Register("trip_full", models.ErrTripFull)
+	problem.Register("trip_not_open", models.ErrTripNotOpen)
+	problem.Register("invalid_transition", models.ErrInvalidTransition)
+	problem.Register("too_many_media", models.ErrTooManyMedia)
+	problem.Register("media_too_large", models.ErrMediaTooLarge)
+	problem.Register("unsupported_media", models.ErrUnsupportedMedia)
+	problem.Register("no_exchange_rate", models.ErrNoExchangeRate)
+	problem.Register("not_schedule_instance", models.ErrNotScheduleInstance)
+	problem.Register("unauthorized", auth.ErrUnauthorized)
+	problem.Register(problem.CodeValidationFailed,
+		models.ErrInvalidPayload, models.ErrInvalidTripID, models.ErrInvalidOrgID,
+		models.ErrMissingAnswer, models.ErrInvalidCurrency, models.ErrInvalidRates,
+		models.ErrInvalidPrice, models.ErrInvalidBBox, models.ErrInvalidZoom,
+		models.ErrImportFormat, models.ErrImportTooLarge, models.ErrImportColumn,
+		models.ErrItineraryNoDates, models.ErrItineraryDayOutside, models.ErrItineraryDuplicateDay,
+		models.ErrMeetingPointLocation, models.ErrInvalidRRule, models.ErrScheduleNoDates,
+		models.ErrInvalidScope)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/RoaringBitmap/roaring/roaring64"
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+)
+
+const geoJSONType = "application/geo+json"
+
+// queryOptions are the GetTrips parameters that shape the response or set a range
+// rather than filter on an indexed field.
+var queryOptions = []string{"format", "bbox", "zoom", "min_price", "max_price"}
+
+// tripQueryFilters returns the query's field filters for queryTrips. Enum filters must
+// name values the enum has.
+func tripQueryFilters(query url.Values) (map[string][]string, error) {
+	// with a price range, currency is the range's currency rather than a filter
+	priced := query.Get("min_price") != "" || query.Get("max_price") != ""
+	filters := map[string][]string{}
+	for key, values := range query {
+		if slices.Contains(queryOptions, key) || (priced && key == "currency") {
+			continue
+		}
+		for _, v := range values {
+			if err := models.CheckFilter(key, v); err != nil {
+				return nil, err
+			}
+		}
+		filters[key] = values
+	}
+	return filters, nil
+}
+
+// geoQuery is the map view of a trip query: only trips inside bbox, clustered at zoom.
+type geoQuery struct {
+	bbox *models.BBox
+	// zoom is -1 when the caller wants every trip as its own point
+	zoom int
+}
+
+func parseGeoQuery(query url.Values) (geoQuery, error) {
+	q := geoQuery{zoom: -1}
+	if v := query.Get("bbox"); v != "" {
+		b, err := models.ParseBBox(v)
+		if err != nil {
+			return q, err
+		}
+		q.bbox = &b
+	}
+	if v := query.Get("zoom"); v != "" {
+		z, err := strconv.Atoi(v)
+		if err != nil || z < 0 || z > models.MaxZoom {
+			return q, models.ErrInvalidZoom
+		}
+		q.zoom = z
+	}
+	return q, nil
+}
+
+// narrow keeps the trips indexed inside the bounding box. The index cells are coarser
+// than the box, so matches still need contains.
+func (q geoQuery) narrow(matched *roaring64.Bitmap) (*roaring64.Bitmap, error) {
+	if q.bbox == nil {
+		return matched, nil
+	}
+	inBox := roaring64.New()
+	for _, tk := range q.bbox.Tokens() {
+		bm, err := storage.BitmapForToken(tk)
+		if err != nil {
+			return nil, err
+		}
+		inBox.Or(bm)
+	}
+	return roaring64.And(matched, inBox), nil
+}
+
+func (q geoQuery) contains(t *models.TripBase) bool {
+	return t.HasCoordinates() && (q.bbox == nil || q.bbox.Contains(t.Latitude, t.Longitude))
+}
+
+func geoJSONResponse(c echo.Context, fc models.FeatureCollection) error {
+	b, err := json.Marshal(fc)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.Blob(http.StatusOK, geoJSONType, b)
+}
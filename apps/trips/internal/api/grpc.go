@@ -0,0 +1,398 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/trips/internal/auth"
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/tripsv1"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+	"github.com/oklog/ulid/v2"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// errorDomain is the ErrorInfo domain of the gRPC API's errors.
+const errorDomain = "trips.vtrips"
+
+// tripsServer is the gRPC API. Each method runs the same trip operations as its REST
+// handler, so the two validate, fail and store alike.
+type tripsServer struct {
+	tripsv1.UnimplementedTripsServiceServer
+	e *echo.Echo
+}
+
+// serveGRPC serves the gRPC API on GRPC_PORT, 9090 by default.
+func serveGRPC(e *echo.Echo) {
+	port := os.Getenv("GRPC_PORT")
+	if port == "" {
+		port = "9090"
+	}
+	lis, err := net.Listen("tcp", "0.0.0.0:"+port)
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+	e.Logger.Fatal(newGRPCServer(e).Serve(lis))
+}
+
+func newGRPCServer(e *echo.Echo) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx, id := grpcRequestID(ctx)
+			grpc.SetHeader(ctx, metadata.Pairs("x-request-id", id))
+			start := time.Now()
+			resp, err := handler(ctx, req)
+			logGRPC(e, id, info.FullMethod, start, err)
+			return resp, err
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, id := grpcRequestID(ss.Context())
+			ss.SetHeader(metadata.Pairs("x-request-id", id))
+			start := time.Now()
+			err := handler(srv, &requestStream{ServerStream: ss, ctx: ctx})
+			logGRPC(e, id, info.FullMethod, start, err)
+			return err
+		}),
+	)
+	tripsv1.RegisterTripsServiceServer(srv, &tripsServer{e: e})
+	// reflection lists every method to anyone who asks, so only for local tools
+	if os.Getenv("GRPC_REFLECTION") == "true" {
+		reflection.Register(srv)
+	}
+	return srv
+}
+
+// grpcSession is auth.FromRequest for gRPC: the session JWT is sent as "authorization:
+// Bearer <token>" metadata.
+func grpcSession(ctx context.Context) (*auth.Session, error) {
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("authorization"); len(v) > 0 {
+			req.Header.Set("Authorization", v[0])
+		}
+	}
+	return auth.FromRequest(req)
+}
+
+type requestIDKey struct{}
+
+// grpcRequestID is requestID for gRPC: the caller's x-request-id metadata when it sent
+// one, or a new ID.
+func grpcRequestID(ctx context.Context) (context.Context, string) {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("x-request-id"); len(v) > 0 && len(v[0]) <= 128 {
+			id = v[0]
+		}
+	}
+	if id == "" {
+		id = ulid.Make().String()
+	}
+	return context.WithValue(ctx, requestIDKey{}, id), id
+}
+
+// requestStream is a stream whose context carries its request ID.
+type requestStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *requestStream) Context() context.Context {
+	return s.ctx
+}
+
+// logGRPC logs a finished call like the REST access log, with its gRPC code for status.
+func logGRPC(e *echo.Echo, id, method string, start time.Time, err error) {
+	fmt.Fprintf(e.Logger.Output(), "%s %s %s %s %s\n", start.Format(time.RFC3339), id, status.Code(err), method, time.Since(start))
+}
+
+// context gives the trip operations the echo.Context they expect, as expireOffers does,
+// with a request carrying the call's context, ID and session, so checkMember finds the
+// caller the way it does for REST.
+func (s *tripsServer) context(ctx context.Context) echo.Context {
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	req.Header.Set(echo.HeaderXRequestID, id)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("authorization"); len(v) > 0 {
+			req.Header.Set("Authorization", v[0])
+		}
+	}
+	return s.e.NewContext(req, nil)
+}
+
+// grpcCodes are the gRPC codes for the statuses the trip operations fail with.
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusRequestEntityTooLarge: codes.InvalidArgument,
+	http.StatusUnsupportedMediaType:  codes.InvalidArgument,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusInternalServerError:   codes.Internal,
+	http.StatusBadGateway:            codes.Unavailable,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+}
+
+func grpcCode(httpStatus int) codes.Code {
+	if code, ok := grpcCodes[httpStatus]; ok {
+		return code
+	}
+	return codes.Unknown
+}
+
+// grpcError is problem.JSON for gRPC: the status for failing with err at httpStatus,
+// with the problem's code as its ErrorInfo reason and its field errors as a BadRequest.
+func grpcError(c echo.Context, httpStatus int, err error) error {
+	p := problem.New(httpStatus, err)
+	p.RequestID = c.Request().Header.Get(echo.HeaderXRequestID)
+	if httpStatus >= 500 {
+		c.Logger().Errorf("request %s: %v", p.RequestID, err)
+	}
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   p.Code,
+		Domain:   errorDomain,
+		Metadata: map[string]string{"request_id": p.RequestID},
+	}}
+	if len(p.Errors) > 0 {
+		invalid := &errdetails.BadRequest{}
+		for _, f := range p.Errors {
+			invalid.FieldViolations = append(invalid.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: f.Message,
+				Reason:      f.Rule,
+			})
+		}
+		details = append(details, invalid)
+	}
+	st := status.New(grpcCode(httpStatus), p.Detail)
+	if withDetails, derr := st.WithDetails(details...); derr == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// tripMessage is trip as the gRPC API sends it: its REST JSON, read into a Trip, so the
+// two stay field for field alike.
+func tripMessage(trip models.Trip) (*tripsv1.Trip, error) {
+	b, err := json.Marshal(trip)
+	if err != nil {
+		return nil, err
+	}
+	msg := &tripsv1.Trip{}
+	return msg, protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, msg)
+}
+
+// mergeTrip applies msg's fields to trip exactly as REST binds a request body, by
+// writing them as JSON and reading that into trip. paths are the fields to apply, such
+// as an update mask's, or nil for every field msg sets.
+func mergeTrip(trip models.Trip, msg *tripsv1.Trip, paths []string) error {
+	m := msg.ProtoReflect()
+	patch := map[string]any{}
+	if paths == nil {
+		m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+			patch[string(fd.Name())] = jsonValue(fd, v)
+			return true
+		})
+	}
+	for _, path := range paths {
+		fd := m.Descriptor().Fields().ByName(protoreflect.Name(path))
+		if fd == nil {
+			return problem.Invalid(fmt.Errorf("update_mask names %s, which trips don't have", path),
+				problem.FieldError{Field: "update_mask", Rule: "field", Message: "trips have no " + path})
+		}
+		if fd.HasPresence() && !m.Has(fd) {
+			patch[path] = nil
+			continue
+		}
+		patch[path] = jsonValue(fd, m.Get(fd))
+	}
+	b, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, trip)
+}
+
+// jsonValue is a field's value as encoding/json writes it; unlike protojson, 64-bit
+// integers stay numbers.
+func jsonValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	if !fd.IsList() {
+		return jsonScalar(fd, v)
+	}
+	list := v.List()
+	values := make([]any, list.Len())
+	for i := range values {
+		values[i] = jsonScalar(fd, list.Get(i))
+	}
+	return values
+}
+
+func jsonScalar(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	if fd.Kind() != protoreflect.MessageKind {
+		return v.Interface()
+	}
+	fields := map[string]any{}
+	v.Message().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		fields[string(fd.Name())] = jsonValue(fd, v)
+		return true
+	})
+	return fields
+}
+
+func (s *tripsServer) CreateTrip(ctx context.Context, req *tripsv1.CreateTripRequest) (*tripsv1.Trip, error) {
+	c := s.context(ctx)
+	trip := models.NewTrip()
+	if err := mergeTrip(trip, req.GetTrip(), nil); err != nil {
+		return nil, grpcError(c, http.StatusBadRequest, err)
+	}
+	if status, err := createTrip(c, trip); err != nil {
+		return nil, grpcError(c, status, err)
+	}
+	return s.reply(c, trip)
+}
+
+func (s *tripsServer) GetTrip(ctx context.Context, req *tripsv1.GetTripRequest) (*tripsv1.Trip, error) {
+	c := s.context(ctx)
+	trip, status, err := findTrip(c, req.GetOrgId(), req.GetTripId())
+	if err != nil {
+		return nil, grpcError(c, status, err)
+	}
+	return s.reply(c, trip)
+}
+
+func (s *tripsServer) UpdateTrip(ctx context.Context, req *tripsv1.UpdateTripRequest) (*tripsv1.Trip, error) {
+	c := s.context(ctx)
+	trip, status, err := orgTrip(c, req.GetOrgId(), req.GetTripId())
+	if err != nil {
+		return nil, grpcError(c, status, err)
+	}
+	if err = mergeTrip(trip, req.GetTrip(), req.GetUpdateMask().GetPaths()); err != nil {
+		return nil, grpcError(c, http.StatusBadRequest, err)
+	}
+	if status, err = updateTrip(c, trip); err != nil {
+		return nil, grpcError(c, status, err)
+	}
+	return s.reply(c, trip)
+}
+
+func (s *tripsServer) DeleteTrip(ctx context.Context, req *tripsv1.DeleteTripRequest) (*tripsv1.DeleteTripResponse, error) {
+	c := s.context(ctx)
+	trip, status, err := orgTrip(c, req.GetOrgId(), req.GetTripId())
+	if err != nil {
+		return nil, grpcError(c, status, err)
+	}
+	if status, err = deleteTrip(c, trip); err != nil {
+		return nil, grpcError(c, status, err)
+	}
+	return &tripsv1.DeleteTripResponse{TripId: trip.GetID()}, nil
+}
+
+func (s *tripsServer) QueryTrips(ctx context.Context, req *tripsv1.QueryTripsRequest) (*tripsv1.QueryTripsResponse, error) {
+	c := s.context(ctx)
+	query := url.Values{}
+	for field, values := range req.GetFilters() {
+		query[field] = values.GetValues()
+	}
+	for param, v := range map[string]string{
+		"min_price": req.GetMinPrice(),
+		"max_price": req.GetMaxPrice(),
+		"currency":  req.GetCurrency(),
+		"bbox":      req.GetBbox(),
+	} {
+		if v != "" {
+			query.Set(param, v)
+		}
+	}
+	found, status, err := findTrips(c, query)
+	if err != nil {
+		return nil, grpcError(c, status, err)
+	}
+	resp := &tripsv1.QueryTripsResponse{Count: int64(len(found.trips)), ScannedCount: int64(found.scanned)}
+	for _, trip := range found.trips {
+		msg, err := tripMessage(trip)
+		if err != nil {
+			return nil, grpcError(c, http.StatusInternalServerError, err)
+		}
+		resp.Trips = append(resp.Trips, msg)
+	}
+	return resp, nil
+}
+
+// eventKinds are the gRPC API's names for storage's trip events.
+var eventKinds = map[storage.TripEventKind]tripsv1.TripEvent_Kind{
+	storage.TripCreated: tripsv1.TripEvent_KIND_CREATED,
+	storage.TripUpdated: tripsv1.TripEvent_KIND_UPDATED,
+	storage.TripDeleted: tripsv1.TripEvent_KIND_DELETED,
+}
+
+func (s *tripsServer) WatchTrips(req *tripsv1.WatchTripsRequest, stream grpc.ServerStreamingServer[tripsv1.TripEvent]) error {
+	c := s.context(stream.Context())
+	// events carry drafts, so only an org's members may watch, and only its trips
+	sess, err := grpcSession(stream.Context())
+	if err != nil {
+		return grpcError(c, auth.Status(err), err)
+	}
+	orgID := req.GetOrgId()
+	if orgID == "" {
+		orgID = sess.OrgID
+	}
+	if orgID == "" || orgID != sess.OrgID {
+		return grpcError(c, http.StatusForbidden, errors.New("forbidden"))
+	}
+	events, stop := storage.WatchTrips()
+	defer stop()
+	// headers go out once the watch is subscribed, so callers can wait for them before
+	// changing trips
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case ev, ok := <-events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "fell behind the trip events; watch again")
+			}
+			if ev.Trip.GetOrgID() != orgID {
+				continue
+			}
+			msg, err := tripMessage(ev.Trip)
+			if err != nil {
+				return grpcError(c, http.StatusInternalServerError, err)
+			}
+			if err = stream.Send(&tripsv1.TripEvent{Kind: eventKinds[ev.Kind], Trip: msg}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// reply sends trip, failing like REST would if it couldn't be written.
+func (s *tripsServer) reply(c echo.Context, trip models.Trip) (*tripsv1.Trip, error) {
+	msg, err := tripMessage(trip)
+	if err != nil {
+		return nil, grpcError(c, http.StatusInternalServerError, err)
+	}
+	return msg, nil
+}
@@ -0,0 +1,442 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/tripsv1"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// checkOrg owns the trips the test makes.
+const checkOrg = "check-org"
+
+// TestGRPCMatchesREST runs the trip operations through REST and gRPC, over an in-memory
+// connection to a scratch database, and reports every way their answers differ:
+// statuses, problem codes, field errors, stored trips and query results. It also checks
+// that WatchTrips sees the changes.
+func TestGRPCMatchesREST(t *testing.T) {
+	ck := newGRPCCheck(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	ck.run(ctx)
+}
+
+// TestWatchTripsNeedsOrgSession checks WatchTrips only streams to members of the org.
+func TestWatchTripsNeedsOrgSession(t *testing.T) {
+	ck := newGRPCCheck(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, tc := range []struct {
+		name  string
+		token string
+		orgID string
+		want  codes.Code
+	}{
+		{"without a session", "", checkOrg, codes.Unauthenticated},
+		{"with a volunteer's session", sessionToken(t, "volunteer", ""), checkOrg, codes.PermissionDenied},
+		{"for another org", sessionToken(t, "member", "another-org"), checkOrg, codes.PermissionDenied},
+		{"with an action token", actionToken(t, "member", checkOrg), checkOrg, codes.Unauthenticated},
+	} {
+		callCtx := ctx
+		if tc.token != "" {
+			callCtx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tc.token)
+		}
+		stream, err := ck.client.WatchTrips(callCtx, &tripsv1.WatchTripsRequest{OrgId: tc.orgID})
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		if got := status.Code(err); got != tc.want {
+			t.Errorf("WatchTrips %s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+// newGRPCCheck serves the REST routes and the gRPC API from a scratch database.
+func newGRPCCheck(t *testing.T) *grpcCheck {
+	t.Helper()
+	withUsers(t)
+	if err := storage.Open(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.Client.Close() })
+
+	e := echo.New()
+	e.Logger.SetOutput(io.Discard)
+	e.HTTPErrorHandler = problem.HTTPErrorHandler
+	e.Use(requestID)
+	setupRouters(e)
+
+	lis := bufconn.Listen(1 << 20)
+	srv := newGRPCServer(e)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &grpcCheck{t: t, e: e, client: tripsv1.NewTripsServiceClient(conn)}
+}
+
+// withUsers signs tokens with a test secret and stands in for the users service, which
+// reports the sessions of the revoked users as revoked.
+func withUsers(t *testing.T, revoked ...string) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret")
+	users := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("auth_token")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		claims := jwt.MapClaims{}
+		new(jwt.Parser).ParseUnverified(cookie.Value, claims)
+		if sub, _ := claims["sub"].(string); slices.Contains(revoked, sub) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(users.Close)
+	t.Setenv("USERS_BASE_URL", users.URL)
+}
+
+// sessionToken signs a users-service session for userID in orgID.
+func sessionToken(t *testing.T, userID, orgID string) string {
+	return signToken(t, jwt.MapClaims{"sub": userID, "username": userID, "tenant": orgID, "exp": time.Now().Add(time.Hour).Unix()})
+}
+
+// actionToken signs a single-use users-service token, which is never a session.
+func actionToken(t *testing.T, userID, orgID string) string {
+	return signToken(t, jwt.MapClaims{"sub": userID, "tenant": orgID, "purpose": "login_totp"})
+}
+
+func signToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(os.Getenv("JWT_SECRET")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+type grpcCheck struct {
+	t      *testing.T
+	e      *echo.Echo
+	client tripsv1.TripsServiceClient
+	events chan *tripsv1.TripEvent
+}
+
+// answer is how a call ended, in the terms both APIs share.
+type answer struct {
+	code codes.Code
+	// reason is the problem code, such as trip_not_found
+	reason string
+	// fields are the field errors, as "field rule"
+	fields []string
+}
+
+func (a answer) String() string {
+	if a.code == codes.OK {
+		return "OK"
+	}
+	return fmt.Sprintf("%s %s %v", a.code, a.reason, a.fields)
+}
+
+func restAnswer(rec *httptest.ResponseRecorder) answer {
+	if rec.Code < 400 {
+		return answer{code: codes.OK}
+	}
+	var p problem.Problem
+	json.Unmarshal(rec.Body.Bytes(), &p)
+	a := answer{code: grpcCode(rec.Code), reason: p.Code}
+	for _, f := range p.Errors {
+		a.fields = append(a.fields, f.Field+" "+f.Rule)
+	}
+	slices.Sort(a.fields)
+	return a
+}
+
+func grpcAnswer(err error) answer {
+	st := status.Convert(err)
+	a := answer{code: st.Code()}
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			a.reason = d.Reason
+		case *errdetails.BadRequest:
+			for _, v := range d.FieldViolations {
+				a.fields = append(a.fields, v.Field+" "+v.Reason)
+			}
+		}
+	}
+	slices.Sort(a.fields)
+	return a
+}
+
+func (ck *grpcCheck) fail(format string, args ...any) {
+	ck.t.Helper()
+	ck.t.Errorf(format, args...)
+}
+
+// rest calls the REST API as a member of checkOrg.
+func (ck *grpcCheck) rest(method, target, body string) *httptest.ResponseRecorder {
+	return ck.restAs(sessionToken(ck.t, "member", checkOrg), method, target, body)
+}
+
+// restAs calls the REST API with token as the session, or without one when it's empty.
+func (ck *grpcCheck) restAs(token, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	ck.e.ServeHTTP(rec, req)
+	return rec
+}
+
+// same reports a difference between the two answers to one operation.
+func (ck *grpcCheck) same(op string, rest *httptest.ResponseRecorder, err error) bool {
+	r, g := restAnswer(rest), grpcAnswer(err)
+	if r.code != g.code || r.reason != g.reason || !slices.Equal(r.fields, g.fields) {
+		ck.fail("%s: REST answers %s, gRPC %s", op, r, g)
+		return false
+	}
+	return true
+}
+
+// sameTrip reports a difference between the trip REST sent and the one gRPC did. With
+// identity false, their IDs and times may differ, as for two trips made alike.
+func (ck *grpcCheck) sameTrip(op string, rest []byte, msg *tripsv1.Trip, identity bool) {
+	fromREST := &tripsv1.Trip{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(rest, fromREST); err != nil {
+		ck.fail("%s: REST sent a trip that doesn't read as one: %v", op, err)
+		return
+	}
+	fromGRPC := proto.Clone(msg).(*tripsv1.Trip)
+	if !identity {
+		for _, t := range []*tripsv1.Trip{fromREST, fromGRPC} {
+			t.Id, t.CreatedAt, t.UpdatedAt = "", 0, 0
+		}
+	}
+	if !proto.Equal(fromREST, fromGRPC) {
+		ck.fail("%s: REST sent %s\n\t\tgRPC sent %s", op, protojson.Format(fromREST), protojson.Format(fromGRPC))
+	}
+}
+
+// watch collects WatchTrips events for checkOrg into ck.events.
+func (ck *grpcCheck) watch(ctx context.Context) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+sessionToken(ck.t, "member", checkOrg))
+	stream, err := ck.client.WatchTrips(ctx, &tripsv1.WatchTripsRequest{OrgId: checkOrg})
+	if err != nil {
+		return err
+	}
+	// the header comes once the watch is subscribed
+	if _, err = stream.Header(); err != nil {
+		return err
+	}
+	ck.events = make(chan *tripsv1.TripEvent, 16)
+	go func() {
+		for {
+			ev, err := stream.Recv()
+			if err != nil {
+				close(ck.events)
+				return
+			}
+			ck.events <- ev
+		}
+	}()
+	return nil
+}
+
+// expectEvents reports unless the next events are kind, for the trips ids, in any order.
+func (ck *grpcCheck) expectEvents(op string, kind tripsv1.TripEvent_Kind, ids ...string) {
+	var got []string
+	timeout := time.After(5 * time.Second)
+	for range ids {
+		select {
+		case ev, ok := <-ck.events:
+			if !ok {
+				ck.fail("%s: WatchTrips ended early", op)
+				return
+			}
+			if ev.GetKind() != kind {
+				ck.fail("%s: WatchTrips sent %s, not %s", op, ev.GetKind(), kind)
+			}
+			got = append(got, ev.GetTrip().GetId())
+		case <-timeout:
+			ck.fail("%s: WatchTrips sent %d of %d events", op, len(got), len(ids))
+			return
+		}
+	}
+	slices.Sort(got)
+	ids = slices.Sorted(slices.Values(ids))
+	if !slices.Equal(got, ids) {
+		ck.fail("%s: WatchTrips sent events for %v, not %v", op, got, ids)
+	}
+}
+
+func (ck *grpcCheck) run(ctx context.Context) {
+	if err := ck.watch(ctx); err != nil {
+		ck.t.Fatalf("WatchTrips: %v", err)
+	}
+	anonymous := ctx
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+sessionToken(ck.t, "member", checkOrg))
+
+	// the same trip, made through each API
+	rec := ck.rest(http.MethodPost, "/v1/trips", `{"org_id": "check-org", "name": "Clinic build",
+		"trip_type": "international", "housing_type": "hotel", "volunteer_limit": 10, "price": 44999,
+		"currency": "USD", "city": "Quito", "country": "EC", "latitude": -0.18, "longitude": -78.47,
+		"questions": [{"id": "why", "prompt": "Why this trip?", "required": true}]}`)
+	made, err := ck.client.CreateTrip(ctx, &tripsv1.CreateTripRequest{Trip: &tripsv1.Trip{
+		OrgId: checkOrg, Name: "Clinic build",
+		TripType: "international", HousingType: "hotel", VolunteerLimit: 10, Price: 44999,
+		Currency: "USD", City: "Quito", Country: "EC", Latitude: -0.18, Longitude: -78.47,
+		Questions: []*tripsv1.Question{{Id: "why", Prompt: "Why this trip?", Required: true}},
+	}})
+	if !ck.same("CreateTrip", rec, err) || err != nil {
+		ck.t.FailNow()
+	}
+	ck.sameTrip("CreateTrip", rec.Body.Bytes(), made, false)
+	var restMade struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &restMade)
+	restID, grpcID := restMade.ID, made.GetId()
+	ck.expectEvents("CreateTrip", tripsv1.TripEvent_KIND_CREATED, restID, grpcID)
+
+	rec = ck.rest(http.MethodPost, "/v1/trips", `{"org_id": "check-org", "trip_type": "bogus"}`)
+	_, err = ck.client.CreateTrip(ctx, &tripsv1.CreateTripRequest{Trip: &tripsv1.Trip{OrgId: checkOrg, TripType: "bogus"}})
+	ck.same("CreateTrip with an unknown trip_type", rec, err)
+	rec = ck.rest(http.MethodPost, "/v1/trips", `{"name": "No org", "price": -1}`)
+	_, err = ck.client.CreateTrip(ctx, &tripsv1.CreateTripRequest{Trip: &tripsv1.Trip{Name: "No org", Price: -1}})
+	ck.same("CreateTrip without org_id", rec, err)
+
+	rec = ck.rest(http.MethodGet, "/v1/trips/"+restID+"?org_id="+checkOrg, "")
+	got, err := ck.client.GetTrip(ctx, &tripsv1.GetTripRequest{OrgId: checkOrg, TripId: restID})
+	if ck.same("GetTrip", rec, err) && err == nil {
+		ck.sameTrip("GetTrip", rec.Body.Bytes(), got, true)
+	}
+	rec = ck.rest(http.MethodGet, "/v1/trips/"+restID+"?org_id=another-org", "")
+	_, err = ck.client.GetTrip(ctx, &tripsv1.GetTripRequest{OrgId: "another-org", TripId: restID})
+	ck.same("GetTrip of another org's trip", rec, err)
+	rec = ck.rest(http.MethodGet, "/v1/trips/"+restID, "")
+	_, err = ck.client.GetTrip(ctx, &tripsv1.GetTripRequest{TripId: restID})
+	ck.same("GetTrip without org_id", rec, err)
+
+	rec = ck.rest(http.MethodPut, "/v1/trips/"+restID+"?org_id="+checkOrg, `{"name": "Clinic rebuild", "volunteer_limit": 12, "questions": []}`)
+	_, err = ck.client.UpdateTrip(ctx, &tripsv1.UpdateTripRequest{
+		OrgId: checkOrg, TripId: grpcID,
+		Trip:       &tripsv1.Trip{Name: "Clinic rebuild", VolunteerLimit: 12},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name", "volunteer_limit", "questions"}},
+	})
+	if ck.same("UpdateTrip", rec, err) && err == nil {
+		ck.expectEvents("UpdateTrip", tripsv1.TripEvent_KIND_UPDATED, restID, grpcID)
+		rec = ck.rest(http.MethodGet, "/v1/trips/"+restID+"?org_id="+checkOrg, "")
+		got, err = ck.client.GetTrip(ctx, &tripsv1.GetTripRequest{OrgId: checkOrg, TripId: grpcID})
+		if ck.same("GetTrip after UpdateTrip", rec, err) && err == nil {
+			ck.sameTrip("UpdateTrip", rec.Body.Bytes(), got, false)
+		}
+	}
+	// org_id only names the org; the session has to belong to it
+	outsider := sessionToken(ck.t, "outsider", "another-org")
+	outsiderCtx := metadata.AppendToOutgoingContext(anonymous, "authorization", "Bearer "+outsider)
+	rec = ck.restAs(outsider, http.MethodPut, "/v1/trips/"+restID+"?org_id="+checkOrg, `{"name": "Taken over"}`)
+	_, err = ck.client.UpdateTrip(outsiderCtx, &tripsv1.UpdateTripRequest{
+		OrgId: checkOrg, TripId: grpcID,
+		Trip:       &tripsv1.Trip{Name: "Taken over"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+	if ck.same("UpdateTrip by another org's member", rec, err) && status.Code(err) != codes.PermissionDenied {
+		ck.fail("UpdateTrip by another org's member: got %s, want PermissionDenied", status.Code(err))
+	}
+	rec = ck.restAs("", http.MethodDelete, "/v1/trips/"+restID+"?org_id="+checkOrg, "")
+	_, err = ck.client.DeleteTrip(anonymous, &tripsv1.DeleteTripRequest{OrgId: checkOrg, TripId: grpcID})
+	if ck.same("DeleteTrip without a session", rec, err) && status.Code(err) != codes.Unauthenticated {
+		ck.fail("DeleteTrip without a session: got %s, want Unauthenticated", status.Code(err))
+	}
+	rec = ck.restAs(outsider, http.MethodPost, "/v1/trips", `{"org_id": "check-org", "name": "Planted"}`)
+	_, err = ck.client.CreateTrip(outsiderCtx, &tripsv1.CreateTripRequest{Trip: &tripsv1.Trip{OrgId: checkOrg, Name: "Planted"}})
+	if ck.same("CreateTrip for another org", rec, err) && status.Code(err) != codes.PermissionDenied {
+		ck.fail("CreateTrip for another org: got %s, want PermissionDenied", status.Code(err))
+	}
+
+	rec = ck.rest(http.MethodPut, "/v1/trips/"+restID+"?org_id="+checkOrg, `{"status": "bogus"}`)
+	_, err = ck.client.UpdateTrip(ctx, &tripsv1.UpdateTripRequest{
+		OrgId: checkOrg, TripId: grpcID,
+		Trip:       &tripsv1.Trip{Status: "bogus"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"status"}},
+	})
+	ck.same("UpdateTrip with an unknown status", rec, err)
+
+	rec = ck.rest(http.MethodGet, "/v1/trips?org_id="+checkOrg+"&trip_type=international&max_price=500", "")
+	found, err := ck.client.QueryTrips(ctx, &tripsv1.QueryTripsRequest{
+		Filters: map[string]*tripsv1.FilterValues{
+			"org_id":    {Values: []string{checkOrg}},
+			"trip_type": {Values: []string{"international"}},
+		},
+		MaxPrice: "500",
+	})
+	if ck.same("QueryTrips", rec, err) && err == nil {
+		var list struct {
+			Trips []struct {
+				ID string `json:"id"`
+			} `json:"trips"`
+			ScannedCount int64 `json:"scanned_count"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &list)
+		var restIDs, grpcIDs []string
+		for _, t := range list.Trips {
+			restIDs = append(restIDs, t.ID)
+		}
+		for _, t := range found.GetTrips() {
+			grpcIDs = append(grpcIDs, t.GetId())
+		}
+		slices.Sort(restIDs)
+		slices.Sort(grpcIDs)
+		if !slices.Equal(restIDs, grpcIDs) || list.ScannedCount != found.GetScannedCount() {
+			ck.fail("QueryTrips: REST found %v scanning %d, gRPC %v scanning %d", restIDs, list.ScannedCount, grpcIDs, found.GetScannedCount())
+		}
+	}
+	rec = ck.rest(http.MethodGet, "/v1/trips?trip_type=bogus", "")
+	_, err = ck.client.QueryTrips(ctx, &tripsv1.QueryTripsRequest{
+		Filters: map[string]*tripsv1.FilterValues{"trip_type": {Values: []string{"bogus"}}},
+	})
+	ck.same("QueryTrips with an unknown trip_type", rec, err)
+
+	rec = ck.rest(http.MethodDelete, "/v1/trips/"+restID+"?org_id="+checkOrg, "")
+	_, err = ck.client.DeleteTrip(ctx, &tripsv1.DeleteTripRequest{OrgId: checkOrg, TripId: grpcID})
+	if ck.same("DeleteTrip", rec, err) && err == nil {
+		ck.expectEvents("DeleteTrip", tripsv1.TripEvent_KIND_DELETED, restID, grpcID)
+	}
+	rec = ck.rest(http.MethodDelete, "/v1/trips/"+restID+"?org_id="+checkOrg, "")
+	_, err = ck.client.DeleteTrip(ctx, &tripsv1.DeleteTripRequest{OrgId: checkOrg, TripId: grpcID})
+	ck.same("DeleteTrip of a deleted trip", rec, err)
+}
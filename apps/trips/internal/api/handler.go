@@ -2,12 +2,17 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/RoaringBitmap/roaring/roaring64"
 	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
 	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
 	"github.com/cockroachdb/pebble"
 	"github.com/labstack/echo"
 	"github.com/labstack/gommon/log"
@@ -38,25 +43,58 @@ func DatabaseDebug(c echo.Context) error {
 	})
 }
 
+// badBind answers a request whose body Bind rejected, naming the field that was wrong
+// when it can. An unknown enum name gets the enum's own message, which lists the names
+// allowed.
+func badBind(c echo.Context, err error) error {
+	status := http.StatusBadRequest
+	var (
+		httpErr *echo.HTTPError
+		typeErr *json.UnmarshalTypeError
+	)
+	if errors.As(err, &httpErr) {
+		status, err = httpErr.Code, fmt.Errorf("%v", httpErr.Message)
+		if httpErr.Internal != nil {
+			err = httpErr.Internal
+		}
+	}
+	if errors.As(err, &typeErr) {
+		field := problem.FieldError{Field: typeErr.Field, Rule: "type", Message: "must be " + models.JSONKind(typeErr.Type)}
+		err = problem.Invalid(fmt.Errorf("%s must be %s", typeErr.Field, models.JSONKind(typeErr.Type)), field)
+	}
+	return problem.JSON(c, status, err)
+}
+
 func CreateTrip(c echo.Context) error {
 	trip := models.NewTrip()
 	err := c.Bind(&trip)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return badBind(c, err)
 	}
+	if status, err := createTrip(c, trip); err != nil {
+		return problem.JSON(c, status, err)
+	}
+	return c.JSON(http.StatusOK, trip)
+}
 
-	if err = trip.Validate(); err != nil {
+// createTrip stores a new trip once it is valid, returning the status to fail with.
+// Like the other trip operations here, it is shared by REST and gRPC so both answer
+// alike.
+func createTrip(c echo.Context, trip models.Trip) (int, error) {
+	if status, err := checkMember(c, trip.GetOrgID()); err != nil {
+		return status, err
+	}
+	if err := trip.Validate(); err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return http.StatusBadRequest, err
 	}
-
-	err = storage.CreateTrip(c, trip)
-	if err != nil {
+	trip.SetSeatsTaken(0)
+	trip.SetSequence(0)
+	if err := storage.CreateTrip(c, trip); err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return http.StatusInternalServerError, err
 	}
-
-	return c.JSON(http.StatusOK, trip)
+	return http.StatusOK, nil
 }
 
 func getTrip(c echo.Context, orgID, tripID string) (models.Trip, error) {
@@ -80,32 +118,79 @@ func getTrip(c echo.Context, orgID, tripID string) (models.Trip, error) {
 	return storage.ReadTrip(c, tripID)
 }
 
+// orgOrPublicTrip loads the trip a sub-resource request is about, returning the status
+// to fail with. Org callers name their org with ?org_id= like the other trip writes and
+// must be its members; anonymous readers only see listed trips.
+func orgOrPublicTrip(c echo.Context, tripID string, requireOrg bool) (models.Trip, int, error) {
+	orgID := c.QueryParam("org_id")
+	if orgID != "" || requireOrg {
+		return orgTrip(c, orgID, tripID)
+	}
+	trip, err := storage.ReadTrip(c, tripID)
+	if err == nil && (trip.GetStatus() != models.TripStatusListed || trip.GetDeletedAt() != 0) {
+		err = models.ErrTripNotFound
+	}
+	switch err {
+	case nil:
+		return trip, http.StatusOK, nil
+	case models.ErrTripNotFound:
+		return nil, http.StatusNotFound, err
+	default:
+		return nil, http.StatusInternalServerError, err
+	}
+}
+
+// GetTrip returns one of an org's trips. A ".ics" suffix on the ID returns the trip as
+// an iCalendar file instead; see TripCalendar.
 func GetTrip(c echo.Context) error {
 	orgID := c.QueryParam("org_id")
 	tripID := c.Param("trip_id")
+	if id, ok := strings.CutSuffix(tripID, ".ics"); ok {
+		return TripCalendar(c, id)
+	}
+	trip, status, err := findTrip(c, orgID, tripID)
+	if err != nil {
+		return problem.JSON(c, status, err)
+	}
+	return c.JSON(http.StatusOK, trip)
+}
+
+// findTrip is GetTrip's lookup: both IDs are required.
+func findTrip(c echo.Context, orgID, tripID string) (models.Trip, int, error) {
 	if orgID == "" || tripID == "" {
-		return c.JSON(http.StatusBadRequest, models.ErrInvalidTripID)
+		return nil, http.StatusBadRequest, models.ErrInvalidTripID
+	}
+	return orgTrip(c, orgID, tripID)
+}
+
+// orgTrip loads one of an org's trips for a member of the org.
+func orgTrip(c echo.Context, orgID, tripID string) (models.Trip, int, error) {
+	if status, err := checkMember(c, orgID); err != nil {
+		return nil, status, err
 	}
 	trip, err := getTrip(c, orgID, tripID)
 	switch err {
 	case nil:
-		return c.JSON(http.StatusOK, trip)
+		return trip, http.StatusOK, nil
 	case models.ErrTripNotFound:
-		return c.JSON(http.StatusNotFound, err.Error())
+		return nil, http.StatusNotFound, err
 	default:
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return nil, http.StatusInternalServerError, err
 	}
 }
 
-func GetTrips(c echo.Context) error {
+// queryTrips intersects the posting lists for each filter field, taking the union of
+// repeated values of the same field, and any range postings already read. It also
+// returns how many postings were scanned.
+func queryTrips(filters map[string][]string, ranges ...*roaring64.Bitmap) (*roaring64.Bitmap, int, error) {
 	scannedCount := 0
 	bitmapMap := make(map[string]*roaring64.Bitmap)
-	for key, vals := range c.QueryParams() {
+	for key, vals := range filters {
 		for _, v := range vals {
 			tk := models.MakeKey(key, v)
 			bm, err := storage.BitmapForToken(tk)
 			if err != nil {
-				return c.JSON(http.StatusInternalServerError, err.Error())
+				return nil, 0, err
 			}
 			if orBm, ok := bitmapMap[key]; !ok {
 				bitmapMap[key] = bm
@@ -121,80 +206,133 @@ func GetTrips(c echo.Context) error {
 		scannedCount += int(bm.GetCardinality())
 		bms = append(bms, bm)
 	}
-	intersection := roaring64.FastAnd(bms...)
-	if intersection.IsEmpty() {
-		return c.JSON(http.StatusOK, []models.Trip{})
+	for _, bm := range ranges {
+		scannedCount += int(bm.GetCardinality())
+		bms = append(bms, bm)
+	}
+	return roaring64.FastAnd(bms...), scannedCount, nil
+}
+
+// GetTrips lists the trips matching every field filter in the query. ?min_price= and
+// ?max_price= limit prices, in major units of ?currency= (USD by default), converting
+// trips priced in other currencies. ?format=geojson returns the ones with coordinates
+// as a GeoJSON FeatureCollection instead, optionally limited to
+// ?bbox=west,south,east,north and clustered for ?zoom=.
+func GetTrips(c echo.Context) error {
+	found, status, err := findTrips(c, c.QueryParams())
+	if err != nil {
+		return problem.JSON(c, status, err)
+	}
+	if c.QueryParam("format") == "geojson" {
+		return geoJSONResponse(c, models.NewFeatureCollection(found.mapped, found.geo.zoom))
+	}
+	return c.JSON(http.StatusOK, log.JSON{
+		"trips":         found.trips,
+		"count":         len(found.trips),
+		"scanned_count": found.scanned,
+	})
+}
+
+// foundTrips are the trips a query matched.
+type foundTrips struct {
+	trips []models.Trip
+	// mapped are the trips inside the query's bbox, for GeoJSON
+	mapped  []*models.TripBase
+	scanned int
+	geo     geoQuery
+}
+
+// findTrips runs a GetTrips query.
+func findTrips(c echo.Context, query url.Values) (*foundTrips, int, error) {
+	geo, err := parseGeoQuery(query)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	price, err := parsePriceQuery(query, "min_price", "max_price")
+	if err != nil {
+		return nil, queryErrorStatus(err), err
+	}
+	ranges, err := price.ranges()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	filters, err := tripQueryFilters(query)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	intersection, scannedCount, err := queryTrips(filters, ranges...)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if intersection, err = geo.narrow(intersection); err != nil {
+		return nil, http.StatusInternalServerError, err
 	}
 	c.Logger().Debugj(log.JSON{"message": "we have an intersection", "intersection": intersection})
 
-	var trips []models.Trip
-	it := intersection.Iterator()
-	for it.HasNext() {
-		numID := it.Next()
-		ulid, ok, err := storage.Reverse(storage.Client, numID)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, err.Error())
-		}
-		if !ok {
+	found := &foundTrips{trips: []models.Trip{}, scanned: scannedCount, geo: geo}
+	matched, err := storage.ReadIndexedTrips(c, intersection.ToArray())
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	for _, t := range matched {
+		trip := t.(*models.TripBase)
+		if !price.contains(trip) {
 			continue
-		} // should not happen
-		t, err := storage.ReadTrip(c, ulid)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, err.Error())
 		}
-		trips = append(trips, t)
+		if geo.contains(trip) {
+			found.mapped = append(found.mapped, trip)
+		}
+		found.trips = append(found.trips, t)
 	}
-	return c.JSON(http.StatusOK, log.JSON{
-		"trips":         trips,
-		"count":         len(trips),
-		"scanned_count": scannedCount,
-	})
+	return found, http.StatusOK, nil
 }
 
 func UpdateTrip(c echo.Context) error {
-	var (
-		tripID = c.Param("trip_id")
-		orgID  = c.QueryParam("org_id")
-	)
-	trip, err := getTrip(c, orgID, tripID)
-	switch err {
-	case nil:
-		break
-	case models.ErrTripNotFound:
-		return c.JSON(http.StatusNotFound, err.Error())
-	default:
-		return c.JSON(http.StatusInternalServerError, err.Error())
+	trip, status, err := orgTrip(c, c.QueryParam("org_id"), c.Param("trip_id"))
+	if err != nil {
+		return problem.JSON(c, status, err)
 	}
 	if err = c.Bind(&trip); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return badBind(c, err)
+	}
+	if status, err = updateTrip(c, trip); err != nil {
+		return problem.JSON(c, status, err)
+	}
+	return c.JSON(http.StatusOK, nil)
+}
+
+// updateTrip stores a trip its caller changed, once it is still valid.
+func updateTrip(c echo.Context, trip models.Trip) (int, error) {
+	if err := trip.Validate(); err != nil {
+		return http.StatusBadRequest, err
 	}
 	trip.SetUpdatedAt(time.Now().Unix())
-	err = storage.UpdateTrip(c, trip)
-	if err != nil {
+	if err := storage.UpdateTrip(c, trip); err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return http.StatusInternalServerError, err
 	}
-
-	return c.JSON(http.StatusOK, nil)
+	return http.StatusOK, nil
 }
 
 func DeleteTrip(c echo.Context) error {
-	var (
-		orgID  = c.QueryParam("org_id")
-		tripID = c.Param("trip_id")
-	)
-	trip, err := getTrip(c, orgID, tripID)
-	switch err {
-	case models.ErrTripNotFound:
-		return c.JSON(http.StatusNotFound, err.Error())
-	case nil:
-		break
-	default:
-		return c.JSON(http.StatusInternalServerError, err.Error())
-	}
-	err = storage.DeleteTrip(c, trip)
+	tripID := c.Param("trip_id")
+	trip, status, err := orgTrip(c, c.QueryParam("org_id"), tripID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return problem.JSON(c, status, err)
+	}
+	if status, err = deleteTrip(c, trip); err != nil {
+		return problem.JSON(c, status, err)
 	}
 	return c.JSON(http.StatusOK, tripID)
 }
+
+// deleteTrip removes a trip and its photos.
+func deleteTrip(c echo.Context, trip models.Trip) (int, error) {
+	if err := storage.DeleteTrip(c, trip); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if err := deleteTripMedia(c, trip.GetID()); err != nil {
+		c.Logger().Error(err)
+	}
+	return http.StatusOK, nil
+}
@@ -0,0 +1,493 @@
+package api
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	maxImportBytes = 16 << 20
+	previewRows    = 5
+	// exportFlushRows is how often an export pushes what it has written to the client
+	exportFlushRows = 100
+)
+
+var transferTypes = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"ndjson": "application/x-ndjson",
+}
+
+// importRow is one trip of an import file as JSON trip fields, keyed by column.
+type importRow struct {
+	row    int
+	fields map[string]any
+	// unreadable rows had a cell that couldn't be read; they're reported but not planned
+	unreadable bool
+}
+
+// importFile is an import read up to the point of touching any trips.
+type importFile struct {
+	format   string
+	headings []string
+	// mapping takes each heading to the trip column it sets; "" ignores the heading
+	mapping map[string]string
+	rows    []importRow
+	// errors are cells that couldn't be read as their column's type
+	errors []models.ImportError
+}
+
+// importFormat takes ?format=, falling back to the request's Content-Type.
+func importFormat(c echo.Context) (string, error) {
+	format := c.QueryParam("format")
+	if format == "" {
+		switch strings.TrimSpace(strings.Split(c.Request().Header.Get(echo.HeaderContentType), ";")[0]) {
+		case "text/csv":
+			format = "csv"
+		case "application/x-ndjson", "application/ndjson":
+			format = "ndjson"
+		}
+	}
+	if _, ok := transferTypes[format]; !ok {
+		return "", models.ErrImportFormat
+	}
+	return format, nil
+}
+
+// columnMapping matches each heading to a trip column. Repeated ?map=Heading=column
+// parameters override the guesses; map=Heading= ignores a heading.
+func columnMapping(c echo.Context, headings []string, match func(string) string) (map[string]string, error) {
+	mapping := map[string]string{}
+	for _, h := range headings {
+		mapping[h] = match(h)
+	}
+	for _, m := range c.QueryParams()["map"] {
+		i := strings.LastIndex(m, "=")
+		if i == -1 {
+			return nil, models.ErrImportColumn
+		}
+		heading, column := m[:i], m[i+1:]
+		if column != "" && match(column) != column {
+			return nil, models.ErrImportColumn
+		}
+		mapping[heading] = column
+	}
+	return mapping, nil
+}
+
+// ndjsonField is the trip field an NDJSON key sets. Unlike CSV, NDJSON can carry a trip's
+// questions.
+func ndjsonField(key string) string {
+	if key == "questions" {
+		return key
+	}
+	return models.MatchColumn(key)
+}
+
+func readImport(c echo.Context, format string, body io.Reader) (*importFile, error) {
+	f := &importFile{format: format}
+	if format == "csv" {
+		return f, f.readCSV(c, body)
+	}
+	return f, f.readNDJSON(c, body)
+}
+
+func (f *importFile) addRow(r importRow) error {
+	if len(f.rows) == models.MaxImportRows {
+		return models.ErrImportTooLarge
+	}
+	f.rows = append(f.rows, r)
+	return nil
+}
+
+// readCSV reads a header row and then one trip per row. Blank cells leave the trip's
+// field as it is, or at its default for new trips.
+func (f *importFile) readCSV(c echo.Context, body io.Reader) error {
+	r := csv.NewReader(body)
+	r.FieldsPerRecord = -1
+	headings, err := r.Read()
+	if err != nil {
+		return err
+	}
+	if len(headings) > 0 {
+		// spreadsheets often save a byte order mark
+		headings[0] = strings.TrimPrefix(headings[0], "\ufeff")
+	}
+	f.headings = headings
+	if f.mapping, err = columnMapping(c, headings, models.MatchColumn); err != nil {
+		return err
+	}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		row, _ := r.FieldPos(0)
+		next := importRow{row: row, fields: map[string]any{}}
+		for i, cell := range record {
+			if i >= len(headings) || strings.TrimSpace(cell) == "" {
+				continue
+			}
+			column := f.mapping[headings[i]]
+			if column == "" {
+				continue
+			}
+			v, err := models.CSVValue(column, cell)
+			if err != nil {
+				f.errors = append(f.errors, models.ImportError{Row: row, Column: column, Error: err.Error()})
+				next.unreadable = true
+				continue
+			}
+			next.fields[column] = v
+		}
+		if err = f.addRow(next); err != nil {
+			return err
+		}
+	}
+}
+
+// readNDJSON reads one JSON trip per line, in the shape the rest of the API uses.
+func (f *importFile) readNDJSON(c echo.Context, body io.Reader) error {
+	var lines [][]byte
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		lines = append(lines, slices.Clone(scanner.Bytes()))
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	objects := make([]map[string]any, len(lines))
+	for i, line := range lines {
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(line, &objects[i]); err != nil || objects[i] == nil {
+			f.errors = append(f.errors, models.ImportError{Row: i + 1, Error: "not a JSON object"})
+			objects[i] = nil
+			continue
+		}
+		for key := range objects[i] {
+			if !slices.Contains(f.headings, key) {
+				f.headings = append(f.headings, key)
+			}
+		}
+	}
+	slices.Sort(f.headings)
+	var err error
+	if f.mapping, err = columnMapping(c, f.headings, ndjsonField); err != nil {
+		return err
+	}
+	for i, object := range objects {
+		if object == nil {
+			continue
+		}
+		fields := map[string]any{}
+		for key, v := range object {
+			if column := f.mapping[key]; column != "" {
+				fields[column] = v
+			}
+		}
+		if err = f.addRow(importRow{row: i + 1, fields: fields}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *importFile) unmapped() []string {
+	unmapped := []string{}
+	for _, h := range f.headings {
+		if f.mapping[h] == "" {
+			unmapped = append(unmapped, h)
+		}
+	}
+	return unmapped
+}
+
+// importResult says what an import did, or in a dry run would do, with one row. ID is
+// empty for trips a dry run would create.
+type importResult struct {
+	Row        int    `json:"row"`
+	ID         string `json:"id,omitempty"`
+	ExternalID string `json:"external_id,omitempty"`
+	Action     string `json:"action"`
+}
+
+// planImport turns each row into the trip it creates or updates. Every row is checked
+// so the report lists all problems at once.
+func planImport(c echo.Context, orgID string, rows []importRow) ([]models.Trip, []importResult, []models.ImportError, error) {
+	var (
+		trips   []models.Trip
+		results []importResult
+		errs    []models.ImportError
+		// seen catches two rows for the same trip, which would make the result depend on order
+		seen = map[string]int{}
+	)
+	for _, r := range rows {
+		if r.unreadable {
+			continue
+		}
+		id, _ := r.fields["id"].(string)
+		externalID, _ := r.fields["external_id"].(string)
+
+		var existing models.Trip
+		switch {
+		case id != "":
+			t, err := getTrip(c, orgID, id)
+			if err == models.ErrTripNotFound {
+				errs = append(errs, models.ImportError{Row: r.row, Column: "id", Error: "no trip with this id in the org"})
+				continue
+			}
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			existing = t
+		case externalID != "":
+			found, ok, err := storage.FindByExternalID(orgID, externalID)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if ok {
+				if existing, err = storage.ReadTrip(c, found); err != nil {
+					return nil, nil, nil, err
+				}
+			}
+		}
+
+		trip, _ := models.NewTrip().(*models.TripBase)
+		trip.OrgID = orgID
+		result := importResult{Row: r.row, Action: "create"}
+		if existing != nil {
+			trip, _ = existing.(*models.TripBase)
+			result.Action = "update"
+		}
+		key := trip.ID
+		if existing == nil && externalID != "" {
+			key = "external_id:" + externalID
+		}
+		if first, ok := seen[key]; ok {
+			errs = append(errs, models.ImportError{Row: r.row, Error: fmt.Sprintf("same trip as row %d", first)})
+			continue
+		}
+		seen[key] = r.row
+
+		if fieldErrs := models.CheckTripFields(r.fields); len(fieldErrs) > 0 {
+			for _, e := range fieldErrs {
+				e.Row = r.row
+				errs = append(errs, e)
+			}
+			continue
+		}
+		patch, err := json.Marshal(r.fields)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if err = models.ApplyTripEdit(trip, patch, false); err != nil {
+			errs = append(errs, models.RowErrors(r.row, err)...)
+			continue
+		}
+		result.ID, result.ExternalID = trip.ID, trip.ExternalID
+		trips = append(trips, trip)
+		results = append(results, result)
+	}
+	return trips, results, errs, nil
+}
+
+// ImportTrips creates and updates an org's trips from a CSV or NDJSON body. Pass
+// ?preview=true to see how headings map to trip columns, and ?dry_run=true to validate
+// every row without saving. A real import saves nothing unless every row is valid. Rows
+// are matched and saved within the caller's own org.
+func ImportTrips(c echo.Context) error {
+	orgID, status, err := sessionOrg(c, c.QueryParam("org_id"))
+	if err != nil {
+		return problem.JSON(c, status, err)
+	}
+	format, err := importFormat(c)
+	if err != nil {
+		return problem.JSON(c, http.StatusBadRequest, err)
+	}
+	body := http.MaxBytesReader(c.Response(), c.Request().Body, maxImportBytes)
+	f, err := readImport(c, format, body)
+	if err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			return problem.JSON(c, http.StatusRequestEntityTooLarge, err)
+		}
+		return problem.JSON(c, http.StatusBadRequest, err)
+	}
+
+	if c.QueryParam("preview") == "true" {
+		sample := []map[string]any{}
+		for _, r := range f.rows[:min(len(f.rows), previewRows)] {
+			sample = append(sample, r.fields)
+		}
+		return c.JSON(http.StatusOK, log.JSON{
+			"format":   f.format,
+			"columns":  f.headings,
+			"mapping":  f.mapping,
+			"unmapped": f.unmapped(),
+			"sample":   sample,
+			"rows":     len(f.rows),
+			"errors":   append([]models.ImportError{}, f.errors...),
+		})
+	}
+
+	trips, results, errs, err := planImport(c, orgID, f.rows)
+	if err != nil {
+		c.Logger().Error(err)
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	errs = append(f.errors, errs...)
+	slices.SortStableFunc(errs, func(a, b models.ImportError) int { return a.Row - b.Row })
+	if results == nil {
+		results = []importResult{}
+	}
+	if errs == nil {
+		errs = []models.ImportError{}
+	}
+	dryRun := c.QueryParam("dry_run") == "true"
+	created := 0
+	for i, r := range results {
+		if r.Action != "create" {
+			continue
+		}
+		created++
+		if dryRun {
+			results[i].ID = ""
+		}
+	}
+	report := log.JSON{
+		"dry_run": dryRun,
+		"rows":    len(f.rows),
+		"created": created,
+		"updated": len(results) - created,
+		"trips":   results,
+		"errors":  errs,
+	}
+	if len(errs) > 0 && !dryRun {
+		return problem.JSON(c, http.StatusBadRequest, importProblem(errs))
+	}
+	if dryRun || len(trips) == 0 {
+		return c.JSON(http.StatusOK, report)
+	}
+	if err = storage.ImportTrips(c, trips); err != nil {
+		c.Logger().Error(err)
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// ExportTrips streams the trips matching any GetTrips query as CSV or NDJSON
+// (?format=csv, the default, or ?format=ndjson). CSV exports can be imported again as
+// they are. Only the caller's own org's trips are exported.
+func ExportTrips(c echo.Context) error {
+	orgID, status, err := sessionOrg(c, c.QueryParam("org_id"))
+	if err != nil {
+		return problem.JSON(c, status, err)
+	}
+	query := c.QueryParams()
+	query.Set("org_id", orgID)
+	format := c.QueryParam("format")
+	if format == "" {
+		format = "csv"
+	}
+	contentType, ok := transferTypes[format]
+	if !ok {
+		return problem.JSON(c, http.StatusBadRequest, models.ErrImportFormat)
+	}
+	price, err := parsePriceQuery(query, "min_price", "max_price")
+	if err != nil {
+		return problem.JSON(c, queryErrorStatus(err), err)
+	}
+	ranges, err := price.ranges()
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	filters, err := tripQueryFilters(query)
+	if err != nil {
+		return problem.JSON(c, http.StatusBadRequest, err)
+	}
+	matched, _, err := queryTrips(filters, ranges...)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, contentType)
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="trips.%s"`, format))
+	res.WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(res)
+	enc := json.NewEncoder(res)
+	if format == "csv" {
+		if err = w.Write(models.TripColumns); err != nil {
+			return err
+		}
+	}
+	// the status is already sent, so from here errors can only cut the export short
+	it := matched.Iterator()
+	for n := 1; it.HasNext(); n++ {
+		ulid, ok, err := storage.Reverse(storage.Client, it.Next())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		t, err := storage.ReadTrip(c, ulid)
+		if err == models.ErrTripNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !price.contains(t.(*models.TripBase)) {
+			continue
+		}
+		if format == "csv" {
+			err = w.Write(t.(*models.TripBase).CSVRecord())
+		} else {
+			err = enc.Encode(t)
+		}
+		if err != nil {
+			return err
+		}
+		if n%exportFlushRows == 0 {
+			w.Flush()
+			res.Flush()
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// importProblem is the validation_failed error for an import with bad rows. Each bad
+// column is a field such as rows[3].price, numbering rows like the report does.
+func importProblem(errs []models.ImportError) error {
+	fields := make([]problem.FieldError, 0, len(errs))
+	for _, e := range errs {
+		field := fmt.Sprintf("rows[%d]", e.Row)
+		if e.Column != "" {
+			field += "." + e.Column
+		}
+		fields = append(fields, problem.FieldError{Field: field, Rule: "import", Message: e.Error})
+	}
+	return problem.Invalid(fmt.Errorf("%d problems in the import; nothing was imported", len(errs)), fields...)
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+)
+
+// GetItinerary returns a trip's days in date order, each with its items in the order given.
+func GetItinerary(c echo.Context) error {
+	trip, status, err := orgOrPublicTrip(c, c.Param("trip_id"), false)
+	if err != nil {
+		return problem.JSON(c, status, err)
+	}
+	it, err := storage.ReadItinerary(trip.GetID())
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, it)
+}
+
+// PutItinerary replaces a trip's whole itinerary. Every day has to fall within the trip's
+// dates; sending no days clears it.
+func PutItinerary(c echo.Context) error {
+	trip, status, err := orgOrPublicTrip(c, c.Param("trip_id"), true)
+	if err != nil {
+		return problem.JSON(c, status, err)
+	}
+	var it models.Itinerary
+	if err = c.Bind(&it); err != nil {
+		return badBind(c, err)
+	}
+	it.TripID = trip.GetID()
+	if err = it.Validate(trip.GetStartDate(), trip.GetEndDate()); err != nil {
+		return problem.JSON(c, http.StatusBadRequest, err)
+	}
+	if err = storage.WriteItinerary(c, &it); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, it)
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	defaultLikesPage = 20
+	maxLikesPage     = 100
+)
+
+// LikeTrip saves a listed trip to the signed-in volunteer's likes.
+func LikeTrip(c echo.Context) error {
+	trip, err := storage.ReadTrip(c, c.Param("trip_id"))
+	switch err {
+	case nil:
+		break
+	case models.ErrTripNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if trip.GetStatus() != models.TripStatusListed || trip.GetDeletedAt() != 0 {
+		return problem.JSON(c, http.StatusNotFound, models.ErrTripNotFound)
+	}
+	return setLike(c, trip.GetID(), true)
+}
+
+// UnlikeTrip removes a trip from the signed-in volunteer's likes. Unlisted trips can
+// still be unliked.
+func UnlikeTrip(c echo.Context) error {
+	return setLike(c, c.Param("trip_id"), false)
+}
+
+func setLike(c echo.Context, tripID string, liked bool) error {
+	err := storage.SetLike(c, session(c).UserID, tripID, liked)
+	switch err {
+	case nil:
+		break
+	case models.ErrTripNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		c.Logger().Error(err)
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	count, err := storage.LikeCount(tripID)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"trip_id":    tripID,
+		"liked":      liked,
+		"like_count": count,
+	})
+}
+
+// GetLikes pages through the signed-in volunteer's liked trips in the order the trips
+// were created. Pass the returned next value as ?after= to fetch the following page.
+func GetLikes(c echo.Context) error {
+	limit := defaultLikesPage
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return problem.Message(c, http.StatusBadRequest, "limit must be a positive number")
+		}
+		limit = min(n, maxLikesPage)
+	}
+
+	liked, err := storage.ReadLikes(session(c).UserID)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	it := liked.Iterator()
+	if after := c.QueryParam("after"); after != "" {
+		numID, ok, err := storage.Lookup(storage.Client, after)
+		if err != nil {
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+		if !ok {
+			return problem.JSON(c, http.StatusBadRequest, models.ErrInvalidTripID)
+		}
+		it.AdvanceIfNeeded(numID + 1)
+	}
+
+	trips := []models.Trip{}
+	next := ""
+	for it.HasNext() {
+		if len(trips) == limit {
+			next = trips[len(trips)-1].GetID()
+			break
+		}
+		ulid, ok, err := storage.Reverse(storage.Client, it.Next())
+		if err != nil {
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+		if !ok {
+			continue
+		}
+		t, err := storage.ReadTrip(c, ulid)
+		if err == models.ErrTripNotFound {
+			continue // deleted since it was liked
+		}
+		if err != nil {
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+		trips = append(trips, t)
+	}
+	return c.JSON(http.StatusOK, log.JSON{
+		"trips":         trips,
+		"count":         len(trips),
+		"scanned_count": liked.GetCardinality(),
+		"next":          next,
+	})
+}
+
+// GetLikedTripIDs returns every trip ID the signed-in volunteer has liked, so pages
+// listing trips can render each heart without fetching the trips themselves.
+func GetLikedTripIDs(c echo.Context) error {
+	liked, err := storage.ReadLikes(session(c).UserID)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	ids := make([]string, 0, liked.GetCardinality())
+	it := liked.Iterator()
+	for it.HasNext() {
+		ulid, ok, err := storage.Reverse(storage.Client, it.Next())
+		if err != nil {
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+		if ok {
+			ids = append(ids, ulid)
+		}
+	}
+	return c.JSON(http.StatusOK, log.JSON{
+		"trip_ids": ids,
+		"count":    len(ids),
+	})
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/Taiterbase/vtrips/apps/trips/internal/blob"
+	"github.com/Taiterbase/vtrips/apps/trips/internal/media"
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+)
+
+// blobs holds photo bytes; StartAPI picks the implementation from the environment.
+var blobs blob.Store
+
+// maxUploadBytes caps each uploaded photo; override with MEDIA_MAX_BYTES.
+var maxUploadBytes int64 = 10 << 20
+
+func configureMedia() {
+	if n, err := strconv.ParseInt(os.Getenv("MEDIA_MAX_BYTES"), 10, 64); err == nil && n > 0 {
+		maxUploadBytes = n
+	}
+}
+
+// UploadMedia accepts one or more images in "file" parts of a multipart form, with
+// optional "alt" text and "cover=true", and stores the original plus resized copies.
+func UploadMedia(c echo.Context) error {
+	trip, status, err := orgOrPublicTrip(c, c.Param("trip_id"), true)
+	if err != nil {
+		return problem.JSON(c, status, err)
+	}
+
+	// leave room for the multipart framing around the largest allowed photo
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxUploadBytes*models.MaxTripMedia+1<<20)
+	form, err := c.MultipartForm()
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return problem.JSON(c, http.StatusRequestEntityTooLarge, models.ErrMediaTooLarge)
+		}
+		return problem.JSON(c, http.StatusBadRequest, err)
+	}
+	files := form.File["file"]
+	if len(files) == 0 {
+		return problem.Message(c, http.StatusBadRequest, "file is required")
+	}
+	alt := c.FormValue("alt")
+	if len(alt) > 300 {
+		return problem.Message(c, http.StatusBadRequest, "alt text must be at most 300 characters")
+	}
+
+	created := []*models.Media{}
+	for _, fh := range files {
+		if fh.Size > maxUploadBytes {
+			return problem.JSON(c, http.StatusRequestEntityTooLarge, models.ErrMediaTooLarge)
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return problem.JSON(c, http.StatusBadRequest, err)
+		}
+		data, err := io.ReadAll(io.LimitReader(f, maxUploadBytes+1))
+		f.Close()
+		if err != nil {
+			return problem.JSON(c, http.StatusBadRequest, err)
+		}
+		if int64(len(data)) > maxUploadBytes {
+			return problem.JSON(c, http.StatusRequestEntityTooLarge, models.ErrMediaTooLarge)
+		}
+
+		m, err := storeMedia(c, trip.GetID(), alt, c.FormValue("cover") == "true", data)
+		switch err {
+		case nil:
+			created = append(created, m)
+		case models.ErrUnsupportedMedia:
+			return problem.JSON(c, http.StatusUnsupportedMediaType, err)
+		case models.ErrMediaTooLarge:
+			return problem.JSON(c, http.StatusRequestEntityTooLarge, err)
+		case models.ErrTooManyMedia:
+			return problem.JSON(c, http.StatusConflict, err)
+		default:
+			c.Logger().Error(err)
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+	}
+	return c.JSON(http.StatusCreated, log.JSON{
+		"media": created,
+		"count": len(created),
+	})
+}
+
+// storeMedia resizes an upload, writes every variant to the blob store, then records it.
+// Blobs are cleaned up if the record can't be written.
+func storeMedia(c echo.Context, tripID, alt string, cover bool, data []byte) (*models.Media, error) {
+	images, err := media.Process(data)
+	if err != nil {
+		return nil, err
+	}
+	m := models.NewMedia(tripID, alt)
+	m.Cover = cover
+	for _, img := range images {
+		if err = blobs.Put(c.Request().Context(), m.BlobKey(img.Name), img.Data, img.ContentType); err != nil {
+			deleteMediaBlobs(c, m)
+			return nil, err
+		}
+		m.Variants = append(m.Variants, models.MediaVariant{
+			Name:        img.Name,
+			Width:       img.Width,
+			Height:      img.Height,
+			ContentType: img.ContentType,
+			Size:        len(img.Data),
+			URL:         models.MediaURL(tripID, m.ID, img.Name),
+		})
+	}
+	if err = storage.AddMedia(c, m); err != nil {
+		deleteMediaBlobs(c, m)
+		return nil, err
+	}
+	return m, nil
+}
+
+func deleteMediaBlobs(c echo.Context, m *models.Media) {
+	for _, v := range m.Variants {
+		if err := blobs.Delete(c.Request().Context(), m.BlobKey(v.Name)); err != nil {
+			c.Logger().Error(err)
+		}
+	}
+}
+
+// ListMedia returns a trip's photos in display order.
+func ListMedia(c echo.Context) error {
+	trip, status, err := orgOrPublicTrip(c, c.Param("trip_id"), false)
+	if err != nil {
+		return problem.JSON(c, status, err)
+	}
+	list, err := storage.ListMedia(trip.GetID())
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if list == nil {
+		list = []*models.Media{}
+	}
+	return c.JSON(http.StatusOK, log.JSON{
+		"media": list,
+		"count": len(list),
+	})
+}
+
+// UpdateMedia changes a photo's alt text, position or cover flag.
+func UpdateMedia(c echo.Context) error {
+	trip, status, err := orgOrPublicTrip(c, c.Param("trip_id"), true)
+	if err != nil {
+		return problem.JSON(c, status, err)
+	}
+	var update models.MediaUpdate
+	if err = c.Bind(&update); err != nil {
+		return badBind(c, err)
+	}
+	if err = c.Validate(update); err != nil {
+		return problem.JSON(c, http.StatusBadRequest, err)
+	}
+	m, err := storage.UpdateMedia(c, trip.GetID(), c.Param("media_id"), update)
+	switch err {
+	case nil:
+		return c.JSON(http.StatusOK, m)
+	case models.ErrMediaNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+}
+
+func DeleteMedia(c echo.Context) error {
+	trip, status, err := orgOrPublicTrip(c, c.Param("trip_id"), true)
+	if err != nil {
+		return problem.JSON(c, status, err)
+	}
+	m, err := storage.DeleteMedia(c, trip.GetID(), c.Param("media_id"))
+	switch err {
+	case nil:
+		deleteMediaBlobs(c, m)
+		return c.JSON(http.StatusOK, m.ID)
+	case models.ErrMediaNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+}
+
+// GetMediaFile serves one variant of a photo. Photo IDs are unguessable and their bytes
+// never change, so responses are public and cached for good.
+func GetMediaFile(c echo.Context) error {
+	m, err := storage.ReadMedia(c.Param("trip_id"), c.Param("media_id"))
+	switch err {
+	case nil:
+		break
+	case models.ErrMediaNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	variant, ok := m.Variant(c.Param("variant"))
+	if !ok {
+		return problem.JSON(c, http.StatusNotFound, models.ErrMediaNotFound)
+	}
+	data, err := blobs.Get(c.Request().Context(), m.BlobKey(variant.Name))
+	switch err {
+	case nil:
+		break
+	case blob.ErrNotFound:
+		return problem.JSON(c, http.StatusNotFound, models.ErrMediaNotFound)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	c.Response().Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	return c.Blob(http.StatusOK, variant.ContentType, data)
+}
+
+// deleteTripMedia removes every photo of a deleted trip, records and blobs.
+func deleteTripMedia(c echo.Context, tripID string) error {
+	list, err := storage.ListMedia(tripID)
+	if err != nil {
+		return err
+	}
+	for _, m := range list {
+		if _, err = storage.DeleteMedia(c, tripID, m.ID); err != nil {
+			return err
+		}
+		deleteMediaBlobs(c, m)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+
+	"github.com/Taiterbase/vtrips/apps/trips/internal/auth"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+	"github.com/oklog/ulid/v2"
+)
+
+const ctxSession = "session"
+
+// requestID tags each request with an ID, the caller's X-Request-ID when it sent one,
+// and echoes it back so error responses and log lines can be matched up.
+func requestID(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Request().Header.Get(echo.HeaderXRequestID)
+		if id == "" || len(id) > 128 {
+			id = ulid.Make().String()
+		}
+		c.Response().Header().Set(echo.HeaderXRequestID, id)
+		return next(c)
+	}
+}
+
+// session returns the caller stored by requireSession.
+func session(c echo.Context) *auth.Session {
+	s, _ := c.Get(ctxSession).(*auth.Session)
+	return s
+}
+
+// requireSession rejects requests without a valid users-service JWT.
+func requireSession(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		s, err := auth.FromRequest(c.Request())
+		if err != nil {
+			return problem.JSON(c, auth.Status(err), err)
+		}
+		c.Set(ctxSession, s)
+		return next(c)
+	}
+}
+
+// checkMember fails unless the caller is signed in as a member of orgID, the session's
+// tenant: 401 without a session, and 403 for volunteers and other orgs' members. Org
+// operations are authorized with it, never with the org_id they name, which anyone can
+// read off an application. Routes without requireSession have the session read here.
+func checkMember(c echo.Context, orgID string) (int, error) {
+	s := session(c)
+	if s == nil {
+		var err error
+		if s, err = auth.FromRequest(c.Request()); err != nil {
+			return auth.Status(err), err
+		}
+		c.Set(ctxSession, s)
+	}
+	if orgID == "" || s.OrgID != orgID {
+		return http.StatusForbidden, auth.ErrForbidden
+	}
+	return http.StatusOK, nil
+}
+
+// sessionOrg is the org a request acts for: the caller's own, from the session. A request
+// that still names an org has to name that one.
+func sessionOrg(c echo.Context, named string) (string, int, error) {
+	if named == "" {
+		if s := session(c); s != nil {
+			named = s.OrgID
+		}
+	}
+	status, err := checkMember(c, named)
+	return named, status, err
+}
+
+// exactPath serves a route at exactly path. echo's router reads the colon of a custom
+// method such as /v1/trips:batchGet as the start of a path parameter, so without it the
+// route also matches every other /v1/trips<suffix>.
+func exactPath(path string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().URL.Path != path {
+				return echo.ErrNotFound
+			}
+			return next(c)
+		}
+	}
+}
+
+// requireAdmin guards operator endpoints with the ADMIN_TOKEN shared secret, sent as
+// X-Admin-Token. They are disabled when ADMIN_TOKEN is unset.
+func requireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := os.Getenv("ADMIN_TOKEN")
+		if token == "" {
+			return problem.Message(c, http.StatusForbidden, "admin endpoints are disabled")
+		}
+		given := c.Request().Header.Get("X-Admin-Token")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			return problem.Message(c, http.StatusUnauthorized, "unauthorized")
+		}
+		return next(c)
+	}
+}
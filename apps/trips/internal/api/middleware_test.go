@@ -0,0 +1,145 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo"
+)
+
+func TestDebugNeedsTheAdminToken(t *testing.T) {
+	if err := storage.Open(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.Client.Close() })
+	e := echo.New()
+	e.Logger.SetOutput(io.Discard)
+	e.HTTPErrorHandler = problem.HTTPErrorHandler
+	setupRouters(e)
+
+	for _, tc := range []struct {
+		name       string
+		adminToken string
+		sent       string
+		want       int
+	}{
+		{"while admin endpoints are disabled", "", "", http.StatusForbidden},
+		{"without the token", "secret", "", http.StatusUnauthorized},
+		{"with a wrong token", "secret", "guess", http.StatusUnauthorized},
+		{"with the token", "secret", "secret", http.StatusOK},
+	} {
+		t.Setenv("ADMIN_TOKEN", tc.adminToken)
+		req := httptest.NewRequest(http.MethodGet, "/debug", nil)
+		if tc.sent != "" {
+			req.Header.Set("X-Admin-Token", tc.sent)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("GET /debug %s: %d, want %d", tc.name, rec.Code, tc.want)
+		}
+	}
+}
+
+func TestOrgRoutesNeedTheOrgsSession(t *testing.T) {
+	e, trip := newPublicTest(t)
+	withUsers(t)
+	query := "?org_id=" + trip.OrgID
+	for _, route := range []struct{ method, target string }{
+		{http.MethodPost, "/v1/trips/" + trip.ID + "/media" + query},
+		{http.MethodPatch, "/v1/trips/" + trip.ID + "/media/photo" + query},
+		{http.MethodDelete, "/v1/trips/" + trip.ID + "/media/photo" + query},
+		{http.MethodPut, "/v1/trips/" + trip.ID + "/itinerary" + query},
+		{http.MethodPost, "/v1/trips:batchGet" + query},
+		{http.MethodPost, "/v1/trips/import" + query},
+		{http.MethodGet, "/v1/trips/export" + query},
+		{http.MethodPost, "/v1/schedules" + query},
+		{http.MethodGet, "/v1/schedules" + query},
+		{http.MethodGet, "/v1/schedules/schedule" + query},
+		{http.MethodPut, "/v1/schedules/schedule/trips/" + trip.ID + query},
+		{http.MethodPost, "/v1/schedules/schedule/trips/" + trip.ID + "/cancel" + query},
+	} {
+		for _, tc := range []struct {
+			name  string
+			token string
+			want  func(int) bool
+		}{
+			{"without a session", "", func(code int) bool { return code == http.StatusUnauthorized }},
+			{"as another org's member", sessionToken(t, "member", "other-org"), func(code int) bool { return code == http.StatusForbidden }},
+			{"as a volunteer", sessionToken(t, "volunteer", ""), func(code int) bool { return code == http.StatusForbidden }},
+			{"as the org's member", sessionToken(t, "member", trip.OrgID), func(code int) bool {
+				return code != http.StatusUnauthorized && code != http.StatusForbidden
+			}},
+		} {
+			// routes that take the org in their body name it there too
+			req := httptest.NewRequest(route.method, route.target, strings.NewReader(`{"org_id":"`+trip.OrgID+`"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if !tc.want(rec.Code) {
+				t.Errorf("%s %s %s: %d %s", route.method, route.target, tc.name, rec.Code, rec.Body)
+			}
+		}
+	}
+}
+
+func TestExportOnlyHasTheCallersTrips(t *testing.T) {
+	e, trip := newPublicTest(t)
+	withUsers(t)
+	for _, tc := range []struct {
+		org  string
+		want bool
+	}{
+		{trip.OrgID, true},
+		{"other-org", false},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/trips/export?format=ndjson", nil)
+		req.Header.Set("Authorization", "Bearer "+sessionToken(t, "member", tc.org))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("export as %s: %d %s", tc.org, rec.Code, rec.Body)
+		}
+		if got := strings.Contains(rec.Body.String(), trip.ID); got != tc.want {
+			t.Errorf("export as %s has the trip: %v, want %v", tc.org, got, tc.want)
+		}
+	}
+}
+
+func TestSessionsMustBeLive(t *testing.T) {
+	e, trip := newPublicTest(t)
+	withUsers(t, "revoked")
+	for _, tc := range []struct {
+		name  string
+		token string
+		users string
+		want  int
+	}{
+		{"live", sessionToken(t, "member", trip.OrgID), "", http.StatusOK},
+		{"without an expiry", signToken(t, jwt.MapClaims{"sub": "member", "tenant": trip.OrgID}), "", http.StatusUnauthorized},
+		{"expired", signToken(t, jwt.MapClaims{"sub": "member", "tenant": trip.OrgID, "exp": time.Now().Add(-time.Minute).Unix()}), "", http.StatusUnauthorized},
+		{"revoked", sessionToken(t, "revoked", trip.OrgID), "", http.StatusUnauthorized},
+		{"while the users service is down", sessionToken(t, "unchecked", trip.OrgID), "http://127.0.0.1:1", http.StatusServiceUnavailable},
+	} {
+		if tc.users != "" {
+			t.Setenv("USERS_BASE_URL", tc.users)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/v1/schedules", nil)
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%s session: %d, want %d", tc.name, rec.Code, tc.want)
+		}
+	}
+}
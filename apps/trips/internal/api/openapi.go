@@ -0,0 +1,19 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/labstack/echo"
+)
+
+// openAPISpec is the OpenAPI 3.1 document for every route in setupRouters. TestOpenAPISpec
+// keeps the two in step; the frontend's trips client is generated from it.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// GetOpenAPI serves the OpenAPI document for this service.
+func GetOpenAPI(c echo.Context) error {
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSONCharsetUTF8, openAPISpec)
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+)
+
+// specModels are the spec's schemas for Go types. Every JSON field of the type must be
+// a property of its schema, and any other property must be read-only, like the computed
+// fields TripBase.MarshalJSON adds.
+var specModels = map[string]any{
+	"Trip":              models.TripBase{},
+	"PublicTrip":        models.PublicTrip{},
+	"EnumSchema":        models.EnumSchema{},
+	"EnumValue":         models.EnumValue{},
+	"Question":          models.Question{},
+	"Answer":            models.Answer{},
+	"Application":       models.Application{},
+	"AuditEntry":        models.AuditEntry{},
+	"ExchangeRates":     models.ExchangeRates{},
+	"ImportResult":      importResult{},
+	"ImportError":       models.ImportError{},
+	"Place":             models.Place{},
+	"ItineraryItem":     models.ItineraryItem{},
+	"ItineraryDay":      models.ItineraryDay{},
+	"Itinerary":         models.Itinerary{},
+	"MediaVariant":      models.MediaVariant{},
+	"Media":             models.Media{},
+	"MediaUpdate":       models.MediaUpdate{},
+	"Schedule":          models.Schedule{},
+	"FeatureCollection": models.FeatureCollection{},
+	"Feature":           models.Feature{},
+	"Point":             models.Point{},
+	"Problem":           problem.Problem{},
+	"FieldError":        problem.FieldError{},
+}
+
+// specEnums are the spec's schemas for generated enums, by the field each is found in.
+var specEnums = map[string]string{
+	"TripType":    "trip_type",
+	"PrivacyType": "privacy_type",
+	"HousingType": "housing_type",
+	"TripStatus":  "status",
+}
+
+type specDocument struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]specSchema `json:"schemas"`
+	} `json:"components"`
+}
+
+type specSchema struct {
+	Enum       []string `json:"enum"`
+	Properties map[string]struct {
+		ReadOnly bool `json:"readOnly"`
+	} `json:"properties"`
+}
+
+// routeParam is a path parameter; a colon inside a segment, as in /v1/trips:batchGet,
+// is part of the path.
+var routeParam = regexp.MustCompile(`/:(\w+)`)
+
+// TestOpenAPISpec reports every way the OpenAPI document and the service have drifted apart:
+// routes missing from either, and model schemas or enums that no longer match the Go
+// types they describe.
+func TestOpenAPISpec(t *testing.T) {
+	var doc specDocument
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		t.Fatalf("openapi.json: %v", err)
+	}
+	e := echo.New()
+	setupRouters(e)
+
+	var problems []string
+	registered := map[string]bool{}
+	for _, r := range e.Routes() {
+		// groups register catch-all routes of their own so their middleware always runs
+		if strings.HasPrefix(r.Name, "github.com/labstack/echo.") {
+			continue
+		}
+		path := routeParam.ReplaceAllString(r.Path, "/{$1}")
+		registered[r.Method+" "+path] = true
+		if _, ok := doc.Paths[path][strings.ToLower(r.Method)]; !ok {
+			problems = append(problems, fmt.Sprintf("%s %s is not in openapi.json", r.Method, path))
+		}
+	}
+	for path, ops := range doc.Paths {
+		for method := range ops {
+			if !registered[strings.ToUpper(method)+" "+path] {
+				problems = append(problems, fmt.Sprintf("openapi.json has %s %s, which has no route", strings.ToUpper(method), path))
+			}
+		}
+	}
+
+	for name, v := range specModels {
+		schema, ok := doc.Components.Schemas[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("openapi.json has no %s schema", name))
+			continue
+		}
+		fields := jsonFields(reflect.TypeOf(v))
+		for _, f := range fields {
+			if _, ok := schema.Properties[f]; !ok {
+				problems = append(problems, fmt.Sprintf("%s schema is missing %s", name, f))
+			}
+		}
+		for prop, p := range schema.Properties {
+			if !slices.Contains(fields, prop) && !p.ReadOnly {
+				problems = append(problems, fmt.Sprintf("%s schema has %s, which %T doesn't", name, prop, v))
+			}
+		}
+	}
+	for name, field := range specEnums {
+		enum, _ := models.EnumFor(field)
+		if !slices.Equal(doc.Components.Schemas[name].Enum, enum.Names()) {
+			problems = append(problems, fmt.Sprintf("%s schema should list %s", name, strings.Join(enum.Names(), ", ")))
+		}
+	}
+
+	slices.Sort(problems)
+	for _, p := range problems {
+		t.Errorf("openapi.json is out of date: %s", p)
+	}
+}
+
+// jsonFields lists the JSON names of a struct's fields, including promoted ones.
+func jsonFields(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch {
+		case name == "-" || !f.IsExported():
+		case f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct:
+			names = append(names, jsonFields(f.Type)...)
+		case name == "":
+			names = append(names, f.Name)
+		default:
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/RoaringBitmap/roaring/roaring64"
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+)
+
+// priceQuery is a price range in one currency. Trips priced in other currencies are
+// converted at the current exchange rates before they're compared.
+type priceQuery struct {
+	// min and max are minor units of currency; max is -1 when there's no upper bound
+	min, max int64
+	currency string
+	rates    *models.ExchangeRates
+}
+
+// parsePriceQuery reads a range written in major units, such as max_price=499.99, in
+// the ?currency= given or USD. It returns nil when neither bound is set.
+func parsePriceQuery(query url.Values, minParam, maxParam string) (*priceQuery, error) {
+	lo, hi := query.Get(minParam), query.Get(maxParam)
+	if lo == "" && hi == "" {
+		return nil, nil
+	}
+	q := &priceQuery{max: -1, currency: models.NormalizedCurrency}
+	if v := query.Get("currency"); v != "" {
+		q.currency = strings.ToUpper(v)
+	}
+	if !models.ValidCurrency(q.currency) {
+		return nil, models.ErrInvalidCurrency
+	}
+	var err error
+	if q.rates, err = storage.ExchangeRates(); err != nil {
+		return nil, err
+	}
+	if _, ok := q.rates.Convert(1, q.currency, models.NormalizedCurrency); !ok {
+		return nil, models.ErrNoExchangeRate
+	}
+	if lo != "" {
+		if q.min, err = models.ParseAmount(lo, q.currency); err != nil {
+			return nil, err
+		}
+	}
+	if hi != "" {
+		if q.max, err = models.ParseAmount(hi, q.currency); err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+// queryErrorStatus is the response status for an error reading a query's range filters.
+func queryErrorStatus(err error) int {
+	switch err {
+	case models.ErrInvalidPayload, models.ErrInvalidCurrency, models.ErrInvalidPrice, models.ErrNoExchangeRate:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ranges are the postings queryTrips intersects for the range: every trip in the price
+// index bands it overlaps. The bands are coarse, so matches still need contains.
+func (q *priceQuery) ranges() ([]*roaring64.Bitmap, error) {
+	if q == nil {
+		return nil, nil
+	}
+	// a cent either side covers rounding in the conversion
+	lo, _ := q.rates.Convert(q.min, q.currency, models.NormalizedCurrency)
+	hi := int64(-1)
+	if q.max >= 0 {
+		hi, _ = q.rates.Convert(q.max, q.currency, models.NormalizedCurrency)
+		hi++
+	}
+	inRange := roaring64.New()
+	for _, tk := range models.PriceTokens(max(lo-1, 0), hi) {
+		bm, err := storage.BitmapForToken(tk)
+		if err != nil {
+			return nil, err
+		}
+		inRange.Or(bm)
+	}
+	return []*roaring64.Bitmap{inRange}, nil
+}
+
+// contains reports whether the trip's price, converted into the range's currency, is
+// inside the range. Trips in a currency without a rate never match.
+func (q *priceQuery) contains(t *models.TripBase) bool {
+	if q == nil {
+		return true
+	}
+	price, ok := q.rates.Convert(t.Price, t.Currency, q.currency)
+	return ok && price >= q.min && (q.max < 0 || price <= q.max)
+}
+
+// GetExchangeRates returns the table prices are converted with.
+func GetExchangeRates(c echo.Context) error {
+	table, err := storage.ExchangeRates()
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, table)
+}
+
+// PutExchangeRates replaces the exchange rate table and re-prices the trips it moves.
+func PutExchangeRates(c echo.Context) error {
+	var table models.ExchangeRates
+	if err := c.Bind(&table); err != nil {
+		return badBind(c, err)
+	}
+	if err := table.Validate(); err != nil {
+		return problem.JSON(c, http.StatusBadRequest, err)
+	}
+	repriced, err := storage.SetExchangeRates(c, &table)
+	if err != nil {
+		c.Logger().Error(err)
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, log.JSON{
+		"rates":    table,
+		"repriced": repriced,
+	})
+}
+
+// loadExchangeRates replaces the exchange rate table with the JSON file at path, in the
+// shape PutExchangeRates takes.
+func loadExchangeRates(e *echo.Echo, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var table models.ExchangeRates
+	if err = json.Unmarshal(b, &table); err != nil {
+		return err
+	}
+	if err = table.Validate(); err != nil {
+		return err
+	}
+	repriced, err := storage.SetExchangeRates(e.NewContext(nil, nil), &table)
+	if err != nil {
+		return err
+	}
+	e.Logger.Infof("loaded exchange rates from %s, re-priced %d trips", path, repriced)
+	return nil
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/RoaringBitmap/roaring/roaring64"
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	defaultPublicPage = 24
+	maxPublicPage     = 100
+	publicDateLayout  = "2006-01-02"
+)
+
+// publicFilters are the indexed fields anonymous visitors may filter listed trips by.
+var publicFilters = []string{"trip_type", "housing_type", "privacy_type", "country", "city"}
+
+// publicQuery holds the range filters the bitmap index can't answer exactly; they are
+// applied to each trip after it is read.
+type publicQuery struct {
+	from, to int64
+	// price is ?price_min= and ?price_max= in ?currency=, or nil
+	price *priceQuery
+}
+
+func parsePublicQuery(c echo.Context) (publicQuery, error) {
+	var q publicQuery
+	for name, dst := range map[string]*int64{"from": &q.from, "to": &q.to} {
+		if v := c.QueryParam(name); v != "" {
+			t, err := time.Parse(publicDateLayout, v)
+			if err != nil {
+				return q, models.ErrInvalidPayload
+			}
+			*dst = t.Unix()
+		}
+	}
+	if q.to != 0 {
+		q.to += 86400 - 1 // the whole "to" day
+	}
+	var err error
+	q.price, err = parsePriceQuery(c.QueryParams(), "price_min", "price_max")
+	return q, err
+}
+
+// matches reports whether the trip's dates overlap [from, to] and its price is in range.
+// Trips without dates never match a date filter.
+func (q publicQuery) matches(t *models.TripBase) bool {
+	if !q.price.contains(t) {
+		return false
+	}
+	if q.from == 0 && q.to == 0 {
+		return true
+	}
+	if t.StartDate == 0 {
+		return false
+	}
+	end := t.EndDate
+	if end == 0 {
+		end = t.StartDate
+	}
+	return (q.from == 0 || end >= q.from) && (q.to == 0 || t.StartDate <= q.to)
+}
+
+// GetPublicTrips lists listed, non-deleted trips across every org, newest first, for
+// anonymous visitors. Pass the returned next value as ?after= to fetch the following page.
+// ?format=geojson maps them instead; see GetTrips for its bbox and zoom parameters.
+func GetPublicTrips(c echo.Context) error {
+	limit := defaultPublicPage
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return problem.Message(c, http.StatusBadRequest, "limit must be a positive number")
+		}
+		limit = min(n, maxPublicPage)
+	}
+	q, err := parsePublicQuery(c)
+	if err != nil {
+		return problem.JSON(c, queryErrorStatus(err), err)
+	}
+	ranges, err := q.price.ranges()
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	filters := map[string][]string{"status": {string(models.TripStatusListed)}}
+	for _, field := range publicFilters {
+		values := c.QueryParams()[field]
+		for _, v := range values {
+			if err := models.CheckFilter(field, v); err != nil {
+				return problem.JSON(c, http.StatusBadRequest, err)
+			}
+		}
+		if len(values) > 0 {
+			filters[field] = values
+		}
+	}
+	matched, scannedCount, err := queryTrips(filters, ranges...)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if c.QueryParam("format") == "geojson" {
+		return publicGeoJSON(c, q, matched)
+	}
+	if after := c.QueryParam("after"); after != "" {
+		numID, ok, err := storage.Lookup(storage.Client, after)
+		if err != nil {
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+		if !ok {
+			return problem.JSON(c, http.StatusBadRequest, models.ErrInvalidTripID)
+		}
+		matched.RemoveRange(numID, math.MaxUint64)
+	}
+
+	trips := []*models.PublicTrip{}
+	next := ""
+	it := matched.ReverseIterator()
+	for it.HasNext() {
+		if len(trips) == limit {
+			next = trips[len(trips)-1].ID
+			break
+		}
+		ulid, ok, err := storage.Reverse(storage.Client, it.Next())
+		if err != nil {
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+		if !ok {
+			continue
+		}
+		t, err := storage.ReadTrip(c, ulid)
+		if err == models.ErrTripNotFound {
+			continue
+		}
+		if err != nil {
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+		trip := t.(*models.TripBase)
+		// the index should already exclude these; never leak a trip that isn't public
+		if trip.Status != models.TripStatusListed || trip.DeletedAt != 0 || !q.matches(trip) {
+			continue
+		}
+		trips = append(trips, trip.Public())
+	}
+	return c.JSON(http.StatusOK, log.JSON{
+		"trips":         trips,
+		"count":         len(trips),
+		"scanned_count": scannedCount,
+		"next":          next,
+	})
+}
+
+// publicGeoJSON maps every matching listed trip for the browse map; with ?zoom= set the
+// response stays small however many trips match, so it isn't paged.
+func publicGeoJSON(c echo.Context, q publicQuery, matched *roaring64.Bitmap) error {
+	geo, err := parseGeoQuery(c.QueryParams())
+	if err != nil {
+		return problem.JSON(c, http.StatusBadRequest, err)
+	}
+	if matched, err = geo.narrow(matched); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	var trips []*models.TripBase
+	it := matched.Iterator()
+	for it.HasNext() {
+		ulid, ok, err := storage.Reverse(storage.Client, it.Next())
+		if err != nil {
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+		if !ok {
+			continue
+		}
+		t, err := storage.ReadTrip(c, ulid)
+		if err == models.ErrTripNotFound {
+			continue
+		}
+		if err != nil {
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+		trip := t.(*models.TripBase)
+		if trip.Status != models.TripStatusListed || trip.DeletedAt != 0 || !q.matches(trip) || !geo.contains(trip) {
+			continue
+		}
+		trips = append(trips, trip)
+	}
+	return geoJSONResponse(c, models.NewFeatureCollection(trips, geo.zoom).Public())
+}
+
+// GetPublicTrip returns one trip to anonymous visitors, as long as it is listed and not
+// deleted. Like the list, it leaves out the org's fields; see models.PublicTrip.
+func GetPublicTrip(c echo.Context) error {
+	t, err := storage.ReadTrip(c, c.Param("trip_id"))
+	switch err {
+	case nil:
+		break
+	case models.ErrTripNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if t.GetStatus() != models.TripStatusListed || t.GetDeletedAt() != 0 {
+		return problem.JSON(c, http.StatusNotFound, models.ErrTripNotFound)
+	}
+	return c.JSON(http.StatusOK, t.(*models.TripBase).Public())
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+)
+
+// newPublicTest serves the REST routes from a scratch database holding one listed trip.
+func newPublicTest(t *testing.T) (*echo.Echo, *models.TripBase) {
+	t.Helper()
+	if err := storage.Open(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.Client.Close() })
+	e := echo.New()
+	e.Logger.SetOutput(io.Discard)
+	e.HTTPErrorHandler = problem.HTTPErrorHandler
+	setupRouters(e)
+
+	trip := models.NewTrip().(*models.TripBase)
+	trip.OrgID = "secret-org"
+	trip.ExternalID = "row-7"
+	trip.Name = "Reef survey"
+	trip.Status = models.TripStatusListed
+	trip.Latitude, trip.Longitude = 12.1, -68.9
+	if err := storage.CreateTrip(e.NewContext(nil, nil), trip); err != nil {
+		t.Fatal(err)
+	}
+	return e, trip
+}
+
+func TestPublicTripsLeaveOutOrgFields(t *testing.T) {
+	e, trip := newPublicTest(t)
+	for _, target := range []string{
+		"/v1/public/trips",
+		"/v1/public/trips/" + trip.ID,
+		"/v1/public/trips?format=geojson",
+	} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: %d %s", target, rec.Code, rec.Body)
+		}
+		var body struct {
+			Trips    []map[string]any `json:"trips"`
+			Features []struct {
+				Properties map[string]any `json:"properties"`
+			} `json:"features"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		var fields map[string]any
+		switch {
+		case len(body.Trips) == 1:
+			fields = body.Trips[0]
+		case len(body.Features) == 1:
+			fields = body.Features[0].Properties
+		default:
+			json.Unmarshal(rec.Body.Bytes(), &fields)
+		}
+		if fields["id"] != trip.ID {
+			t.Fatalf("GET %s sent %s", target, rec.Body)
+		}
+		for _, private := range []string{"org_id", "external_id", "schedule_id", "price_usd"} {
+			if _, ok := fields[private]; ok {
+				t.Errorf("GET %s sent %s", target, private)
+			}
+		}
+	}
+}
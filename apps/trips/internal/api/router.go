@@ -1,8 +1,14 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"time"
 
+	"github.com/Taiterbase/vtrips/apps/trips/internal/blob"
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/pkg/problem"
 	"github.com/go-playground/validator"
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
@@ -23,24 +29,131 @@ func StartAPI() {
 	e.Logger.SetLevel(log.DEBUG)
 	e.Debug = true
 	e.HideBanner = true
+	e.HTTPErrorHandler = problem.HTTPErrorHandler
+	e.Use(requestID)
 	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
-		Format: "${time_rfc3339} ${status} ${method} ${uri} ${latency_human}\n",
+		Format: "${time_rfc3339} ${id} ${status} ${method} ${uri} ${latency_human}\n",
 	}))
 	e.Use(middleware.Recover())
 
 	e.Validator = &Validator{validator: validator.New()}
+	if window, err := time.ParseDuration(os.Getenv("SEAT_OFFER_WINDOW")); err == nil && window > 0 {
+		storage.OfferWindow = window
+	}
+	go expireOffers(e)
+	if horizon, err := time.ParseDuration(os.Getenv("SCHEDULE_HORIZON")); err == nil && horizon > 0 {
+		storage.ScheduleHorizon = horizon
+	}
+	go extendSchedules(e)
+	if path := os.Getenv("EXCHANGE_RATES_FILE"); path != "" {
+		if err := loadExchangeRates(e, path); err != nil {
+			e.Logger.Fatal(err)
+		}
+	}
+	// after the rates load, so migrated prices are converted with them
+	if err := storage.Migrate(e.NewContext(nil, nil)); err != nil {
+		e.Logger.Fatal(err)
+	}
+	configureMedia()
+	store, err := blob.FromEnv(context.Background())
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+	blobs = store
 	setupRouters(e)
+	go serveGRPC(e)
 	e.Logger.Fatal(e.Start(fmt.Sprintf("%s:%s", "0.0.0.0", "8080")))
 }
 
 func setupRouters(eng *echo.Echo) {
 	eng.GET("/v1/trips", GetTrips)
+	eng.GET("/v1/trips/schema", GetTripSchema)
+	eng.GET("/v1/trips/export", ExportTrips, requireSession)
+	eng.POST("/v1/trips/import", ImportTrips, requireSession)
+	eng.POST("/v1/trips:batchGet", BatchGetTrips, exactPath("/v1/trips:batchGet"))
 
 	// item operations
-	eng.POST("/v1/trips", CreateTrip)
+	// org operations check the session belongs to the org they name; see checkMember
+	eng.POST("/v1/trips", CreateTrip, requireSession)
 	eng.GET("/v1/trips/:trip_id", GetTrip)
-	eng.PUT("/v1/trips/:trip_id", UpdateTrip)
-	eng.DELETE("/v1/trips/:trip_id", DeleteTrip)
+	eng.PUT("/v1/trips/:trip_id", UpdateTrip, requireSession)
+	eng.DELETE("/v1/trips/:trip_id", DeleteTrip, requireSession)
 
-	eng.GET("/debug", DatabaseDebug)
+	// trip photos
+	eng.POST("/v1/trips/:trip_id/media", UploadMedia, requireSession)
+	eng.GET("/v1/trips/:trip_id/media", ListMedia)
+	eng.PATCH("/v1/trips/:trip_id/media/:media_id", UpdateMedia, requireSession)
+	eng.DELETE("/v1/trips/:trip_id/media/:media_id", DeleteMedia, requireSession)
+	eng.GET("/v1/trips/:trip_id/media/:media_id/:variant", GetMediaFile)
+
+	// day-by-day plan
+	eng.GET("/v1/trips/:trip_id/itinerary", GetItinerary)
+	eng.PUT("/v1/trips/:trip_id/itinerary", PutItinerary, requireSession)
+
+	// recurring trips; each occurrence is an ordinary trip with schedule_id set
+	eng.POST("/v1/schedules", CreateSchedule, requireSession)
+	eng.GET("/v1/schedules", ListSchedules, requireSession)
+	eng.GET("/v1/schedules/:schedule_id", GetSchedule, requireSession)
+	eng.PUT("/v1/schedules/:schedule_id/trips/:trip_id", UpdateScheduleTrips, requireSession)
+	eng.POST("/v1/schedules/:schedule_id/trips/:trip_id/cancel", CancelScheduleTrips, requireSession)
+
+	// prices are converted with these for price filters; operators replace the table
+	eng.GET("/v1/exchange-rates", GetExchangeRates)
+	eng.PUT("/v1/admin/exchange-rates", PutExchangeRates, requireAdmin)
+
+	// anonymous reads; only ever listed, non-deleted trips
+	eng.GET("/v1/public/trips", GetPublicTrips)
+	eng.GET("/v1/public/trips/:trip_id", GetPublicTrip)
+
+	// iCalendar feeds; a single trip is GET /v1/trips/:trip_id.ics, handled by GetTrip
+	eng.GET("/v1/orgs/:org_id/calendar.ics", OrgCalendar)
+	eng.GET("/v1/calendars/:token", UserCalendar)
+	eng.GET("/v1/users/me/calendar", GetCalendarFeed, requireSession)
+	eng.POST("/v1/users/me/calendar/reset", ResetCalendarFeed, requireSession)
+
+	// volunteer applications
+	eng.POST("/v1/trips/:trip_id/applications", ApplyHandler, requireSession)
+	eng.GET("/v1/trips/:trip_id/applications", ListTripApplications, requireSession)
+	appGroup := eng.Group("/v1/applications", requireSession)
+	appGroup.GET("", ListApplications)
+	appGroup.GET("/mine", MyApplications)
+	appGroup.GET("/:application_id", GetApplication)
+	appGroup.POST("/:application_id/decision", DecideApplication)
+	appGroup.POST("/:application_id/withdraw", WithdrawApplication)
+	appGroup.POST("/:application_id/accept", AcceptOfferHandler)
+
+	// liked trips live here rather than in the users service because they index numeric trip IDs
+	likesGroup := eng.Group("/v1/users/me/likes", requireSession)
+	likesGroup.GET("", GetLikes)
+	likesGroup.GET("/ids", GetLikedTripIDs)
+	likesGroup.PUT("/:trip_id", LikeTrip)
+	likesGroup.DELETE("/:trip_id", UnlikeTrip)
+
+	// the dump includes calendar feed tokens and application answers
+	eng.GET("/debug", DatabaseDebug, requireAdmin)
+	eng.GET("/openapi.json", GetOpenAPI)
+}
+
+// expireOffers periodically passes lapsed seat offers on to the next waitlisted volunteer.
+func expireOffers(e *echo.Echo) {
+	c := e.NewContext(nil, nil)
+	for range time.Tick(time.Minute) {
+		if n, err := storage.ExpireOffers(c, time.Now()); err != nil {
+			e.Logger.Error(err)
+		} else if n > 0 {
+			e.Logger.Infof("lapsed %d seat offers", n)
+		}
+	}
+}
+
+// extendSchedules creates schedule instances as they come within the horizon.
+func extendSchedules(e *echo.Echo) {
+	c := e.NewContext(nil, nil)
+	for range time.Tick(time.Hour) {
+		if n, err := storage.ExtendSchedules(c, time.Now()); err != nil {
+			e.Logger.Error(err)
+		} else if n > 0 {
+			e.Logger.Infof("created %d scheduled trips", n)
+		}
+	}
 }
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/trips/internal/auth"
+	"github.com/Taiterbase/vtrips/apps/trips/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+)
+
+// getSchedule loads a schedule owned by orgID.
+func getSchedule(orgID, scheduleID string) (*models.Schedule, error) {
+	s, err := storage.ReadSchedule(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if orgID == "" || s.OrgID != orgID {
+		return nil, models.ErrScheduleNotFound
+	}
+	return s, nil
+}
+
+// CreateSchedule takes {"rrule", "template": {...trip}} and creates one trip per
+// occurrence, starting with the template's own dates, for the caller's org.
+func CreateSchedule(c echo.Context) error {
+	var req struct {
+		OrgID    string          `json:"org_id"`
+		RRule    string          `json:"rrule"`
+		Template models.TripBase `json:"template"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return badBind(c, err)
+	}
+	orgID, status, err := sessionOrg(c, req.OrgID)
+	if err != nil {
+		return problem.JSON(c, status, err)
+	}
+	s := models.NewSchedule(orgID)
+	s.RRule = req.RRule
+	s.Template = req.Template
+	s.Template.ID = ""
+	s.Template.OrgID = s.OrgID
+	s.Template.ScheduleID = s.ID
+	s.Template.CreatedAt = s.CreatedAt
+	s.Template.UpdatedAt = s.UpdatedAt
+	if s.Template.Status == "" {
+		s.Template.Status = models.TripStatusDraft
+	}
+	r, err := s.Validate()
+	if err != nil {
+		return problem.JSON(c, http.StatusBadRequest, err)
+	}
+	if err = storage.CreateSchedule(c, s, r, time.Now()); err != nil {
+		c.Logger().Error(err)
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return scheduleResponse(c, http.StatusCreated, s)
+}
+
+func scheduleResponse(c echo.Context, status int, s *models.Schedule) error {
+	trips, err := storage.ScheduleInstances(c, s.ID)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if trips == nil {
+		trips = []*models.TripBase{}
+	}
+	return c.JSON(status, log.JSON{
+		"schedule": s,
+		"trips":    trips,
+		"count":    len(trips),
+	})
+}
+
+func ListSchedules(c echo.Context) error {
+	orgID, status, err := sessionOrg(c, c.QueryParam("org_id"))
+	if err != nil {
+		return problem.JSON(c, status, err)
+	}
+	list, err := storage.ListSchedules(orgID)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if list == nil {
+		list = []*models.Schedule{}
+	}
+	return c.JSON(http.StatusOK, log.JSON{
+		"schedules": list,
+		"count":     len(list),
+	})
+}
+
+// GetSchedule returns the schedule with its instances, earliest first.
+func GetSchedule(c echo.Context) error {
+	orgID, status, err := sessionOrg(c, c.QueryParam("org_id"))
+	if err != nil {
+		return problem.JSON(c, status, err)
+	}
+	s, err := getSchedule(orgID, c.Param("schedule_id"))
+	switch err {
+	case nil:
+		return scheduleResponse(c, http.StatusOK, s)
+	case models.ErrScheduleNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+}
+
+// scheduleTargets resolves the instance named in the URL and the instances a request with
+// ?scope=this (the default) or ?scope=following applies to.
+func scheduleTargets(c echo.Context) (*models.Schedule, []*models.TripBase, models.EditScope, error) {
+	orgID, _, err := sessionOrg(c, c.QueryParam("org_id"))
+	if err != nil {
+		return nil, nil, "", err
+	}
+	s, err := getSchedule(orgID, c.Param("schedule_id"))
+	if err != nil {
+		return nil, nil, "", err
+	}
+	scope := models.EditScope(c.QueryParam("scope"))
+	if scope == "" {
+		scope = models.ScopeThis
+	}
+	if scope != models.ScopeThis && scope != models.ScopeFollowing {
+		return nil, nil, "", models.ErrInvalidScope
+	}
+	trips, err := storage.ScheduleInstances(c, s.ID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	for i, trip := range trips {
+		if trip.ID != c.Param("trip_id") {
+			continue
+		}
+		if scope == models.ScopeThis {
+			return s, trips[i : i+1], scope, nil
+		}
+		return s, trips[i:], scope, nil
+	}
+	return nil, nil, "", models.ErrNotScheduleInstance
+}
+
+func scheduleTargetError(c echo.Context, err error) error {
+	switch err {
+	case models.ErrScheduleNotFound, models.ErrNotScheduleInstance:
+		return problem.JSON(c, http.StatusNotFound, err)
+	case models.ErrInvalidScope:
+		return problem.JSON(c, http.StatusBadRequest, err)
+	case auth.ErrForbidden:
+		return problem.JSON(c, http.StatusForbidden, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+}
+
+// UpdateScheduleTrips applies a trip edit to one instance, or to it and every later one.
+// "Following" edits keep each instance's own dates and also change the template, so
+// instances created later pick them up.
+func UpdateScheduleTrips(c echo.Context) error {
+	s, targets, scope, err := scheduleTargets(c)
+	if err != nil {
+		return scheduleTargetError(c, err)
+	}
+	patch, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return problem.JSON(c, http.StatusBadRequest, err)
+	}
+	following := scope == models.ScopeFollowing
+	// check every instance before writing any, so a bad patch changes nothing
+	for _, trip := range targets {
+		if err = models.ApplyTripEdit(trip, patch, following); err != nil {
+			return problem.JSON(c, http.StatusBadRequest, err)
+		}
+	}
+	if following {
+		if err = models.ApplyTripEdit(&s.Template, patch, true); err != nil {
+			return problem.JSON(c, http.StatusBadRequest, err)
+		}
+		if err = storage.SaveSchedule(c, s); err != nil {
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+	}
+	for _, trip := range targets {
+		if err = storage.UpdateTrip(c, trip); err != nil {
+			c.Logger().Error(err)
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+	}
+	return scheduleResponse(c, http.StatusOK, s)
+}
+
+// CancelScheduleTrips cancels one instance, or it and every later one. Cancelling the
+// following instances also ends the schedule there, so no more are created.
+func CancelScheduleTrips(c echo.Context) error {
+	s, targets, scope, err := scheduleTargets(c)
+	if err != nil {
+		return scheduleTargetError(c, err)
+	}
+	if scope == models.ScopeFollowing {
+		s.CancelledFrom = targets[0].StartDate
+		if err = storage.SaveSchedule(c, s); err != nil {
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+	}
+	now := time.Now().Unix()
+	for _, trip := range targets {
+		trip.SetStatus(models.TripStatusCancelled)
+		trip.SetUpdatedAt(now)
+		if err = storage.UpdateTrip(c, trip); err != nil {
+			c.Logger().Error(err)
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+	}
+	return scheduleResponse(c, http.StatusOK, s)
+}
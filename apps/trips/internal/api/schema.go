@@ -0,0 +1,17 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+)
+
+// GetTripSchema lists the names each trip enum accepts, with labels, so clients can
+// build their pickers from the service instead of copying the lists.
+func GetTripSchema(c echo.Context) error {
+	return c.JSON(http.StatusOK, log.JSON{
+		"enums": models.Enums,
+	})
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// liveFor is how long a session the users service vouched for is trusted without asking
+// again, and so how long a revoked session can still be used here.
+const liveFor = 30 * time.Second
+
+var (
+	usersClient = &http.Client{Timeout: 5 * time.Second}
+	// live caches when each recently checked session token has to be checked again
+	live   = map[string]time.Time{}
+	liveMu sync.Mutex
+)
+
+// usersURL is the users service; override with USERS_BASE_URL.
+func usersURL() string {
+	if v := os.Getenv("USERS_BASE_URL"); v != "" {
+		return v
+	}
+	return "http://users:80"
+}
+
+// checkRevoked asks the users service whether a session token is still live. Users
+// revoke all their sessions by signing out everywhere or changing their password, and
+// only the users service keeps track of that.
+func checkRevoked(ctx context.Context, token string) error {
+	now := time.Now()
+	liveMu.Lock()
+	until, ok := live[token]
+	liveMu.Unlock()
+	if ok && now.Before(until) {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, usersURL()+"/v1/users/auth/session", nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	req.AddCookie(&http.Cookie{Name: AuthCookieName, Value: token})
+	res, err := usersClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusNoContent:
+		break
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	default:
+		return fmt.Errorf("%w: the users service answered %s", ErrUnavailable, res.Status)
+	}
+
+	liveMu.Lock()
+	defer liveMu.Unlock()
+	// drop lapsed entries now and then rather than letting every token ever seen pile up
+	if len(live) >= 4096 {
+		for t, until := range live {
+			if !now.Before(until) {
+				delete(live, t)
+			}
+		}
+	}
+	live[token] = now.Add(liveFor)
+	return nil
+}
@@ -0,0 +1,42 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps binary objects, such as trip photos, outside Pebble. Keys are slash
+// separated paths like "trips/<trip_id>/<media_id>/thumb".
+type Store interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// FromEnv picks the store named by BLOB_STORE: "fs" (the default) writes under
+// BLOB_DIR, "s3" talks to any S3-compatible endpoint such as localstack.
+func FromEnv(ctx context.Context) (Store, error) {
+	switch kind := os.Getenv("BLOB_STORE"); kind {
+	case "", "fs":
+		dir := os.Getenv("BLOB_DIR")
+		if dir == "" {
+			dir = "/tmp/vtrips-blobs"
+		}
+		return NewFS(dir)
+	case "s3":
+		return NewS3(ctx, S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			UseSSL:    os.Getenv("S3_USE_SSL") == "true",
+		})
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE %q", kind)
+	}
+}
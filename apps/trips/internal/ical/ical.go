@@ -0,0 +1,134 @@
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType is what calendar feeds are served as.
+const ContentType = "text/calendar; charset=utf-8"
+
+const (
+	prodID       = "-//vtrips//trips//EN"
+	maxLineBytes = 75
+	dateLayout   = "20060102"
+	stampLayout  = "20060102T150405Z"
+)
+
+// Status values for Event.Status.
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Calendar is a VCALENDAR of all-day events.
+type Calendar struct {
+	// Name is shown by clients that support X-WR-CALNAME when subscribing
+	Name   string
+	Events []Event
+}
+
+// Event is one VEVENT. Start and End are dates; End is the last day of the event, not
+// the exclusive DTEND the format wants.
+type Event struct {
+	// UID must not change between exports of the same event, so clients update it in
+	// place instead of adding a copy.
+	UID string
+	// Sequence goes up each time the event is revised.
+	Sequence    int
+	Created     time.Time
+	Modified    time.Time
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	// Geo is latitude, longitude; nil leaves GEO out
+	Geo    *[2]float64
+	Status string
+}
+
+// Encode renders the calendar as RFC 5545 text: CRLF line endings, TEXT values escaped
+// and lines folded at 75 octets.
+func (cal *Calendar) Encode() []byte {
+	var w writer
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", prodID)
+	w.line("CALSCALE", "GREGORIAN")
+	if cal.Name != "" {
+		w.line("X-WR-CALNAME", escape(cal.Name))
+	}
+	for _, ev := range cal.Events {
+		ev.encode(&w)
+	}
+	w.line("END", "VCALENDAR")
+	return w.buf.Bytes()
+}
+
+func (ev Event) encode(w *writer) {
+	end := ev.End
+	if end.Before(ev.Start) {
+		end = ev.Start
+	}
+	w.line("BEGIN", "VEVENT")
+	w.line("UID", ev.UID)
+	w.line("SEQUENCE", strconv.Itoa(ev.Sequence))
+	// DTSTAMP is the last revision for feeds published without a METHOD
+	w.line("DTSTAMP", ev.Modified.UTC().Format(stampLayout))
+	if !ev.Created.IsZero() {
+		w.line("CREATED", ev.Created.UTC().Format(stampLayout))
+	}
+	w.line("LAST-MODIFIED", ev.Modified.UTC().Format(stampLayout))
+	w.line("DTSTART;VALUE=DATE", ev.Start.UTC().Format(dateLayout))
+	w.line("DTEND;VALUE=DATE", end.UTC().AddDate(0, 0, 1).Format(dateLayout))
+	w.line("SUMMARY", escape(ev.Summary))
+	if ev.Description != "" {
+		w.line("DESCRIPTION", escape(ev.Description))
+	}
+	if ev.Location != "" {
+		w.line("LOCATION", escape(ev.Location))
+	}
+	if ev.Geo != nil {
+		w.line("GEO", fmt.Sprintf("%s;%s", strconv.FormatFloat(ev.Geo[0], 'f', -1, 64), strconv.FormatFloat(ev.Geo[1], 'f', -1, 64)))
+	}
+	if ev.Status != "" {
+		w.line("STATUS", ev.Status)
+	}
+	w.line("END", "VEVENT")
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escape quotes a TEXT value (RFC 5545 section 3.3.11).
+func escape(s string) string {
+	return textEscaper.Replace(s)
+}
+
+type writer struct {
+	buf bytes.Buffer
+}
+
+// line writes "name:value", folding it into 75-octet lines that continue with a space.
+// Folds never split a UTF-8 sequence.
+func (w *writer) line(name, value string) {
+	s := name + ":" + value
+	limit := maxLineBytes
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.buf.WriteString(s[:cut])
+		w.buf.WriteString("\r\n ")
+		s = s[cut:]
+		// the leading space of a continuation line counts towards its length
+		limit = maxLineBytes - 1
+	}
+	w.buf.WriteString(s)
+	w.buf.WriteString("\r\n")
+}
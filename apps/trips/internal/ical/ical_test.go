@@ -0,0 +1,67 @@
+package ical
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestEncodeMatchesGolden(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 0, 0, 0, 0, time.UTC) }
+	cal := Calendar{
+		Name: "Trips by Reef, Rock & Co.",
+		Events: []Event{{
+			UID:      "one-day@vtrips",
+			Sequence: 2,
+			Created:  day(1),
+			Modified: day(2).Add(90 * time.Minute),
+			// a one-day trip ends on the day it starts; DTEND is the day after
+			Start: day(10),
+			End:   day(10),
+			// long enough to fold, with multibyte runes across the fold points
+			Summary:     "Récif de corail — journée de plongée à Bonaire 🐠🐠🐠🐠🐠🐠 avec l'équipe locale, puis déjeuner au bord de l'eau et nettoyage de la plage",
+			Description: "Bring: fins, mask; a towel\\sunscreen.\nMeet at 8, dock B.",
+			Location:    "Kralendijk, Bonaire",
+			Geo:         &[2]float64{12.15, -68.27},
+			Status:      StatusConfirmed,
+		}, {
+			UID:      "cancelled@vtrips",
+			Modified: day(3),
+			Start:    day(20),
+			End:      day(22),
+			Summary:  "Trail repair",
+			Status:   StatusCancelled,
+		}},
+	}
+	got := cal.Encode()
+
+	for i, line := range strings.Split(strings.TrimSuffix(string(got), "\r\n"), "\r\n") {
+		if len(line) > maxLineBytes {
+			t.Errorf("line %d is %d octets: %q", i+1, len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line %d splits a UTF-8 sequence: %q", i+1, line)
+		}
+	}
+
+	golden := filepath.Join("testdata", "calendar.ics")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Encode() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,103 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxPixels bounds the decoded size of an upload so a small file can't expand into
+// gigabytes of pixels.
+const MaxPixels = 40_000_000
+
+const jpegQuality = 82
+
+var supported = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// Image is one stored rendition of an upload.
+type Image struct {
+	Name        string
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Process sniffs and validates an upload, then returns the untouched original followed
+// by a JPEG for each of models.MediaSizes. Images are never scaled up.
+func Process(data []byte) ([]Image, error) {
+	contentType := http.DetectContentType(data)
+	if !supported[contentType] {
+		return nil, models.ErrUnsupportedMedia
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, models.ErrUnsupportedMedia
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, models.ErrMediaTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, models.ErrUnsupportedMedia
+	}
+
+	images := []Image{{
+		Name:        models.MediaOriginal,
+		Data:        data,
+		ContentType: contentType,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}}
+	for _, size := range models.MediaSizes {
+		resized, err := resize(src, size.Width)
+		if err != nil {
+			return nil, err
+		}
+		b := resized.Bounds()
+		images = append(images, Image{
+			Name:        size.Name,
+			Data:        resized.encoded,
+			ContentType: "image/jpeg",
+			Width:       b.Dx(),
+			Height:      b.Dy(),
+		})
+	}
+	return images, nil
+}
+
+type resizedImage struct {
+	*image.RGBA
+	encoded []byte
+}
+
+// resize fits src within bound pixels on its longest side and encodes it as JPEG,
+// flattening any transparency onto white.
+func resize(src image.Image, bound int) (resizedImage, error) {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if longest := max(w, h); longest > bound {
+		w = max(w*bound/longest, 1)
+		h = max(h*bound/longest, 1)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return resizedImage{}, err
+	}
+	return resizedImage{RGBA: dst, encoded: buf.Bytes()}, nil
+}
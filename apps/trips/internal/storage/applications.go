@@ -0,0 +1,419 @@
+package storage
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/RoaringBitmap/roaring/roaring64"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/cockroachdb/pebble"
+	"github.com/labstack/echo"
+)
+
+// applicationMu serializes application writes so the volunteer-limit check and the
+// one-active-application-per-trip claim can't race with another decision.
+var applicationMu sync.Mutex
+
+func applicationKey(applicationID string) []byte {
+	return models.MakeKey("application_id", applicationID)
+}
+
+// applicantKey maps trip+user to the volunteer's active application on that trip.
+func applicantKey(tripID, userID string) []byte {
+	return models.MakeKey("application_active", tripID+":"+userID)
+}
+
+// waitlistSeqKey holds the last sequence number given to an application joining a waitlist.
+var waitlistSeqKey = []byte("application_waitlist_seq")
+
+func auditKey(applicationID, entryID string) []byte {
+	return models.MakeKey("application_audit", applicationID+":"+entryID)
+}
+
+func ReadApplication(c echo.Context, applicationID string) (*models.Application, error) {
+	v, closer, err := Client.Get(applicationKey(applicationID))
+	if err == pebble.ErrNotFound {
+		return nil, models.ErrApplicationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	var app models.Application
+	err = json.Unmarshal(v, &app)
+	return &app, err
+}
+
+// removeTokens takes numID out of each posting list, deleting lists that become empty.
+func removeTokens(batch *pebble.Batch, tokens [][]byte, numID uint64) error {
+	for _, tk := range tokens {
+		data, closer, err := batch.Get(tk)
+		if err == pebble.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		rb, err := decode(data)
+		closer.Close()
+		if err != nil {
+			return err
+		}
+		if rb.Remove(numID); rb.IsEmpty() {
+			if err = batch.Delete(tk, pebble.Sync); err != nil {
+				return err
+			}
+			continue
+		}
+		blob, err := encode(rb)
+		if err != nil {
+			return err
+		}
+		if err = batch.Set(tk, blob, pebble.Sync); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeAudit(batch *pebble.Batch, entry *models.AuditEntry) error {
+	j, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return batch.Set(auditKey(entry.ApplicationID, entry.ID), j, pebble.Sync)
+}
+
+// CreateApplication stores a new application with its "apply" audit entry. It returns
+// ErrAlreadyApplied while the volunteer has another active application for the trip.
+func CreateApplication(c echo.Context, app *models.Application) error {
+	applicationMu.Lock()
+	defer applicationMu.Unlock()
+
+	batch := Client.NewIndexedBatch()
+	defer batch.Close()
+
+	claim := applicantKey(app.TripID, app.UserID)
+	if _, closer, err := batch.Get(claim); err == nil {
+		closer.Close()
+		return models.ErrAlreadyApplied
+	} else if err != pebble.ErrNotFound {
+		return err
+	}
+
+	numID, err := GetOrAllocate(Client, app.ID)
+	if err != nil {
+		return err
+	}
+	j, err := json.Marshal(app)
+	if err != nil {
+		return err
+	}
+	if err = batch.Set(applicationKey(app.ID), j, pebble.Sync); err != nil {
+		return err
+	}
+	if err = batch.Set(claim, []byte(app.ID), pebble.Sync); err != nil {
+		return err
+	}
+	if err = writeTokens(c, batch, app, numID); err != nil {
+		return err
+	}
+	if err = writeAudit(batch, models.NewAuditEntry(app, app.UserID, "apply", "", "")); err != nil {
+		return err
+	}
+	return batch.Commit(pebble.Sync)
+}
+
+// OfferWindow is how long a promoted volunteer has to accept a freed seat.
+var OfferWindow = 48 * time.Hour
+
+// TransitionApplication moves an application to next and audits the change as action
+// by actorID. Seats on the trip are updated in the same batch: taking one fails with
+// ErrTripFull once the volunteer limit is reached, and freeing one offers it to the
+// longest-waiting waitlisted applicant. The promoted application is returned, if any.
+func TransitionApplication(c echo.Context, applicationID string, next models.ApplicationStatus, actorID, action, note string) (app, promoted *models.Application, err error) {
+	applicationMu.Lock()
+	defer applicationMu.Unlock()
+
+	app, err = ReadApplication(c, applicationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !app.Status.CanTransition(next) {
+		return nil, nil, models.ErrInvalidTransition
+	}
+	trip, err := ReadTrip(c, app.TripID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seats := trip.GetSeatsTaken()
+	from := app.Status
+	switch {
+	case next.HoldsSeat() && !from.HoldsSeat():
+		if trip.IsFull() {
+			return nil, nil, models.ErrTripFull
+		}
+		seats++
+	case from.HoldsSeat() && !next.HoldsSeat():
+		seats--
+	}
+
+	batch := Client.NewIndexedBatch()
+	defer batch.Close()
+	now := time.Now()
+	if err = moveApplication(c, batch, app, next, actorID, action, note, now); err != nil {
+		return nil, nil, err
+	}
+	// a freed seat is only offered on while the trip is under its limit, which may have
+	// been lowered below the seats taken
+	if seats < trip.GetSeatsTaken() && seats < trip.GetVolunteerLimit() {
+		promoted, err = nextWaitlisted(c, app.TripID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if promoted != nil {
+			promoted.OfferExpiresAt = now.Add(OfferWindow).Unix()
+			if err = moveApplication(c, batch, promoted, models.ApplicationOffered, "system", "promote", "", now); err != nil {
+				return nil, nil, err
+			}
+			seats++
+		}
+	}
+	seatsMoved := seats != trip.GetSeatsTaken()
+	if err = setSeats(c, batch, trip, seats); err != nil {
+		return nil, nil, err
+	}
+	if err = batch.Commit(pebble.Sync); err != nil {
+		return nil, nil, err
+	}
+	if seatsMoved {
+		publishTrip(TripUpdated, trip)
+	}
+	return app, promoted, nil
+}
+
+// moveApplication stages app's move to next in batch: postings, record, active claim and audit entry.
+func moveApplication(c echo.Context, batch *pebble.Batch, app *models.Application, next models.ApplicationStatus, actorID, action, note string, now time.Time) error {
+	numID, ok, err := Lookup(Client, app.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return models.ErrApplicationNotFound
+	}
+	if err = removeTokens(batch, app.Tokenize(), numID); err != nil {
+		return err
+	}
+
+	from := app.Status
+	app.Status = next
+	app.UpdatedAt = now.Unix()
+	if next != models.ApplicationOffered {
+		app.OfferExpiresAt = 0
+	}
+	app.WaitlistSeq = 0
+	if next == models.ApplicationWaitlisted {
+		if app.WaitlistSeq, err = nextWaitlistSeq(batch); err != nil {
+			return err
+		}
+	}
+	if actorID != app.UserID && actorID != "system" {
+		app.DecidedBy = actorID
+		app.DecidedAt = now.Unix()
+	}
+	if note != "" {
+		app.Note = note
+	}
+	j, err := json.Marshal(app)
+	if err != nil {
+		return err
+	}
+	if err = batch.Set(applicationKey(app.ID), j, pebble.Sync); err != nil {
+		return err
+	}
+	if err = writeTokens(c, batch, app, numID); err != nil {
+		return err
+	}
+	if !next.Active() {
+		if err = batch.Delete(applicantKey(app.TripID, app.UserID), pebble.Sync); err != nil {
+			return err
+		}
+	}
+	return writeAudit(batch, models.NewAuditEntry(app, actorID, action, from, note))
+}
+
+// nextWaitlistSeq stages the next waitlist sequence number in batch and returns it.
+func nextWaitlistSeq(batch *pebble.Batch) (uint64, error) {
+	var seq uint64
+	v, closer, err := batch.Get(waitlistSeqKey)
+	switch err {
+	case nil:
+		seq = binary.BigEndian.Uint64(v)
+		closer.Close()
+	case pebble.ErrNotFound:
+	default:
+		return 0, err
+	}
+	seq++
+	return seq, batch.Set(waitlistSeqKey, binary.BigEndian.AppendUint64(nil, seq), pebble.Sync)
+}
+
+// nextWaitlisted returns the trip's waitlisted application that has waited longest, or
+// nil. Applications with the same sequence, which only older releases wrote, go in the
+// order they applied.
+func nextWaitlisted(c echo.Context, tripID string) (*models.Application, error) {
+	byTrip, err := BitmapForToken(models.ApplicationKey("trip_id", tripID))
+	if err != nil {
+		return nil, err
+	}
+	waitlisted, err := BitmapForToken(models.ApplicationKey("status", string(models.ApplicationWaitlisted)))
+	if err != nil {
+		return nil, err
+	}
+	var first *models.Application
+	it := roaring64.And(byTrip, waitlisted).Iterator()
+	for it.HasNext() {
+		ulid, ok, err := Reverse(Client, it.Next())
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		app, err := ReadApplication(c, ulid)
+		if err != nil {
+			return nil, err
+		}
+		if first == nil || app.WaitlistSeq < first.WaitlistSeq {
+			first = app
+		}
+	}
+	return first, nil
+}
+
+// setSeats stages the trip's new seat count in batch and reindexes it, so the computed
+// status=full posting follows the count.
+func setSeats(c echo.Context, batch *pebble.Batch, trip models.Trip, seats int) error {
+	if seats == trip.GetSeatsTaken() {
+		return nil
+	}
+	numID, ok, err := Lookup(Client, trip.GetID())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return models.ErrTripNotFound
+	}
+	if err = removeTokens(batch, trip.Tokenize(), numID); err != nil {
+		return err
+	}
+	trip.SetSeatsTaken(seats)
+	j, err := json.Marshal(trip)
+	if err != nil {
+		return err
+	}
+	if err = batch.Set(models.MakeKey("trip_id", trip.GetID()), j, pebble.Sync); err != nil {
+		return err
+	}
+	return writeTokens(c, batch, trip, numID)
+}
+
+// ExpireOffers lapses seat offers whose confirmation window closed before now, passing
+// each seat on to the next waitlisted applicant. It returns how many offers lapsed.
+func ExpireOffers(c echo.Context, now time.Time) (int, error) {
+	offered, err := BitmapForToken(models.ApplicationKey("status", string(models.ApplicationOffered)))
+	if err != nil {
+		return 0, err
+	}
+	lapsed := 0
+	it := offered.Iterator()
+	for it.HasNext() {
+		ulid, ok, err := Reverse(Client, it.Next())
+		if err != nil {
+			return lapsed, err
+		}
+		if !ok {
+			continue
+		}
+		app, err := ReadApplication(c, ulid)
+		if err != nil {
+			return lapsed, err
+		}
+		if app.OfferExpiresAt == 0 || app.OfferExpiresAt > now.Unix() {
+			continue
+		}
+		_, _, err = TransitionApplication(c, app.ID, models.ApplicationLapsed, "system", "lapse", "offer not accepted in time")
+		if err == models.ErrInvalidTransition {
+			continue // accepted or withdrawn since we read it
+		}
+		if err != nil {
+			return lapsed, err
+		}
+		lapsed++
+	}
+	return lapsed, nil
+}
+
+// QueryApplications returns the applications matching every field in filters, where
+// several values for one field match any of them. scanned counts the postings read.
+func QueryApplications(c echo.Context, filters map[string][]string) (apps []*models.Application, scanned int, err error) {
+	var bms []*roaring64.Bitmap
+	for field, values := range filters {
+		union := roaring64.New()
+		for _, value := range values {
+			bm, err := BitmapForToken(models.ApplicationKey(field, value))
+			if err != nil {
+				return nil, 0, err
+			}
+			union.Or(bm)
+		}
+		scanned += int(union.GetCardinality())
+		bms = append(bms, union)
+	}
+	if len(bms) == 0 {
+		return nil, 0, nil
+	}
+
+	it := roaring64.FastAnd(bms...).Iterator()
+	for it.HasNext() {
+		ulid, ok, err := Reverse(Client, it.Next())
+		if err != nil {
+			return nil, scanned, err
+		}
+		if !ok {
+			continue
+		}
+		app, err := ReadApplication(c, ulid)
+		if err != nil {
+			return nil, scanned, err
+		}
+		apps = append(apps, app)
+	}
+	return apps, scanned, nil
+}
+
+// ReadAudit returns the application's audit trail, oldest first.
+func ReadAudit(applicationID string) ([]*models.AuditEntry, error) {
+	prefix := auditKey(applicationID, "")
+	iter, err := Client.NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: append(prefix[:len(prefix):len(prefix)], 0xff),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var entries []*models.AuditEntry
+	for valid := iter.First(); valid; valid = iter.Next() {
+		var entry models.AuditEntry
+		if err := json.Unmarshal(iter.Value(), &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, iter.Error()
+}
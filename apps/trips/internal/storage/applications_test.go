@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/cockroachdb/pebble"
+	"github.com/labstack/echo"
+)
+
+// newApplicationTest opens a scratch database with a one-seat trip and applications to
+// it from each of users, in that order.
+func newApplicationTest(t *testing.T, users ...string) (echo.Context, []*models.Application) {
+	t.Helper()
+	if err := Open(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Client.Close() })
+	e := echo.New()
+	e.Logger.SetOutput(io.Discard)
+	c := e.NewContext(nil, nil)
+
+	trip := models.NewTrip().(*models.TripBase)
+	trip.OrgID = "org"
+	trip.Status = models.TripStatusListed
+	trip.VolunteerLimit = 1
+	if err := CreateTrip(c, trip); err != nil {
+		t.Fatal(err)
+	}
+	var apps []*models.Application
+	for _, user := range users {
+		app := models.NewApplication(trip, user, user)
+		if err := CreateApplication(c, app); err != nil {
+			t.Fatal(err)
+		}
+		apps = append(apps, app)
+	}
+	return c, apps
+}
+
+func transition(t *testing.T, c echo.Context, app *models.Application, next models.ApplicationStatus) *models.Application {
+	t.Helper()
+	_, promoted, err := TransitionApplication(c, app.ID, next, "organizer", string(next), "")
+	if err != nil {
+		t.Fatalf("%s to %s: %v", app.Username, next, err)
+	}
+	return promoted
+}
+
+func TestWaitlistPromotesInWaitlistOrder(t *testing.T) {
+	c, apps := newApplicationTest(t, "alice", "bob", "carol")
+	alice, bob, carol := apps[0], apps[1], apps[2]
+
+	transition(t, c, alice, models.ApplicationApproved)
+	transition(t, c, bob, models.ApplicationWaitlisted)
+	transition(t, c, carol, models.ApplicationWaitlisted)
+	// alice goes back to the waitlist, behind carol, and her seat is offered to bob
+	if promoted := transition(t, c, alice, models.ApplicationWaitlisted); promoted == nil || promoted.ID != bob.ID {
+		t.Fatalf("moving alice back promoted %+v, want bob", promoted)
+	}
+	if promoted := transition(t, c, bob, models.ApplicationWithdrawn); promoted == nil || promoted.ID != carol.ID {
+		t.Fatalf("bob withdrawing promoted %+v, want carol", promoted)
+	}
+	if promoted := transition(t, c, carol, models.ApplicationWithdrawn); promoted == nil || promoted.ID != alice.ID {
+		t.Fatalf("carol withdrawing promoted %+v, want alice", promoted)
+	}
+}
+
+func TestWaitlistWaitsWhileOverTheLimit(t *testing.T) {
+	c, apps := newApplicationTest(t, "alice", "bob", "carol")
+	alice, bob, carol := apps[0], apps[1], apps[2]
+	setLimit := func(limit int) {
+		t.Helper()
+		trip, err := ReadTrip(c, alice.TripID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trip.(*models.TripBase).VolunteerLimit = limit
+		if err = UpdateTrip(c, trip); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	setLimit(2)
+	transition(t, c, alice, models.ApplicationApproved)
+	transition(t, c, bob, models.ApplicationApproved)
+	transition(t, c, carol, models.ApplicationWaitlisted)
+	setLimit(1)
+	if promoted := transition(t, c, alice, models.ApplicationWithdrawn); promoted != nil {
+		t.Fatalf("alice withdrawing with bob still over the limit promoted %s", promoted.Username)
+	}
+	if promoted := transition(t, c, bob, models.ApplicationWithdrawn); promoted == nil || promoted.ID != carol.ID {
+		t.Fatalf("bob withdrawing promoted %+v, want carol", promoted)
+	}
+}
+
+func TestMigrateWaitlistSeqFollowsTheAuditTrail(t *testing.T) {
+	c, apps := newApplicationTest(t, "alice", "bob", "carol")
+	alice, bob, carol := apps[0], apps[1], apps[2]
+
+	transition(t, c, alice, models.ApplicationApproved)
+	transition(t, c, bob, models.ApplicationWaitlisted)
+	transition(t, c, carol, models.ApplicationWaitlisted)
+	transition(t, c, alice, models.ApplicationWaitlisted) // bob is offered the seat
+
+	// make the waitlist look as older releases left it: no sequence, and alice's move
+	// back recorded after carol joined
+	for i, app := range []*models.Application{carol, alice} {
+		stored, err := ReadApplication(c, app.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored.WaitlistSeq = 0
+		j, err := json.Marshal(stored)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = Client.Set(applicationKey(app.ID), j, pebble.Sync); err != nil {
+			t.Fatal(err)
+		}
+		entries, err := ReadAudit(app.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		last := entries[len(entries)-1]
+		last.At = int64(1000 + i)
+		j, err = json.Marshal(last)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = Client.Set(auditKey(app.ID, last.ID), j, pebble.Sync); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	batch := Client.NewIndexedBatch()
+	defer batch.Close()
+	if n, err := migrateWaitlistSeq(c, batch); err != nil || n != 2 {
+		t.Fatalf("migrated %d: %v", n, err)
+	}
+	if err := batch.Commit(pebble.Sync); err != nil {
+		t.Fatal(err)
+	}
+	if next, err := nextWaitlisted(c, alice.TripID); err != nil || next == nil || next.ID != carol.ID {
+		t.Fatalf("next waitlisted is %+v (%v), want carol", next, err)
+	}
+}
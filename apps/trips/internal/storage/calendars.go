@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/cockroachdb/pebble"
+)
+
+// calendarMu keeps a user's token and the feed it points at in step.
+var calendarMu sync.Mutex
+
+// calendarFeedKey holds the feed a token opens.
+func calendarFeedKey(token string) []byte {
+	return models.MakeKey("calendar_feed", token)
+}
+
+// calendarUserKey holds the token of a user's current feed.
+func calendarUserKey(userID string) []byte {
+	return models.MakeKey("calendar_user", userID)
+}
+
+// ReadCalendarFeed returns the feed a token opens.
+func ReadCalendarFeed(token string) (*models.CalendarFeed, error) {
+	v, closer, err := Client.Get(calendarFeedKey(token))
+	if err == pebble.ErrNotFound {
+		return nil, models.ErrCalendarNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	var feed models.CalendarFeed
+	err = json.Unmarshal(v, &feed)
+	return &feed, err
+}
+
+// CalendarFeedFor returns the user's feed, creating it on first use. reset replaces the
+// token, so links handed out before stop working.
+func CalendarFeedFor(userID string, reset bool) (*models.CalendarFeed, error) {
+	calendarMu.Lock()
+	defer calendarMu.Unlock()
+
+	prev := ""
+	v, closer, err := Client.Get(calendarUserKey(userID))
+	switch err {
+	case nil:
+		prev = string(v)
+		closer.Close()
+	case pebble.ErrNotFound:
+	default:
+		return nil, err
+	}
+	if prev != "" && !reset {
+		return ReadCalendarFeed(prev)
+	}
+
+	secret := make([]byte, 24)
+	if _, err = rand.Read(secret); err != nil {
+		return nil, err
+	}
+	feed := &models.CalendarFeed{
+		Token:     base64.RawURLEncoding.EncodeToString(secret),
+		UserID:    userID,
+		CreatedAt: time.Now().Unix(),
+	}
+	j, err := json.Marshal(feed)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := Client.NewBatch()
+	defer batch.Close()
+	if prev != "" {
+		if err = batch.Delete(calendarFeedKey(prev), nil); err != nil {
+			return nil, err
+		}
+	}
+	if err = batch.Set(calendarFeedKey(feed.Token), j, nil); err != nil {
+		return nil, err
+	}
+	if err = batch.Set(calendarUserKey(userID), []byte(feed.Token), nil); err != nil {
+		return nil, err
+	}
+	return feed, batch.Commit(pebble.Sync)
+}
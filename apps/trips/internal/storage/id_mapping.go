@@ -3,6 +3,7 @@ package storage
 import (
 	"encoding/binary"
 	"errors"
+	"strconv"
 
 	"github.com/cockroachdb/pebble"
 )
@@ -88,3 +89,23 @@ func Reverse(db *pebble.DB, id uint64) (ulid string, ok bool, err error) {
 	defer closer.Close()
 	return string(v), true, nil
 }
+
+// reverseAll is Reverse for many IDs from r, in the same order. IDs with no ULID are
+// left out.
+func reverseAll(r pebble.Reader, ids []uint64) ([]string, error) {
+	keys := make(map[string][]byte, len(ids))
+	for _, id := range ids {
+		keys[strconv.FormatUint(id, 10)] = append([]byte(kReverse), putUint64(id)...)
+	}
+	values, err := multiGet(r, keys)
+	if err != nil {
+		return nil, err
+	}
+	ulids := make([]string, 0, len(values))
+	for _, id := range ids {
+		if v, ok := values[strconv.FormatUint(id, 10)]; ok {
+			ulids = append(ulids, string(v))
+		}
+	}
+	return ulids, nil
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"encoding/json"
+
+	"github.com/RoaringBitmap/roaring/roaring64"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/cockroachdb/pebble"
+	"github.com/labstack/echo"
+)
+
+// FindByExternalID returns the ID of the org's trip with the given external ID.
+func FindByExternalID(orgID, externalID string) (string, bool, error) {
+	byExternal, err := BitmapForToken(models.MakeKey("external_id", externalID))
+	if err != nil {
+		return "", false, err
+	}
+	byOrg, err := BitmapForToken(models.MakeKey("org_id", orgID))
+	if err != nil {
+		return "", false, err
+	}
+	matched := roaring64.And(byExternal, byOrg)
+	if matched.IsEmpty() {
+		return "", false, nil
+	}
+	return Reverse(Client, matched.Minimum())
+}
+
+// ImportTrips creates or updates trips in one batch, so an import lands completely or not
+// at all. Postings are gathered in memory and every touched token is written once,
+// rather than once per trip as CreateTrip and UpdateTrip do. As with UpdateTrip, stored
+// seat counts win and the revision sequence moves on from the stored one.
+func ImportTrips(c echo.Context, trips []models.Trip) error {
+	applicationMu.Lock()
+	defer applicationMu.Unlock()
+
+	batch := Client.NewBatch()
+	defer batch.Close()
+
+	kinds := make([]TripEventKind, len(trips))
+	postings := map[string]*roaring64.Bitmap{}
+	posting := func(tk []byte) (*roaring64.Bitmap, error) {
+		if rb, ok := postings[string(tk)]; ok {
+			return rb, nil
+		}
+		rb, err := BitmapForToken(tk)
+		if err != nil {
+			return nil, err
+		}
+		postings[string(tk)] = rb
+		return rb, nil
+	}
+
+	for i, trip := range trips {
+		numID, err := GetOrAllocate(Client, trip.GetID())
+		if err != nil {
+			return err
+		}
+		keyTrip := models.MakeKey("trip_id", trip.GetID())
+		prevBytes, closer, err := Client.Get(keyTrip)
+		switch err {
+		case nil:
+			var prev models.TripBase
+			err = json.Unmarshal(prevBytes, &prev)
+			closer.Close()
+			if err != nil {
+				return err
+			}
+			for _, tk := range prev.Tokenize() {
+				rb, err := posting(tk)
+				if err != nil {
+					return err
+				}
+				rb.Remove(numID)
+			}
+			trip.SetSeatsTaken(prev.SeatsTaken)
+			trip.SetSequence(prev.Sequence + 1)
+			kinds[i] = TripUpdated
+		case pebble.ErrNotFound:
+			trip.SetSeatsTaken(0)
+			trip.SetSequence(0)
+			kinds[i] = TripCreated
+		default:
+			return err
+		}
+
+		if err = normalizePrice(trip); err != nil {
+			return err
+		}
+		for _, tk := range trip.Tokenize() {
+			rb, err := posting(tk)
+			if err != nil {
+				return err
+			}
+			rb.Add(numID)
+		}
+		j, err := json.Marshal(trip)
+		if err != nil {
+			return err
+		}
+		if err = batch.Set(keyTrip, j, nil); err != nil {
+			return err
+		}
+	}
+
+	for tk, rb := range postings {
+		if rb.IsEmpty() {
+			if err := batch.Delete([]byte(tk), nil); err != nil {
+				return err
+			}
+			continue
+		}
+		blob, err := encode(rb)
+		if err != nil {
+			return err
+		}
+		if err = batch.Set([]byte(tk), blob, nil); err != nil {
+			return err
+		}
+	}
+	if err := batch.Commit(pebble.Sync); err != nil {
+		return err
+	}
+	for i, trip := range trips {
+		publishTrip(kinds[i], trip)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/cockroachdb/pebble"
+	"github.com/labstack/echo"
+)
+
+// itineraryKey holds a trip's whole itinerary as one JSON document; it's edited and read
+// as a unit, so there's nothing to gain from storing days separately.
+func itineraryKey(tripID string) []byte {
+	return models.MakeKey("itinerary", tripID)
+}
+
+// ReadItinerary returns the trip's itinerary, or an empty one if it was never written.
+func ReadItinerary(tripID string) (*models.Itinerary, error) {
+	v, closer, err := Client.Get(itineraryKey(tripID))
+	if err == pebble.ErrNotFound {
+		return &models.Itinerary{TripID: tripID, Days: []models.ItineraryDay{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	var it models.Itinerary
+	err = json.Unmarshal(v, &it)
+	return &it, err
+}
+
+// WriteItinerary replaces the trip's itinerary; the caller validates it first.
+func WriteItinerary(c echo.Context, it *models.Itinerary) error {
+	it.UpdatedAt = time.Now().Unix()
+	j, err := json.Marshal(it)
+	if err != nil {
+		return err
+	}
+	return Client.Set(itineraryKey(it.TripID), j, pebble.Sync)
+}
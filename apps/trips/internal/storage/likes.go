@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/RoaringBitmap/roaring/roaring64"
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/cockroachdb/pebble"
+	"github.com/labstack/echo"
+)
+
+// likesMu keeps a user's bitmap and the trip's like counter moving together.
+var likesMu sync.Mutex
+
+// likesKey holds the roaring bitmap of numeric trip IDs a user has liked.
+func likesKey(userID string) []byte {
+	return models.MakeKey("likes", userID)
+}
+
+// likeCountKey holds the 8-byte big-endian number of users who liked a trip.
+func likeCountKey(tripID string) []byte {
+	return models.MakeKey("like_count", tripID)
+}
+
+// ReadLikes returns the numeric IDs of the trips userID has liked.
+func ReadLikes(userID string) (*roaring64.Bitmap, error) {
+	return BitmapForToken(likesKey(userID))
+}
+
+// LikeCount returns how many users have liked tripID.
+func LikeCount(tripID string) (int64, error) {
+	v, closer, err := Client.Get(likeCountKey(tripID))
+	if err == pebble.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer closer.Close()
+	return int64(getUint64(v)), nil
+}
+
+// SetLike adds or removes tripID from the user's likes. It is idempotent: the trip's
+// counter only moves when the bitmap actually changes.
+func SetLike(c echo.Context, userID, tripID string, liked bool) error {
+	numID, ok, err := Lookup(Client, tripID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return models.ErrTripNotFound
+	}
+
+	likesMu.Lock()
+	defer likesMu.Unlock()
+
+	rb, err := ReadLikes(userID)
+	if err != nil {
+		return err
+	}
+	if rb.Contains(numID) == liked {
+		return nil
+	}
+	count, err := LikeCount(tripID)
+	if err != nil {
+		return err
+	}
+	if liked {
+		rb.Add(numID)
+		count++
+	} else {
+		rb.Remove(numID)
+		count = max(count-1, 0)
+	}
+
+	batch := Client.NewBatch()
+	defer batch.Close()
+	if rb.IsEmpty() {
+		err = batch.Delete(likesKey(userID), pebble.Sync)
+	} else {
+		var blob []byte
+		if blob, err = encode(rb); err == nil {
+			err = batch.Set(likesKey(userID), blob, pebble.Sync)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err = batch.Set(likeCountKey(tripID), putUint64(uint64(count)), pebble.Sync); err != nil {
+		return err
+	}
+	return batch.Commit(pebble.Sync)
+}
@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/cockroachdb/pebble"
+	"github.com/labstack/echo"
+)
+
+// mediaMu serializes photo writes; every change rewrites the trip's (short) photo list
+// so positions stay dense and exactly one photo is the cover.
+var mediaMu sync.Mutex
+
+func mediaKey(tripID, mediaID string) []byte {
+	return models.MakeKey("media", tripID+":"+mediaID)
+}
+
+// mediaCoverKey points at the cover photo so trip reads don't scan the photo list.
+func mediaCoverKey(tripID string) []byte {
+	return models.MakeKey("media_cover", tripID)
+}
+
+// ListMedia returns a trip's photos in display order.
+func ListMedia(tripID string) ([]*models.Media, error) {
+	prefix := mediaKey(tripID, "")
+	iter, err := Client.NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: append(prefix[:len(prefix):len(prefix)], 0xff),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var list []*models.Media
+	for valid := iter.First(); valid; valid = iter.Next() {
+		var m models.Media
+		if err := json.Unmarshal(iter.Value(), &m); err != nil {
+			return nil, err
+		}
+		list = append(list, &m)
+	}
+	slices.SortStableFunc(list, func(a, b *models.Media) int { return a.Position - b.Position })
+	return list, iter.Error()
+}
+
+func ReadMedia(tripID, mediaID string) (*models.Media, error) {
+	v, closer, err := Client.Get(mediaKey(tripID, mediaID))
+	if err == pebble.ErrNotFound {
+		return nil, models.ErrMediaNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	var m models.Media
+	err = json.Unmarshal(v, &m)
+	return &m, err
+}
+
+// ReadCover returns the trip's cover photo, or nil when it has none.
+func ReadCover(tripID string) (*models.Media, error) {
+	v, closer, err := Client.Get(mediaCoverKey(tripID))
+	if err == pebble.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	mediaID := string(v)
+	closer.Close()
+	m, err := ReadMedia(tripID, mediaID)
+	if err == models.ErrMediaNotFound {
+		return nil, nil
+	}
+	return m, err
+}
+
+// saveMediaList renumbers positions, makes sure one photo is the cover and writes every
+// record plus the cover pointer.
+func saveMediaList(batch *pebble.Batch, tripID string, list []*models.Media) error {
+	cover := -1
+	for i, m := range list {
+		m.Position = i
+		if m.Cover && cover == -1 {
+			cover = i
+		}
+		m.Cover = false
+	}
+	if cover == -1 && len(list) > 0 {
+		cover = 0
+	}
+	for i, m := range list {
+		m.Cover = i == cover
+		j, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		if err = batch.Set(mediaKey(tripID, m.ID), j, pebble.Sync); err != nil {
+			return err
+		}
+	}
+	if cover == -1 {
+		return batch.Delete(mediaCoverKey(tripID), pebble.Sync)
+	}
+	return batch.Set(mediaCoverKey(tripID), []byte(list[cover].ID), pebble.Sync)
+}
+
+// AddMedia appends a photo to the end of the trip's list. The trip's first photo
+// becomes its cover.
+func AddMedia(c echo.Context, m *models.Media) error {
+	mediaMu.Lock()
+	defer mediaMu.Unlock()
+
+	list, err := ListMedia(m.TripID)
+	if err != nil {
+		return err
+	}
+	if len(list) >= models.MaxTripMedia {
+		return models.ErrTooManyMedia
+	}
+	if m.Cover {
+		for _, other := range list {
+			other.Cover = false
+		}
+	}
+	batch := Client.NewBatch()
+	defer batch.Close()
+	if err = saveMediaList(batch, m.TripID, append(list, m)); err != nil {
+		return err
+	}
+	return batch.Commit(pebble.Sync)
+}
+
+// UpdateMedia applies alt text, moves the photo to a new position, or makes it the cover.
+func UpdateMedia(c echo.Context, tripID, mediaID string, update models.MediaUpdate) (*models.Media, error) {
+	mediaMu.Lock()
+	defer mediaMu.Unlock()
+
+	list, err := ListMedia(tripID)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(list, func(m *models.Media) bool { return m.ID == mediaID })
+	if i == -1 {
+		return nil, models.ErrMediaNotFound
+	}
+	m := list[i]
+	if update.Alt != nil {
+		m.Alt = *update.Alt
+	}
+	if update.Cover != nil && *update.Cover {
+		for _, other := range list {
+			other.Cover = other == m
+		}
+	}
+	if update.Position != nil {
+		list = slices.Delete(list, i, i+1)
+		list = slices.Insert(list, min(*update.Position, len(list)), m)
+	}
+
+	batch := Client.NewBatch()
+	defer batch.Close()
+	if err = saveMediaList(batch, tripID, list); err != nil {
+		return nil, err
+	}
+	return m, batch.Commit(pebble.Sync)
+}
+
+// DeleteMedia removes the photo's record; the caller deletes its blobs. If it was the
+// cover, the next photo in order takes over.
+func DeleteMedia(c echo.Context, tripID, mediaID string) (*models.Media, error) {
+	mediaMu.Lock()
+	defer mediaMu.Unlock()
+
+	list, err := ListMedia(tripID)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(list, func(m *models.Media) bool { return m.ID == mediaID })
+	if i == -1 {
+		return nil, models.ErrMediaNotFound
+	}
+	m := list[i]
+
+	batch := Client.NewBatch()
+	defer batch.Close()
+	if err = batch.Delete(mediaKey(tripID, mediaID), pebble.Sync); err != nil {
+		return nil, err
+	}
+	if err = saveMediaList(batch, tripID, slices.Delete(list, i, i+1)); err != nil {
+		return nil, err
+	}
+	return m, batch.Commit(pebble.Sync)
+}
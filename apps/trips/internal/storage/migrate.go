@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"cmp"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/cockroachdb/pebble"
+	"github.com/labstack/echo"
+)
+
+// schemaVersionKey holds how many of migrations the database has had.
+var schemaVersionKey = []byte("schema_version")
+
+// migration brings data written by older releases up to date, staging its writes in
+// batch. It returns how many records it changed.
+type migration struct {
+	name string
+	run  func(c echo.Context, batch *pebble.Batch) (int, error)
+}
+
+// migrations run in order, each once; only ever append to them.
+var migrations = []migration{
+	{"price_minor_units", migratePriceMinorUnits},
+	{"waitlist_seq", migrateWaitlistSeq},
+}
+
+// Migrate runs the migrations the database hasn't had yet. Each commits together with
+// the new schema version, so a failed one runs again on the next start.
+func Migrate(c echo.Context) error {
+	applicationMu.Lock()
+	defer applicationMu.Unlock()
+
+	version, err := schemaVersion()
+	if err != nil {
+		return err
+	}
+	for i := version; i < len(migrations); i++ {
+		m := migrations[i]
+		batch := Client.NewIndexedBatch()
+		n, err := m.run(c, batch)
+		if err == nil {
+			err = batch.Set(schemaVersionKey, binary.BigEndian.AppendUint64(nil, uint64(i+1)), pebble.Sync)
+		}
+		if err == nil {
+			err = batch.Commit(pebble.Sync)
+		}
+		batch.Close()
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", m.name, err)
+		}
+		c.Logger().Infof("migration %s changed %d records", m.name, n)
+	}
+	return nil
+}
+
+func schemaVersion() (int, error) {
+	v, closer, err := Client.Get(schemaVersionKey)
+	if err == pebble.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer closer.Close()
+	return int(binary.BigEndian.Uint64(v)), nil
+}
+
+// migratePriceMinorUnits converts prices from before they were minor units: JSON numbers
+// in major units, such as 499.99. Their trips are reindexed too, as their price token
+// was the major-unit amount and they had no USD price.
+func migratePriceMinorUnits(c echo.Context, batch *pebble.Batch) (int, error) {
+	table, err := ExchangeRates()
+	if err != nil {
+		return 0, err
+	}
+	prefix := models.MakeKey("trip_id", "")
+	iter, err := Client.NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: append(prefix[:len(prefix):len(prefix)], 0xff),
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Close()
+
+	migrated := 0
+	for valid := iter.First(); valid; valid = iter.Next() {
+		var record map[string]json.RawMessage
+		if err = json.Unmarshal(iter.Value(), &record); err != nil {
+			return 0, err
+		}
+		var legacy struct {
+			ID       string   `json:"id"`
+			Price    *float64 `json:"price"`
+			Currency string   `json:"currency"`
+		}
+		if err = json.Unmarshal(iter.Value(), &legacy); err != nil {
+			return 0, err
+		}
+		if legacy.Price == nil {
+			continue
+		}
+		amount, err := models.ParseAmount(strconv.FormatFloat(*legacy.Price, 'f', -1, 64), legacy.Currency)
+		if err != nil {
+			return 0, fmt.Errorf("trip %s has price %v: %w", legacy.ID, *legacy.Price, err)
+		}
+		record["price"] = json.RawMessage(strconv.FormatInt(amount, 10))
+		upgraded, err := json.Marshal(record)
+		if err != nil {
+			return 0, err
+		}
+		var trip models.TripBase
+		if err = json.Unmarshal(upgraded, &trip); err != nil {
+			return 0, err
+		}
+
+		numID, ok, err := Lookup(Client, trip.ID)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			// the old token was the float64 formatted as Tokenize formats equality values
+			if err = removeTokens(batch, [][]byte{models.MakeKey("price", fmt.Sprintf("%v", *legacy.Price))}, numID); err != nil {
+				return 0, err
+			}
+			trip.NormalizePrice(table)
+			if err = writeTokens(c, batch, &trip, numID); err != nil {
+				return 0, err
+			}
+		}
+		tripJSON, err := json.Marshal(&trip)
+		if err != nil {
+			return 0, err
+		}
+		if err = batch.Set(models.MakeKey("trip_id", trip.ID), tripJSON, pebble.Sync); err != nil {
+			return 0, err
+		}
+		migrated++
+	}
+	return migrated, iter.Error()
+}
+
+// migrateWaitlistSeq numbers the applications waitlisted before WaitlistSeq existed in
+// the order they last joined their waitlist, as their audit trails record it.
+func migrateWaitlistSeq(c echo.Context, batch *pebble.Batch) (int, error) {
+	type waiting struct {
+		app     *models.Application
+		since   int64
+		applied uint64
+	}
+	waitlisted, err := BitmapForToken(models.ApplicationKey("status", string(models.ApplicationWaitlisted)))
+	if err != nil {
+		return 0, err
+	}
+	var queue []waiting
+	it := waitlisted.Iterator()
+	for it.HasNext() {
+		numID := it.Next()
+		ulid, ok, err := Reverse(Client, numID)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			continue
+		}
+		app, err := ReadApplication(c, ulid)
+		if err != nil {
+			return 0, err
+		}
+		entries, err := ReadAudit(app.ID)
+		if err != nil {
+			return 0, err
+		}
+		w := waiting{app: app, since: app.UpdatedAt, applied: numID}
+		for _, entry := range entries {
+			if entry.To == models.ApplicationWaitlisted {
+				w.since = entry.At
+			}
+		}
+		queue = append(queue, w)
+	}
+	slices.SortStableFunc(queue, func(a, b waiting) int {
+		if a.since != b.since {
+			return cmp.Compare(a.since, b.since)
+		}
+		return cmp.Compare(a.applied, b.applied)
+	})
+	for _, w := range queue {
+		if w.app.WaitlistSeq, err = nextWaitlistSeq(batch); err != nil {
+			return 0, err
+		}
+		j, err := json.Marshal(w.app)
+		if err != nil {
+			return 0, err
+		}
+		if err = batch.Set(applicationKey(w.app.ID), j, pebble.Sync); err != nil {
+			return 0, err
+		}
+	}
+	return len(queue), nil
+}
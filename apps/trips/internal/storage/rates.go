@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/cockroachdb/pebble"
+	"github.com/labstack/echo"
+)
+
+var (
+	ratesMu sync.RWMutex
+	// rates caches the stored exchange rate table; nil until first read
+	rates *models.ExchangeRates
+)
+
+var exchangeRatesKey = []byte("exchange_rates")
+
+// ExchangeRates returns the current exchange rate table. Before any rates are loaded it
+// only knows USD, so only USD and free trips are in the price index.
+func ExchangeRates() (*models.ExchangeRates, error) {
+	ratesMu.RLock()
+	cached := rates
+	ratesMu.RUnlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	ratesMu.Lock()
+	defer ratesMu.Unlock()
+	if rates != nil {
+		return rates, nil
+	}
+	table := &models.ExchangeRates{Base: models.NormalizedCurrency, Rates: map[string]float64{}}
+	v, closer, err := Client.Get(exchangeRatesKey)
+	switch err {
+	case nil:
+		err = json.Unmarshal(v, table)
+		closer.Close()
+		if err != nil {
+			return nil, err
+		}
+	case pebble.ErrNotFound:
+	default:
+		return nil, err
+	}
+	rates = table
+	return rates, nil
+}
+
+// normalizePrice sets the trip's USD price from the current rates before it is indexed.
+func normalizePrice(trip models.Trip) error {
+	table, err := ExchangeRates()
+	if err != nil {
+		return err
+	}
+	trip.NormalizePrice(table)
+	return nil
+}
+
+// SetExchangeRates replaces the exchange rate table and re-prices every trip whose USD
+// price moved, so the price index matches the new rates. It returns how many trips
+// were re-priced. Trips keep their revision sequence; their own price didn't change.
+func SetExchangeRates(c echo.Context, table *models.ExchangeRates) (int, error) {
+	applicationMu.Lock()
+	defer applicationMu.Unlock()
+
+	table.UpdatedAt = time.Now().Unix()
+	j, err := json.Marshal(table)
+	if err != nil {
+		return 0, err
+	}
+
+	batch := Client.NewIndexedBatch()
+	defer batch.Close()
+	if err = batch.Set(exchangeRatesKey, j, pebble.Sync); err != nil {
+		return 0, err
+	}
+
+	prefix := models.MakeKey("trip_id", "")
+	iter, err := Client.NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: append(prefix[:len(prefix):len(prefix)], 0xff),
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Close()
+
+	repriced := 0
+	for valid := iter.First(); valid; valid = iter.Next() {
+		var trip models.TripBase
+		if err = json.Unmarshal(iter.Value(), &trip); err != nil {
+			return 0, err
+		}
+		prev := trip.Tokenize()
+		if !trip.NormalizePrice(table) {
+			continue
+		}
+		numID, ok, err := Lookup(Client, trip.ID)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			continue
+		}
+		if err = removeTokens(batch, prev, numID); err != nil {
+			return 0, err
+		}
+		if err = writeTokens(c, batch, &trip, numID); err != nil {
+			return 0, err
+		}
+		tripJSON, err := json.Marshal(&trip)
+		if err != nil {
+			return 0, err
+		}
+		if err = batch.Set(models.MakeKey("trip_id", trip.ID), tripJSON, pebble.Sync); err != nil {
+			return 0, err
+		}
+		repriced++
+	}
+	if err = iter.Error(); err != nil {
+		return 0, err
+	}
+	if err = batch.Commit(pebble.Sync); err != nil {
+		return 0, err
+	}
+
+	ratesMu.Lock()
+	rates = table
+	ratesMu.Unlock()
+	return repriced, nil
+}
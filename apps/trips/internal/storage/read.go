@@ -1,7 +1,10 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/json"
+	"maps"
+	"slices"
 
 	"github.com/RoaringBitmap/roaring/roaring64"
 	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
@@ -33,23 +36,131 @@ func ReadTrip(c echo.Context, tripID string) (models.Trip, error) {
 		return nil, err
 	}
 	defer closer.Close()
-	err = json.Unmarshal(tripBytes, &trip)
+	if err = json.Unmarshal(tripBytes, &trip); err != nil {
+		return nil, err
+	}
+	count, err := LikeCount(tripID)
+	if err != nil {
+		return nil, err
+	}
+	trip.SetLikeCount(count)
+	cover, err := ReadCover(tripID)
+	trip.SetCover(cover)
 	return &trip, err
 }
 
-func ReadTrips(c echo.Context, tripID []string) ([]models.Trip, error) {
-	var trips []models.Trip
-	for _, tripID := range tripID {
-		trip, err := ReadTrip(c, tripID)
-		if err != nil {
-			return nil, err
+// ReadTrips reads the trips tripIDs from one snapshot, so they agree with each other
+// however the trips change meanwhile. It returns the trips in the order asked, each
+// once, and the IDs that have no trip.
+func ReadTrips(c echo.Context, tripIDs []string) ([]models.Trip, []string, error) {
+	snap := Client.NewSnapshot()
+	defer snap.Close()
+	return readTrips(snap, tripIDs)
+}
+
+// ReadIndexedTrips reads the trips with the numeric IDs ids, such as an index query's
+// matches, from one snapshot and in the same order. IDs with no trip are left out.
+func ReadIndexedTrips(c echo.Context, ids []uint64) ([]models.Trip, error) {
+	snap := Client.NewSnapshot()
+	defer snap.Close()
+	tripIDs, err := reverseAll(snap, ids)
+	if err != nil {
+		return nil, err
+	}
+	trips, _, err := readTrips(snap, tripIDs)
+	return trips, err
+}
+
+// readTrips is ReadTrips from r. The trips, their like counts, their cover pointers and
+// the covers are each read with one pass of one iterator.
+func readTrips(r pebble.Reader, tripIDs []string) ([]models.Trip, []string, error) {
+	keys := make(map[string][]byte, len(tripIDs))
+	for _, id := range tripIDs {
+		keys[id] = models.MakeKey("trip_id", id)
+	}
+	records, err := multiGet(r, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	likeKeys := make(map[string][]byte, len(records))
+	coverKeys := make(map[string][]byte, len(records))
+	for id := range records {
+		likeKeys[id] = likeCountKey(id)
+		coverKeys[id] = mediaCoverKey(id)
+	}
+	counts, err := multiGet(r, likeKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+	coverIDs, err := multiGet(r, coverKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+	mediaKeys := make(map[string][]byte, len(coverIDs))
+	for id, mediaID := range coverIDs {
+		mediaKeys[id] = mediaKey(id, string(mediaID))
+	}
+	covers, err := multiGet(r, mediaKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	trips := make([]models.Trip, 0, len(records))
+	missing := []string{}
+	seen := make(map[string]bool, len(tripIDs))
+	for _, id := range tripIDs {
+		if seen[id] {
+			continue
 		}
-		if trip == nil {
+		seen[id] = true
+		record, ok := records[id]
+		if !ok {
+			missing = append(missing, id)
 			continue
 		}
-		trips = append(trips, trip)
+		var trip models.TripBase
+		if err = json.Unmarshal(record, &trip); err != nil {
+			return nil, nil, err
+		}
+		trip.SetLikeCount(int64(getUint64(counts[id])))
+		if cover, ok := covers[id]; ok {
+			var m models.Media
+			if err = json.Unmarshal(cover, &m); err != nil {
+				return nil, nil, err
+			}
+			trip.SetCover(&m)
+		}
+		trips = append(trips, &trip)
+	}
+	return trips, missing, nil
+}
+
+// multiGet reads the value of every key in keys, a map from the caller's names for them,
+// by sorting them and seeking one iterator forward through them. Names whose key isn't
+// there are left out.
+func multiGet(r pebble.Reader, keys map[string][]byte) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+	names := slices.SortedFunc(maps.Keys(keys), func(a, b string) int {
+		return bytes.Compare(keys[a], keys[b])
+	})
+	last := keys[names[len(names)-1]]
+	iter, err := r.NewIter(&pebble.IterOptions{
+		LowerBound: keys[names[0]],
+		UpperBound: append(last[:len(last):len(last)], 0),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	for _, name := range names {
+		if iter.SeekGE(keys[name]) && bytes.Equal(iter.Key(), keys[name]) {
+			values[name] = slices.Clone(iter.Value())
+		}
 	}
-	return trips, nil
+	return values, iter.Error()
 }
 
 func GetIDsFromToken(c echo.Context, key []byte) ([]string, error) {
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+	"github.com/cockroachdb/pebble"
+	"github.com/labstack/echo"
+)
+
+// scheduleMu keeps materialization from creating the same occurrence twice.
+var scheduleMu sync.Mutex
+
+// ScheduleHorizon is how far ahead open-ended schedules create their instances.
+var ScheduleHorizon = 365 * 24 * time.Hour
+
+func scheduleKey(scheduleID string) []byte {
+	return models.MakeKey("schedule", scheduleID)
+}
+
+func ReadSchedule(scheduleID string) (*models.Schedule, error) {
+	v, closer, err := Client.Get(scheduleKey(scheduleID))
+	if err == pebble.ErrNotFound {
+		return nil, models.ErrScheduleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	var s models.Schedule
+	err = json.Unmarshal(v, &s)
+	return &s, err
+}
+
+func writeSchedule(s *models.Schedule) error {
+	j, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return Client.Set(scheduleKey(s.ID), j, pebble.Sync)
+}
+
+// ListSchedules returns an org's schedules, or every schedule when orgID is empty.
+func ListSchedules(orgID string) ([]*models.Schedule, error) {
+	prefix := scheduleKey("")
+	iter, err := Client.NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: append(prefix[:len(prefix):len(prefix)], 0xff),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var list []*models.Schedule
+	for valid := iter.First(); valid; valid = iter.Next() {
+		var s models.Schedule
+		if err := json.Unmarshal(iter.Value(), &s); err != nil {
+			return nil, err
+		}
+		if orgID == "" || s.OrgID == orgID {
+			list = append(list, &s)
+		}
+	}
+	return list, iter.Error()
+}
+
+// ScheduleInstances returns the trips a schedule has created, earliest first.
+func ScheduleInstances(c echo.Context, scheduleID string) ([]*models.TripBase, error) {
+	bm, err := BitmapForToken(models.MakeKey("schedule_id", scheduleID))
+	if err != nil {
+		return nil, err
+	}
+	var trips []*models.TripBase
+	it := bm.Iterator()
+	for it.HasNext() {
+		ulid, ok, err := Reverse(Client, it.Next())
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		trip, err := ReadTrip(c, ulid)
+		if err != nil {
+			return nil, err
+		}
+		trips = append(trips, trip.(*models.TripBase))
+	}
+	slices.SortFunc(trips, func(a, b *models.TripBase) int { return int(a.StartDate - b.StartDate) })
+	return trips, nil
+}
+
+// CreateSchedule saves a new schedule and creates its first instances.
+func CreateSchedule(c echo.Context, s *models.Schedule, r models.Recurrence, now time.Time) error {
+	scheduleMu.Lock()
+	defer scheduleMu.Unlock()
+
+	if err := writeSchedule(s); err != nil {
+		return err
+	}
+	_, err := materialize(c, s, r, now)
+	return err
+}
+
+// SaveSchedule stores changes to a schedule's template or cancellation.
+func SaveSchedule(c echo.Context, s *models.Schedule) error {
+	scheduleMu.Lock()
+	defer scheduleMu.Unlock()
+
+	s.UpdatedAt = time.Now().Unix()
+	return writeSchedule(s)
+}
+
+// materialize creates a trip for every occurrence after the schedule's watermark that
+// starts within the horizon. The schedule is saved after each trip so a crash part way
+// through never creates an occurrence twice.
+func materialize(c echo.Context, s *models.Schedule, r models.Recurrence, now time.Time) (int, error) {
+	horizon := now.Add(ScheduleHorizon)
+	created := 0
+	for t := range r.All(time.Unix(s.Template.StartDate, 0).UTC()) {
+		start := t.Unix()
+		if s.InstanceCount > 0 && start <= s.MaterializedThrough {
+			continue
+		}
+		if t.After(horizon) || s.InstanceCount >= models.MaxScheduleInstances || (s.CancelledFrom != 0 && start >= s.CancelledFrom) {
+			break
+		}
+		if err := CreateTrip(c, s.NewInstance(start)); err != nil {
+			return created, err
+		}
+		s.MaterializedThrough = start
+		s.InstanceCount++
+		if err := writeSchedule(s); err != nil {
+			return created, err
+		}
+		created++
+	}
+	return created, nil
+}
+
+// ExtendSchedules creates the instances that have come within the horizon since the
+// last run. It returns how many trips it created.
+func ExtendSchedules(c echo.Context, now time.Time) (int, error) {
+	scheduleMu.Lock()
+	defer scheduleMu.Unlock()
+
+	list, err := ListSchedules("")
+	if err != nil {
+		return 0, err
+	}
+	created := 0
+	for _, s := range list {
+		r, err := models.ParseRRule(s.RRule)
+		if err != nil {
+			c.Logger().Error(err)
+			continue
+		}
+		n, err := materialize(c, s, r, now)
+		created += n
+		if err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
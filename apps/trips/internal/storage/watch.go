@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/Taiterbase/vtrips/apps/trips/pkg/models"
+)
+
+// TripEventKind says what happened to the trip in a TripEvent.
+type TripEventKind int
+
+const (
+	TripCreated TripEventKind = iota + 1
+	TripUpdated
+	TripDeleted
+)
+
+// TripEvent is a committed change to a trip: the trip as stored after it, or as it was
+// before a deletion. Re-pricing after an exchange-rate update isn't an event; price_usd
+// is derived.
+type TripEvent struct {
+	Kind TripEventKind
+	Trip models.Trip
+}
+
+// watchBuffer is how many events a watcher may fall behind by before it is cut off.
+const watchBuffer = 64
+
+var (
+	watchMu  sync.Mutex
+	watchers = map[chan TripEvent]struct{}{}
+)
+
+// WatchTrips subscribes to trip events from now on. The channel is closed after stop is
+// called, or when the watcher falls behind by more than watchBuffer events; writers
+// never wait for watchers.
+func WatchTrips() (events <-chan TripEvent, stop func()) {
+	ch := make(chan TripEvent, watchBuffer)
+	watchMu.Lock()
+	watchers[ch] = struct{}{}
+	watchMu.Unlock()
+	return ch, func() {
+		watchMu.Lock()
+		defer watchMu.Unlock()
+		if _, ok := watchers[ch]; ok {
+			delete(watchers, ch)
+			close(ch)
+		}
+	}
+}
+
+// publishTrip tells every watcher about a committed change.
+func publishTrip(kind TripEventKind, trip models.Trip) {
+	watchMu.Lock()
+	defer watchMu.Unlock()
+	for ch := range watchers {
+		select {
+		case ch <- TripEvent{Kind: kind, Trip: trip}:
+		default:
+			delete(watchers, ch)
+			close(ch)
+		}
+	}
+}
@@ -27,14 +27,19 @@ func encode(rb *roaring64.Bitmap) ([]byte, error) {
 	return rb.MarshalBinary()
 }
 
-func writeTokens(c echo.Context, batch *pebble.Batch, trip models.Trip, numericID uint64) (err error) {
+// tokenizer is anything whose postings live in the bitmap index: trips and applications.
+type tokenizer interface {
+	Tokenize() [][]byte
+}
+
+func writeTokens(c echo.Context, batch *pebble.Batch, item tokenizer, numericID uint64) (err error) {
 	defer func() {
 		if err != nil {
 			c.Logger().Errorj(log.JSON{"err": err})
 		}
 	}()
 
-	for _, key := range trip.Tokenize() {
+	for _, key := range item.Tokenize() {
 		existing, closer, err := batch.Get(key)
 		if err != nil && err != pebble.ErrNotFound {
 			return err
@@ -80,6 +85,9 @@ func CreateTrip(c echo.Context, trip models.Trip) error {
 	if err != nil {
 		return err
 	}
+	if err = normalizePrice(trip); err != nil {
+		return err
+	}
 	batch := Client.NewIndexedBatch()
 	defer batch.Close()
 
@@ -95,7 +103,11 @@ func CreateTrip(c echo.Context, trip models.Trip) error {
 	if err = writeTokens(c, batch, trip, numID); err != nil {
 		return err
 	}
-	return batch.Commit(pebble.Sync)
+	if err = batch.Commit(pebble.Sync); err != nil {
+		return err
+	}
+	publishTrip(TripCreated, trip)
+	return nil
 }
 
 // DeleteTrip removes the trip object and its posting-list entries.
@@ -127,6 +139,9 @@ func DeleteTrip(c echo.Context, trip models.Trip) error {
 
 	for _, tk := range oldTrip.Tokenize() {
 		data, closer, err := batch.Get(tk)
+		if err == pebble.ErrNotFound {
+			continue // indexed before this token existed
+		}
 		if err != nil {
 			return err
 		}
@@ -161,12 +176,28 @@ func DeleteTrip(c echo.Context, trip models.Trip) error {
 	if err = batch.Delete(keyTrip, nil); err != nil {
 		return err
 	}
-	return batch.Commit(pebble.Sync)
+	// likers' bitmaps keep the stale ID; GetLikes skips trips that no longer exist
+	if err = batch.Delete(likeCountKey(ulid), nil); err != nil {
+		return err
+	}
+	if err = batch.Delete(itineraryKey(ulid), nil); err != nil {
+		return err
+	}
+	if err = batch.Commit(pebble.Sync); err != nil {
+		return err
+	}
+	publishTrip(TripDeleted, &oldTrip)
+	return nil
 }
 
 // UpdateTrip overwrites the trip JSON and refreshes all bitmap tokens.
 // Simplest strategy: delete old postings then re-add new ones.
+// The stored seat count wins over the caller's; only application transitions move it.
+// The revision sequence always moves on from the stored one.
 func UpdateTrip(c echo.Context, trip models.Trip) error {
+	applicationMu.Lock()
+	defer applicationMu.Unlock()
+
 	batch := Client.NewIndexedBatch()
 	defer batch.Close()
 
@@ -193,6 +224,11 @@ func UpdateTrip(c echo.Context, trip models.Trip) error {
 	if err = json.Unmarshal(prevBytes, &prev); err != nil {
 		return err
 	}
+	trip.SetSeatsTaken(prev.SeatsTaken)
+	trip.SetSequence(prev.Sequence + 1)
+	if err = normalizePrice(trip); err != nil {
+		return err
+	}
 
 	for _, tk := range prev.Tokenize() {
 		data, closeFn, err := batch.Get(tk)
@@ -235,5 +271,9 @@ func UpdateTrip(c echo.Context, trip models.Trip) error {
 	if err = batch.Set(keyTrip, newJSON, pebble.Sync); err != nil {
 		return err
 	}
-	return batch.Commit(pebble.Sync)
+	if err = batch.Commit(pebble.Sync); err != nil {
+		return err
+	}
+	publishTrip(TripUpdated, trip)
+	return nil
 }
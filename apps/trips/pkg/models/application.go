@@ -0,0 +1,187 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+var (
+	ErrApplicationNotFound = fmt.Errorf("Application not found")
+	ErrAlreadyApplied      = fmt.Errorf("Already applied to this trip")
+	ErrTripFull            = fmt.Errorf("Trip has reached its volunteer limit")
+	ErrTripNotOpen         = fmt.Errorf("Trip is not accepting applications")
+	ErrInvalidTransition   = fmt.Errorf("Application cannot move to that status")
+	ErrMissingAnswer       = fmt.Errorf("A required question was not answered")
+)
+
+type ApplicationStatus string
+
+const (
+	ApplicationPending    ApplicationStatus = "pending"
+	ApplicationApproved   ApplicationStatus = "approved"
+	ApplicationRejected   ApplicationStatus = "rejected"
+	ApplicationWaitlisted ApplicationStatus = "waitlisted"
+	ApplicationWithdrawn  ApplicationStatus = "withdrawn"
+	// ApplicationOffered holds a freed seat for a promoted waitlisted volunteer until they
+	// accept or the offer lapses
+	ApplicationOffered ApplicationStatus = "offered"
+	ApplicationLapsed  ApplicationStatus = "lapsed"
+)
+
+// applicationTransitions lists the statuses each status may move to. Rejected, withdrawn
+// and lapsed are final; the volunteer can apply again afterwards.
+var applicationTransitions = map[ApplicationStatus][]ApplicationStatus{
+	ApplicationPending:    {ApplicationApproved, ApplicationRejected, ApplicationWaitlisted, ApplicationWithdrawn},
+	ApplicationWaitlisted: {ApplicationApproved, ApplicationRejected, ApplicationWithdrawn, ApplicationOffered},
+	ApplicationOffered:    {ApplicationApproved, ApplicationRejected, ApplicationWithdrawn, ApplicationLapsed},
+	ApplicationApproved:   {ApplicationRejected, ApplicationWaitlisted, ApplicationWithdrawn},
+}
+
+// CanTransition reports whether an application may move from s to next.
+func (s ApplicationStatus) CanTransition(next ApplicationStatus) bool {
+	for _, allowed := range applicationTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether the application still holds or may get a place on the trip.
+func (s ApplicationStatus) Active() bool {
+	return s == ApplicationPending || s == ApplicationWaitlisted || s == ApplicationApproved || s == ApplicationOffered
+}
+
+// HoldsSeat reports whether an application in this status counts against the volunteer limit.
+func (s ApplicationStatus) HoldsSeat() bool {
+	return s == ApplicationApproved || s == ApplicationOffered
+}
+
+// Question is an org-defined prompt volunteers answer when applying to a trip.
+type Question struct {
+	ID       string `json:"id" validate:"required,max=64"`
+	Prompt   string `json:"prompt" validate:"required,max=500"`
+	Required bool   `json:"required"`
+}
+
+type Answer struct {
+	QuestionID string `json:"question_id" validate:"required"`
+	Answer     string `json:"answer" validate:"max=5000"`
+}
+
+// Application is a volunteer's request to join a trip. Its tokens live under the
+// "application/" prefix so they don't collide with trip postings.
+type Application struct {
+	ID        string            `json:"id"`
+	TripID    string            `json:"trip_id" validate:"required" index:"equality"`
+	OrgID     string            `json:"org_id" validate:"required" index:"equality"`
+	UserID    string            `json:"user_id" validate:"required" index:"equality"`
+	Username  string            `json:"username"`
+	Status    ApplicationStatus `json:"status" index:"equality"`
+	Answers   []Answer          `json:"answers" validate:"dive"`
+	Note      string            `json:"note"`
+	DecidedBy string            `json:"decided_by,omitempty"`
+	DecidedAt int64             `json:"decided_at,omitempty"`
+	// OfferExpiresAt is when an offered seat passes to the next waitlisted volunteer
+	OfferExpiresAt int64 `json:"offer_expires_at,omitempty"`
+	// WaitlistSeq is the sequence assigned on joining the waitlist; the smallest (longest
+	// waiting) is promoted first
+	WaitlistSeq uint64 `json:"waitlist_seq,omitempty"`
+
+	CreatedAt int64 `json:"created_at" validate:"required" index:"time"`
+	UpdatedAt int64 `json:"updated_at" index:"time"`
+}
+
+// NewApplication starts a pending application from userID to trip.
+func NewApplication(trip Trip, userID, username string) *Application {
+	now := time.Now().Unix()
+	return &Application{
+		ID:        ulid.Make().String(),
+		TripID:    trip.GetID(),
+		OrgID:     trip.GetOrgID(),
+		UserID:    userID,
+		Username:  username,
+		Status:    ApplicationPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func (a *Application) Validate() error {
+	return validation.Struct(a)
+}
+
+// CheckAnswers verifies every required question has a non-empty answer and drops
+// answers to questions the trip doesn't ask.
+func (a *Application) CheckAnswers(questions []Question) error {
+	given := make(map[string]string, len(a.Answers))
+	for _, answer := range a.Answers {
+		given[answer.QuestionID] = answer.Answer
+	}
+	answers := make([]Answer, 0, len(questions))
+	for _, q := range questions {
+		text, ok := given[q.ID]
+		if q.Required && text == "" {
+			return ErrMissingAnswer
+		}
+		if ok {
+			answers = append(answers, Answer{QuestionID: q.ID, Answer: text})
+		}
+	}
+	a.Answers = answers
+	return nil
+}
+
+// ApplicationKey builds the bitmap token for an application field query.
+func ApplicationKey(field, value string) []byte {
+	return MakeKey("application/"+field, value)
+}
+
+func (a *Application) Tokenize() [][]byte {
+	tokens := [][]byte{}
+	typ := reflect.TypeOf(*a)
+	v := reflect.ValueOf(*a)
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		switch field.Tag.Get("index") {
+		case "time":
+			value := GetDailyBucket(v.Field(i).Int())
+			tokens = append(tokens, ApplicationKey(field.Tag.Get("json"), fmt.Sprintf("%v", value)))
+		case "equality":
+			value := v.Field(i).Interface()
+			if value == nil || value == "" {
+				continue
+			}
+			tokens = append(tokens, ApplicationKey(field.Tag.Get("json"), fmt.Sprintf("%v", value)))
+		}
+	}
+	return tokens
+}
+
+// AuditEntry records one state change on an application, who made it and why.
+type AuditEntry struct {
+	ID            string            `json:"id"`
+	ApplicationID string            `json:"application_id"`
+	ActorID       string            `json:"actor_id"`
+	Action        string            `json:"action"`
+	From          ApplicationStatus `json:"from,omitempty"`
+	To            ApplicationStatus `json:"to"`
+	Note          string            `json:"note,omitempty"`
+	At            int64             `json:"at"`
+}
+
+func NewAuditEntry(app *Application, actorID, action string, from ApplicationStatus, note string) *AuditEntry {
+	return &AuditEntry{
+		ID:            ulid.Make().String(),
+		ApplicationID: app.ID,
+		ActorID:       actorID,
+		Action:        action,
+		From:          from,
+		To:            app.Status,
+		Note:          note,
+		At:            time.Now().Unix(),
+	}
+}
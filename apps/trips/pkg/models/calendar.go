@@ -0,0 +1,13 @@
+package models
+
+import "fmt"
+
+var ErrCalendarNotFound = fmt.Errorf("Calendar feed not found")
+
+// CalendarFeed is a volunteer's private calendar subscription. The token in its URL is
+// the only credential calendar apps send, so it can be reset to cut off old links.
+type CalendarFeed struct {
+	Token     string `json:"token"`
+	UserID    string `json:"user_id"`
+	CreatedAt int64  `json:"created_at"`
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+var (
+	ErrInvalidCurrency = fmt.Errorf("Currency must be an ISO 4217 code such as USD")
+	ErrInvalidRates    = fmt.Errorf("Exchange rates need a base currency and a positive rate per ISO 4217 code")
+	ErrNoExchangeRate  = fmt.Errorf("No exchange rate for that currency")
+	ErrInvalidPrice    = fmt.Errorf("Prices must be amounts of zero or more, such as 500 or 499.99")
+)
+
+// NormalizedCurrency is the currency every price is converted into for the price index.
+const NormalizedCurrency = "USD"
+
+// minorUnitExponents are the ISO 4217 currencies whose minor unit isn't a hundredth.
+var minorUnitExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// MinorUnitExponent is how many decimal places the currency's minor unit has: 2 for
+// USD cents, 0 for JPY.
+func MinorUnitExponent(currency string) int {
+	if exp, ok := minorUnitExponents[currency]; ok {
+		return exp
+	}
+	return 2
+}
+
+// ValidCurrency reports whether code is an ISO 4217 currency code.
+func ValidCurrency(code string) bool {
+	return validation.Var(code, "iso4217") == nil
+}
+
+// ParseAmount reads an amount written in major units, such as "499.99", as minor units
+// of currency.
+func ParseAmount(s, currency string) (int64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, ErrInvalidPrice
+	}
+	return int64(math.Round(f * math.Pow10(MinorUnitExponent(currency)))), nil
+}
+
+// FormatAmount writes minor units of currency in major units, with the currency's
+// decimal places: 49999 USD is "499.99".
+func FormatAmount(amount int64, currency string) string {
+	exp := MinorUnitExponent(currency)
+	return strconv.FormatFloat(float64(amount)/math.Pow10(exp), 'f', exp, 64)
+}
+
+// ExchangeRates is a table of how much of each currency one unit of Base buys.
+type ExchangeRates struct {
+	Base      string             `json:"base" validate:"required,iso4217"`
+	Rates     map[string]float64 `json:"rates" validate:"dive,keys,iso4217,endkeys,gt=0"`
+	UpdatedAt int64              `json:"updated_at"`
+}
+
+// Validate checks every code is ISO 4217 and every rate positive.
+func (r *ExchangeRates) Validate() error {
+	if err := validation.Struct(r); err != nil {
+		return ErrInvalidRates
+	}
+	return nil
+}
+
+func (r *ExchangeRates) rate(currency string) (float64, bool) {
+	if currency == r.Base {
+		return 1, true
+	}
+	rate, ok := r.Rates[currency]
+	return rate, ok
+}
+
+// Convert turns minor units of one currency into the nearest minor unit of another. It
+// is false when either currency has no rate. Nothing costs nothing in any currency.
+func (r *ExchangeRates) Convert(amount int64, from, to string) (int64, bool) {
+	if amount == 0 {
+		return 0, true
+	}
+	if from == to {
+		return amount, from != ""
+	}
+	fromRate, ok := r.rate(from)
+	if !ok {
+		return 0, false
+	}
+	toRate, ok := r.rate(to)
+	if !ok {
+		return 0, false
+	}
+	major := float64(amount) / math.Pow10(MinorUnitExponent(from)) / fromRate * toRate
+	return int64(math.Round(major * math.Pow10(MinorUnitExponent(to)))), true
+}
+
+// NormalizePrice sets the trip's PriceUSD from the table, or clears it when the trip's
+// currency has no rate. It reports whether PriceUSD changed.
+func (t *TripBase) NormalizePrice(rates *ExchangeRates) bool {
+	prev := t.PriceUSD
+	t.PriceUSD = nil
+	if usd, ok := rates.Convert(t.Price, t.Currency, NormalizedCurrency); ok {
+		t.PriceUSD = &usd
+	}
+	if prev == nil || t.PriceUSD == nil {
+		return prev != t.PriceUSD
+	}
+	return *prev != *t.PriceUSD
+}
+
+// priceBands are the lower bounds, in USD cents, of the price index's postings. Range
+// queries read the bands they overlap and check each trip's exact price.
+var priceBands = []int64{
+	0, 25_00, 50_00, 100_00, 250_00, 500_00, 1000_00, 2500_00, 5000_00, 10000_00, 25000_00,
+}
+
+func priceBand(usd int64) int64 {
+	i, found := slices.BinarySearch(priceBands, usd)
+	if !found {
+		i--
+	}
+	return priceBands[max(i, 0)]
+}
+
+func priceToken(field string, band int64) []byte {
+	return MakeKey(field, strconv.FormatInt(band, 10))
+}
+
+// PriceTokens are the price index postings that cover USD prices from lo to hi cents;
+// hi below zero means no upper bound.
+func PriceTokens(lo, hi int64) [][]byte {
+	var tokens [][]byte
+	for _, band := range priceBands {
+		if hi >= 0 && band > hi {
+			break
+		}
+		if band >= priceBand(lo) {
+			tokens = append(tokens, priceToken("price_usd", band))
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// The trip enums are defined in enums.json; edit that and regenerate.
+//go:generate go run ../../cmd/enumgen -in enums.json -out enums_gen.go
+
+// TripStatusFull is never stored or accepted as a status; it is indexed for trips whose
+// seats are all taken, so status=full filters on it.
+const TripStatusFull TripStatus = "full"
+
+// EnumSchema describes an enum for API clients: the names it accepts, with labels for
+// showing them.
+type EnumSchema struct {
+	Field string `json:"field"`
+	// Default is the name new trips start with
+	Default string      `json:"default"`
+	Values  []EnumValue `json:"values"`
+}
+
+type EnumValue struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+}
+
+// Names lists the names the enum accepts.
+func (s EnumSchema) Names() []string {
+	names := make([]string, len(s.Values))
+	for i, v := range s.Values {
+		names[i] = v.Name
+	}
+	return names
+}
+
+// EnumFor returns the enum usually found in a JSON field, such as "trip_type".
+func EnumFor(field string) (EnumSchema, bool) {
+	i := slices.IndexFunc(Enums, func(s EnumSchema) bool { return s.Field == field })
+	if i == -1 {
+		return EnumSchema{}, false
+	}
+	return Enums[i], true
+}
+
+// EnumError is a name an enum doesn't have.
+type EnumError struct {
+	Field   string
+	Value   string
+	Allowed []string
+}
+
+func (e *EnumError) Error() string {
+	return fmt.Sprintf("%s must be one of %s, not %q", e.Field, strings.Join(e.Allowed, ", "), e.Value)
+}
+
+// InvalidField names the field and the check it failed, for error responses.
+func (e *EnumError) InvalidField() (field, rule string) {
+	return e.Field, "oneof"
+}
+
+// CheckFilter rejects a query filter value no trip can have for an enum field. Status
+// filters may also ask for full trips.
+func CheckFilter(field, value string) error {
+	s, ok := EnumFor(field)
+	if !ok || slices.Contains(s.Names(), value) {
+		return nil
+	}
+	if field == "status" && value == string(TripStatusFull) {
+		return nil
+	}
+	return &EnumError{Field: field, Value: value, Allowed: s.Names()}
+}
@@ -0,0 +1,242 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidBBox = fmt.Errorf("bbox must be west,south,east,north in degrees")
+	ErrInvalidZoom = fmt.Errorf("zoom must be a whole number from 0 to %d", MaxZoom)
+)
+
+const (
+	MaxZoom = 22
+	// MaxClusterZoom is the zoom from which trips are always sent as their own points
+	MaxClusterZoom = 16
+	// clusterCellsPerTile splits each 256px map tile into a 4x4 grid, so trips closer than
+	// about 64px on screen share a cluster
+	clusterCellsPerTile = 4
+	// maxBBoxCells bounds how many geo index postings one bounding box reads
+	maxBBoxCells = 128
+)
+
+// geoCellLevels are the sizes, in degrees, of the grid cells trips are indexed under.
+// Large boxes read a few coarse cells and small ones a few fine cells.
+var geoCellLevels = []float64{1, 5, 30}
+
+// HasCoordinates reports whether the trip has been placed on the map. 0,0 is in the
+// Gulf of Guinea and is taken to mean unset.
+func (t *TripBase) HasCoordinates() bool {
+	return t.Latitude != 0 || t.Longitude != 0
+}
+
+func geoCell(level, lat, lon float64) (row, col int) {
+	rows, cols := int(180/level), int(360/level)
+	row = min(max(int(math.Floor((lat+90)/level)), 0), rows-1)
+	col = min(max(int(math.Floor((lon+180)/level)), 0), cols-1)
+	return row, col
+}
+
+func geoToken(level float64, row, col int) []byte {
+	return MakeKey("geo", fmt.Sprintf("%g:%d:%d", level, row, col))
+}
+
+// GeoTokens are the geo index postings for a point, one per cell level.
+func GeoTokens(lat, lon float64) [][]byte {
+	var tokens [][]byte
+	for _, level := range geoCellLevels {
+		row, col := geoCell(level, lat, lon)
+		tokens = append(tokens, geoToken(level, row, col))
+	}
+	return tokens
+}
+
+// BBox is a GeoJSON bounding box. West is greater than East for boxes that cross the
+// antimeridian.
+type BBox struct {
+	West, South, East, North float64
+}
+
+// ParseBBox reads "west,south,east,north", the order GeoJSON and map libraries use.
+func ParseBBox(s string) (BBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return BBox{}, ErrInvalidBBox
+	}
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil || math.IsNaN(f) {
+			return BBox{}, ErrInvalidBBox
+		}
+		v[i] = f
+	}
+	b := BBox{West: v[0], South: v[1], East: v[2], North: v[3]}
+	// maps scrolled past the antimeridian report longitudes beyond ±180
+	if b.East-b.West >= 360 {
+		b.West, b.East = -180, 180
+	}
+	b.West, b.East = wrapLongitude(b.West), wrapLongitude(b.East)
+	b.South, b.North = max(b.South, -90), min(b.North, 90)
+	if b.South > b.North {
+		return BBox{}, ErrInvalidBBox
+	}
+	return b, nil
+}
+
+func wrapLongitude(lon float64) float64 {
+	if lon >= -180 && lon <= 180 {
+		return lon
+	}
+	return math.Mod(math.Mod(lon+180, 360)+360, 360) - 180
+}
+
+// Contains reports whether the point is inside the box, edges included.
+func (b BBox) Contains(lat, lon float64) bool {
+	if lat < b.South || lat > b.North {
+		return false
+	}
+	if b.West <= b.East {
+		return lon >= b.West && lon <= b.East
+	}
+	return lon >= b.West || lon <= b.East
+}
+
+// Tokens are the geo index postings that together cover the box, at the finest level
+// that needs no more than maxBBoxCells of them.
+func (b BBox) Tokens() [][]byte {
+	for i, level := range geoCellLevels {
+		rowLo, colLo := geoCell(level, b.South, b.West)
+		rowHi, colHi := geoCell(level, b.North, b.East)
+		var cols []int
+		if colLo <= colHi && b.West <= b.East {
+			for col := colLo; col <= colHi; col++ {
+				cols = append(cols, col)
+			}
+		} else {
+			for col := colLo; col < int(360/level); col++ {
+				cols = append(cols, col)
+			}
+			for col := 0; col <= colHi; col++ {
+				cols = append(cols, col)
+			}
+		}
+		if (rowHi-rowLo+1)*len(cols) > maxBBoxCells && i < len(geoCellLevels)-1 {
+			continue
+		}
+		var tokens [][]byte
+		for row := rowLo; row <= rowHi; row++ {
+			for _, col := range cols {
+				tokens = append(tokens, geoToken(level, row, col))
+			}
+		}
+		return tokens
+	}
+	return nil
+}
+
+// FeatureCollection is a GeoJSON (RFC 7946) collection of points.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature is one trip, or a cluster of nearby trips. Cluster features carry the bbox of
+// their trips so a map can zoom to fit them.
+type Feature struct {
+	Type       string    `json:"type"`
+	BBox       []float64 `json:"bbox,omitempty"`
+	Geometry   Point     `json:"geometry"`
+	Properties any       `json:"properties"`
+}
+
+type Point struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// ClusterProperties describe a cluster feature.
+type ClusterProperties struct {
+	Cluster    bool `json:"cluster"`
+	PointCount int  `json:"point_count"`
+	// TripIDs lists the clustered trips, up to maxClusterIDs of them
+	TripIDs []string `json:"trip_ids"`
+}
+
+const maxClusterIDs = 20
+
+func newPoint(lat, lon float64) Point {
+	return Point{Type: "Point", Coordinates: [2]float64{lon, lat}}
+}
+
+// Public replaces the trips in fc's features with their public view, for anonymous
+// visitors.
+func (fc FeatureCollection) Public() FeatureCollection {
+	for i, f := range fc.Features {
+		if t, ok := f.Properties.(*TripBase); ok {
+			fc.Features[i].Properties = t.Public()
+		}
+	}
+	return fc
+}
+
+// NewFeatureCollection maps trips that have coordinates, with each trip's JSON as the
+// feature's properties. With zoom set (0 or more) and below MaxClusterZoom, trips in the
+// same grid cell at that zoom become one cluster feature at their centroid.
+func NewFeatureCollection(trips []*TripBase, zoom int) FeatureCollection {
+	fc := FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+	if zoom < 0 || zoom >= MaxClusterZoom {
+		for _, t := range trips {
+			if t.HasCoordinates() {
+				fc.Features = append(fc.Features, Feature{Type: "Feature", Geometry: newPoint(t.Latitude, t.Longitude), Properties: t})
+			}
+		}
+		return fc
+	}
+
+	cell := 360 / math.Exp2(float64(zoom)) / clusterCellsPerTile
+	type group struct{ trips []*TripBase }
+	var (
+		order  []string
+		groups = map[string]*group{}
+	)
+	for _, t := range trips {
+		if !t.HasCoordinates() {
+			continue
+		}
+		key := fmt.Sprintf("%d:%d", int(math.Floor((t.Latitude+90)/cell)), int(math.Floor((t.Longitude+180)/cell)))
+		g, ok := groups[key]
+		if !ok {
+			g = &group{}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.trips = append(g.trips, t)
+	}
+	for _, key := range order {
+		members := groups[key].trips
+		if len(members) == 1 {
+			t := members[0]
+			fc.Features = append(fc.Features, Feature{Type: "Feature", Geometry: newPoint(t.Latitude, t.Longitude), Properties: t})
+			continue
+		}
+		var latSum, lonSum float64
+		bbox := []float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+		props := ClusterProperties{Cluster: true, PointCount: len(members)}
+		for _, t := range members {
+			latSum += t.Latitude
+			lonSum += t.Longitude
+			bbox[0], bbox[1] = min(bbox[0], t.Longitude), min(bbox[1], t.Latitude)
+			bbox[2], bbox[3] = max(bbox[2], t.Longitude), max(bbox[3], t.Latitude)
+			if len(props.TripIDs) < maxClusterIDs {
+				props.TripIDs = append(props.TripIDs, t.ID)
+			}
+		}
+		n := float64(len(members))
+		fc.Features = append(fc.Features, Feature{Type: "Feature", BBox: bbox, Geometry: newPoint(latSum/n, lonSum/n), Properties: props})
+	}
+	return fc
+}
@@ -0,0 +1,197 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	validate "github.com/go-playground/validator/v10"
+)
+
+// MaxImportRows caps how many trips one import can create or update.
+const MaxImportRows = 5000
+
+var (
+	ErrImportFormat   = fmt.Errorf("Format must be csv or ndjson")
+	ErrImportTooLarge = fmt.Errorf("Imports are limited to %d rows", MaxImportRows)
+	ErrImportColumn   = fmt.Errorf("Column mappings look like map=Heading=column, naming a trip column")
+)
+
+// ImportDateLayout is how CSV imports and exports write trip dates. Imports also accept
+// Unix seconds, as the JSON API uses.
+const ImportDateLayout = "2006-01-02"
+
+// TripColumns are the trip fields a CSV import can set and an export writes, in column
+// order. A row with an id updates that trip; otherwise external_id picks the trip to
+// update, if the org has one. Prices are in minor units, as in the JSON API.
+var TripColumns = []string{
+	"id", "external_id", "name", "description", "mission",
+	"trip_type", "housing_type", "privacy_type", "status",
+	"volunteer_limit", "price", "currency",
+	"city", "country", "latitude", "longitude",
+	"start_date", "end_date",
+}
+
+// columnAliases are spreadsheet headings mapped to the trip column they usually mean.
+var columnAliases = map[string]string{
+	"title":    "name",
+	"type":     "trip_type",
+	"housing":  "housing_type",
+	"privacy":  "privacy_type",
+	"capacity": "volunteer_limit",
+	"seats":    "volunteer_limit",
+	"start":    "start_date",
+	"end":      "end_date",
+	"lat":      "latitude",
+	"lng":      "longitude",
+	"lon":      "longitude",
+}
+
+// ImportError is a problem with one row of an import. Row is the line in the file, so a
+// CSV's first trip is row 2.
+type ImportError struct {
+	Row    int    `json:"row"`
+	Column string `json:"column,omitempty"`
+	Error  string `json:"error"`
+}
+
+// MatchColumn maps a spreadsheet heading such as "Start Date" to its trip column, or ""
+// when nothing matches.
+func MatchColumn(heading string) string {
+	name := strings.ToLower(strings.TrimSpace(heading))
+	name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+	if slices.Contains(TripColumns, name) {
+		return name
+	}
+	return columnAliases[name]
+}
+
+// CSVValue converts a CSV cell into the JSON value of its trip column.
+func CSVValue(column, cell string) (any, error) {
+	cell = strings.TrimSpace(cell)
+	switch column {
+	case "volunteer_limit":
+		n, err := strconv.Atoi(cell)
+		if err != nil {
+			return nil, fmt.Errorf("must be a whole number")
+		}
+		return n, nil
+	case "price":
+		n, err := strconv.ParseInt(cell, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a whole number of minor units, such as cents")
+		}
+		return n, nil
+	case "latitude", "longitude":
+		f, err := strconv.ParseFloat(cell, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return f, nil
+	case "start_date", "end_date":
+		if t, err := time.Parse(ImportDateLayout, cell); err == nil {
+			return t.Unix(), nil
+		}
+		n, err := strconv.ParseInt(cell, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a date like 2026-03-01")
+		}
+		return n, nil
+	}
+	return cell, nil
+}
+
+// CheckTripFields reports enum values no trip column accepts, all at once where
+// ApplyTripEdit would stop at the first. The errors have no row; the caller fills it in.
+func CheckTripFields(fields map[string]any) []ImportError {
+	var errs []ImportError
+	for _, enum := range Enums {
+		v, ok := fields[enum.Field]
+		if !ok {
+			continue
+		}
+		if s, _ := v.(string); !slices.Contains(enum.Names(), s) {
+			err := &EnumError{Field: enum.Field, Value: s, Allowed: enum.Names()}
+			errs = append(errs, ImportError{Column: enum.Field, Error: err.Error()})
+		}
+	}
+	slices.SortFunc(errs, func(a, b ImportError) int { return strings.Compare(a.Column, b.Column) })
+	return errs
+}
+
+// RowErrors explains why ApplyTripEdit rejected a row, one error per bad column where
+// that can be told.
+func RowErrors(row int, err error) []ImportError {
+	var (
+		invalid validate.ValidationErrors
+		typeErr *json.UnmarshalTypeError
+		enumErr *EnumError
+		errs    []ImportError
+	)
+	switch {
+	case errors.As(err, &enumErr):
+		errs = append(errs, ImportError{Row: row, Column: enumErr.Field, Error: enumErr.Error()})
+	case errors.As(err, &invalid):
+		for _, fe := range invalid {
+			errs = append(errs, ImportError{Row: row, Column: jsonName(fe.StructNamespace()), Error: fmt.Sprintf("failed %s validation", fe.Tag())})
+		}
+	case errors.As(err, &typeErr):
+		errs = append(errs, ImportError{Row: row, Column: typeErr.Field, Error: "must be " + JSONKind(typeErr.Type)})
+	default:
+		errs = append(errs, ImportError{Row: row, Error: err.Error()})
+	}
+	return errs
+}
+
+// JSONKind names a Go type the way the JSON it's read from looks.
+func JSONKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Slice:
+		return "a list"
+	default:
+		return "an object"
+	}
+}
+
+// jsonName turns a validator namespace such as "TripBase.Questions[0].Prompt" into the
+// trip's JSON field, "questions".
+func jsonName(namespace string) string {
+	_, name, _ := strings.Cut(namespace, ".")
+	name, _, _ = strings.Cut(name, "[")
+	name, _, _ = strings.Cut(name, ".")
+	if f, ok := reflect.TypeFor[TripBase]().FieldByName(name); ok {
+		return f.Tag.Get("json")
+	}
+	return name
+}
+
+// CSVRecord writes the trip as a row of TripColumns.
+func (t *TripBase) CSVRecord() []string {
+	date := func(ts int64) string {
+		if ts == 0 {
+			return ""
+		}
+		return time.Unix(ts, 0).UTC().Format(ImportDateLayout)
+	}
+	float := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return []string{
+		t.ID, t.ExternalID, t.Name, t.Description, t.Mission,
+		t.TripType.String(), t.HousingType.String(), t.PrivacyType.String(), string(t.Status),
+		strconv.Itoa(t.VolunteerLimit), strconv.FormatInt(t.Price, 10), t.Currency,
+		t.City, t.Country, float(t.Latitude), float(t.Longitude),
+		date(t.StartDate), date(t.EndDate),
+	}
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrItineraryNoDates      = fmt.Errorf("Trip needs a start and end date before it can have an itinerary")
+	ErrItineraryDayOutside   = fmt.Errorf("Itinerary days must fall between the trip's start and end dates")
+	ErrItineraryDuplicateDay = fmt.Errorf("Itinerary has more than one entry for the same day")
+	ErrMeetingPointLocation  = fmt.Errorf("Meeting points need a place with latitude and longitude")
+)
+
+// ItineraryDateLayout is how itinerary days are written, in the trip's local calendar.
+const ItineraryDateLayout = "2006-01-02"
+
+type ItineraryItemKind string
+
+const (
+	ItemActivity     ItineraryItemKind = "activity"
+	ItemMeetingPoint ItineraryItemKind = "meeting_point"
+	ItemTravel       ItineraryItemKind = "travel"
+	ItemMeal         ItineraryItemKind = "meal"
+	ItemFreeTime     ItineraryItemKind = "free_time"
+)
+
+// Place is a named spot on the itinerary; coordinates are optional except for meeting points.
+type Place struct {
+	Name      string   `json:"name" validate:"max=200"`
+	Address   string   `json:"address" validate:"max=300"`
+	Latitude  *float64 `json:"latitude,omitempty" validate:"omitempty,latitude"`
+	Longitude *float64 `json:"longitude,omitempty" validate:"omitempty,longitude"`
+}
+
+// HasCoordinates reports whether the place can be pinned on a map.
+func (p *Place) HasCoordinates() bool {
+	return p != nil && p.Latitude != nil && p.Longitude != nil
+}
+
+// ItineraryItem is one entry in a day, shown in the order given.
+type ItineraryItem struct {
+	Time        string            `json:"time,omitempty" validate:"omitempty,datetime=15:04"`
+	Kind        ItineraryItemKind `json:"kind" validate:"required,oneof=activity meeting_point travel meal free_time"`
+	Title       string            `json:"title" validate:"required,max=200"`
+	Description string            `json:"description" validate:"max=2000"`
+	Place       *Place            `json:"place,omitempty"`
+}
+
+// ItineraryDay plans one calendar day of the trip; Housing is where volunteers sleep that night.
+type ItineraryDay struct {
+	Date    string          `json:"date" validate:"required,datetime=2006-01-02"`
+	Title   string          `json:"title" validate:"max=200"`
+	Housing *Place          `json:"housing,omitempty"`
+	Items   []ItineraryItem `json:"items" validate:"max=50,dive"`
+}
+
+// Itinerary is a trip's day-by-day plan, stored alongside the trip rather than inside it.
+type Itinerary struct {
+	TripID    string         `json:"trip_id"`
+	Days      []ItineraryDay `json:"days" validate:"max=366,dive"`
+	UpdatedAt int64          `json:"updated_at"`
+}
+
+// Validate checks the itinerary against the trip's dates and sorts its days. Days are
+// calendar dates, compared against the UTC dates of StartDate and EndDate.
+func (it *Itinerary) Validate(startDate, endDate int64) error {
+	if it.Days == nil {
+		it.Days = []ItineraryDay{}
+	}
+	if err := validation.Struct(it); err != nil {
+		return err
+	}
+	if len(it.Days) == 0 {
+		return nil
+	}
+	if startDate == 0 || endDate == 0 {
+		return ErrItineraryNoDates
+	}
+	first := time.Unix(startDate, 0).UTC().Format(ItineraryDateLayout)
+	last := time.Unix(endDate, 0).UTC().Format(ItineraryDateLayout)
+
+	slices.SortStableFunc(it.Days, func(a, b ItineraryDay) int { return strings.Compare(a.Date, b.Date) })
+	for i, day := range it.Days {
+		// layout dates compare correctly as strings
+		if day.Date < first || day.Date > last {
+			return ErrItineraryDayOutside
+		}
+		if i > 0 && it.Days[i-1].Date == day.Date {
+			return ErrItineraryDuplicateDay
+		}
+		if day.Items == nil {
+			it.Days[i].Items = []ItineraryItem{}
+		}
+		for _, item := range day.Items {
+			if item.Kind == ItemMeetingPoint && !item.Place.HasCoordinates() {
+				return ErrMeetingPointLocation
+			}
+		}
+	}
+	return nil
+}
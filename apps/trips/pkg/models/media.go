@@ -0,0 +1,94 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+var (
+	ErrMediaNotFound    = fmt.Errorf("Media not found")
+	ErrUnsupportedMedia = fmt.Errorf("Only JPEG, PNG and WebP images are supported")
+	ErrMediaTooLarge    = fmt.Errorf("Image is too large")
+	ErrTooManyMedia     = fmt.Errorf("Trip has reached its photo limit")
+)
+
+// MaxTripMedia caps how many photos one trip can have.
+const MaxTripMedia = 20
+
+// MediaSize is a resized copy generated for every upload, bounded by Width on its
+// longest side. The original upload is kept as-is under MediaOriginal.
+type MediaSize struct {
+	Name  string
+	Width int
+}
+
+const MediaOriginal = "original"
+
+var MediaSizes = []MediaSize{
+	{Name: "thumb", Width: 320},
+	{Name: "card", Width: 640},
+	{Name: "large", Width: 1280},
+}
+
+type MediaVariant struct {
+	Name        string `json:"name"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+	URL         string `json:"url"`
+}
+
+// Media is a photo attached to a trip. Position orders a trip's photos; exactly one
+// of them is the cover shown on cards once any exist.
+type Media struct {
+	ID        string         `json:"id"`
+	TripID    string         `json:"trip_id"`
+	Alt       string         `json:"alt" validate:"max=300"`
+	Position  int            `json:"position"`
+	Cover     bool           `json:"cover"`
+	Variants  []MediaVariant `json:"variants"`
+	CreatedAt int64          `json:"created_at"`
+}
+
+func NewMedia(tripID, alt string) *Media {
+	return &Media{
+		ID:        ulid.Make().String(),
+		TripID:    tripID,
+		Alt:       alt,
+		CreatedAt: time.Now().Unix(),
+	}
+}
+
+// BlobKey is where a variant's bytes live in the blob store.
+func (m *Media) BlobKey(variant string) string {
+	return MediaBlobKey(m.TripID, m.ID, variant)
+}
+
+func MediaBlobKey(tripID, mediaID, variant string) string {
+	return fmt.Sprintf("trips/%s/%s/%s", tripID, mediaID, variant)
+}
+
+// MediaURL is the trips service path that serves a variant.
+func MediaURL(tripID, mediaID, variant string) string {
+	return fmt.Sprintf("/v1/trips/%s/media/%s/%s", tripID, mediaID, variant)
+}
+
+// Variant returns the named variant, if the upload has it.
+func (m *Media) Variant(name string) (MediaVariant, bool) {
+	for _, v := range m.Variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return MediaVariant{}, false
+}
+
+// MediaUpdate changes a photo's alt text, order or cover flag; nil fields are left alone.
+type MediaUpdate struct {
+	Alt      *string `json:"alt" validate:"omitempty,max=300"`
+	Position *int    `json:"position" validate:"omitempty,min=0"`
+	Cover    *bool   `json:"cover"`
+}
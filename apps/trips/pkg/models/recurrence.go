@@ -0,0 +1,172 @@
+package models
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRRule = fmt.Errorf("Recurrence rule is invalid")
+
+type RecurrenceFreq string
+
+const (
+	FreqDaily   RecurrenceFreq = "DAILY"
+	FreqWeekly  RecurrenceFreq = "WEEKLY"
+	FreqMonthly RecurrenceFreq = "MONTHLY"
+	FreqYearly  RecurrenceFreq = "YEARLY"
+)
+
+// maxRecurrenceSteps bounds how far an occurrence walk goes before giving up, so a rule
+// that never matches (or never ends) can't spin forever.
+const maxRecurrenceSteps = 10000
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// Recurrence is the subset of an RFC 5545 RRULE that trip schedules support: FREQ,
+// INTERVAL, COUNT, UNTIL and, for weekly rules, BYDAY without ordinals. Weeks start on
+// Monday. Monthly and yearly rules skip months without the start's day, as RFC 5545 does.
+type Recurrence struct {
+	Freq     RecurrenceFreq
+	Interval int
+	Count    int
+	Until    time.Time
+	ByDay    []time.Weekday
+}
+
+// ParseRRule reads a rule such as "FREQ=MONTHLY;INTERVAL=1;COUNT=6". A leading "RRULE:"
+// is allowed.
+func ParseRRule(rule string) (Recurrence, error) {
+	r := Recurrence{Interval: 1}
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return r, fmt.Errorf("%w: %q is not NAME=VALUE", ErrInvalidRRule, part)
+		}
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			r.Freq = RecurrenceFreq(strings.ToUpper(value))
+			if !slices.Contains([]RecurrenceFreq{FreqDaily, FreqWeekly, FreqMonthly, FreqYearly}, r.Freq) {
+				return r, fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRRule, value)
+			}
+		case "INTERVAL":
+			if r.Interval, err = strconv.Atoi(value); err != nil || r.Interval < 1 {
+				return r, fmt.Errorf("%w: INTERVAL must be a positive number", ErrInvalidRRule)
+			}
+		case "COUNT":
+			if r.Count, err = strconv.Atoi(value); err != nil || r.Count < 1 {
+				return r, fmt.Errorf("%w: COUNT must be a positive number", ErrInvalidRRule)
+			}
+		case "UNTIL":
+			if r.Until, err = parseRRuleTime(value); err != nil {
+				return r, fmt.Errorf("%w: UNTIL must look like 20260131 or 20260131T000000Z", ErrInvalidRRule)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(strings.ToUpper(value), ",") {
+				wd, ok := rruleWeekdays[day]
+				if !ok {
+					return r, fmt.Errorf("%w: unsupported BYDAY %q", ErrInvalidRRule, day)
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "WKST":
+			if strings.ToUpper(value) != "MO" {
+				return r, fmt.Errorf("%w: only WKST=MO is supported", ErrInvalidRRule)
+			}
+		default:
+			return r, fmt.Errorf("%w: unsupported part %s", ErrInvalidRRule, name)
+		}
+	}
+	switch {
+	case r.Freq == "":
+		return r, fmt.Errorf("%w: FREQ is required", ErrInvalidRRule)
+	case r.Count > 0 && !r.Until.IsZero():
+		return r, fmt.Errorf("%w: COUNT and UNTIL can't both be set", ErrInvalidRRule)
+	case len(r.ByDay) > 0 && r.Freq != FreqWeekly:
+		return r, fmt.Errorf("%w: BYDAY is only supported with FREQ=WEEKLY", ErrInvalidRRule)
+	}
+	return r, nil
+}
+
+func parseRRuleTime(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("20060102", value)
+	if err != nil {
+		return t, err
+	}
+	// a date-only UNTIL includes that whole day
+	return t.Add(24*time.Hour - time.Second), nil
+}
+
+// Bounded reports whether the rule ends on its own.
+func (r Recurrence) Bounded() bool {
+	return r.Count > 0 || !r.Until.IsZero()
+}
+
+// All yields the rule's occurrences in order, starting with dtstart. Times keep dtstart's
+// location and time of day.
+func (r Recurrence) All(dtstart time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		n := 0
+		emit := func(t time.Time) bool {
+			if t.Before(dtstart) {
+				return true
+			}
+			if (r.Count > 0 && n >= r.Count) || (!r.Until.IsZero() && t.After(r.Until)) {
+				return false
+			}
+			n++
+			return yield(t)
+		}
+		interval := max(r.Interval, 1)
+		for step := range maxRecurrenceSteps {
+			k := step * interval
+			switch r.Freq {
+			case FreqDaily:
+				if !emit(dtstart.AddDate(0, 0, k)) {
+					return
+				}
+			case FreqWeekly:
+				if len(r.ByDay) == 0 {
+					if !emit(dtstart.AddDate(0, 0, 7*k)) {
+						return
+					}
+					continue
+				}
+				monday := dtstart.AddDate(0, 0, -((int(dtstart.Weekday())+6)%7)+7*k)
+				for offset := range 7 {
+					day := monday.AddDate(0, 0, offset)
+					if slices.Contains(r.ByDay, day.Weekday()) && !emit(day) {
+						return
+					}
+				}
+			case FreqMonthly:
+				// build the date by hand; AddDate would roll Jan 31 into March
+				first := time.Date(dtstart.Year(), dtstart.Month()+time.Month(k), 1, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location())
+				if day := first.AddDate(0, 0, dtstart.Day()-1); day.Month() == first.Month() && !emit(day) {
+					return
+				}
+			case FreqYearly:
+				day := time.Date(dtstart.Year()+k, dtstart.Month(), dtstart.Day(), dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location())
+				if day.Day() == dtstart.Day() && !emit(day) {
+					return
+				}
+			default:
+				return
+			}
+		}
+	}
+}
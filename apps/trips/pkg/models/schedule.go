@@ -0,0 +1,113 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+var (
+	ErrScheduleNotFound    = fmt.Errorf("Schedule not found")
+	ErrScheduleNoDates     = fmt.Errorf("Schedule template needs a start and end date")
+	ErrNotScheduleInstance = fmt.Errorf("Trip is not an instance of this schedule")
+	ErrInvalidScope        = fmt.Errorf("Scope must be this or following")
+)
+
+// MaxScheduleInstances caps how many trips one schedule can create over its lifetime.
+const MaxScheduleInstances = 100
+
+// EditScope says which instances a bulk edit or cancel touches.
+type EditScope string
+
+const (
+	ScopeThis      EditScope = "this"
+	ScopeFollowing EditScope = "following"
+)
+
+// Schedule is a recurring trip. Its template is an ordinary trip whose StartDate and
+// EndDate are the first occurrence; every occurrence becomes its own trip, indexed and
+// booked like any other, with ScheduleID pointing back here.
+type Schedule struct {
+	ID       string   `json:"id"`
+	OrgID    string   `json:"org_id" validate:"required"`
+	Template TripBase `json:"template"`
+	RRule    string   `json:"rrule" validate:"required"`
+	// MaterializedThrough is the start of the latest occurrence created so far. Open-ended
+	// rules are created a horizon ahead and topped up as time passes.
+	MaterializedThrough int64 `json:"materialized_through"`
+	InstanceCount       int   `json:"instance_count"`
+	// CancelledFrom stops the rule: occurrences starting at or after it are never created.
+	CancelledFrom int64 `json:"cancelled_from,omitempty"`
+	CreatedAt     int64 `json:"created_at"`
+	UpdatedAt     int64 `json:"updated_at"`
+}
+
+func NewSchedule(orgID string) *Schedule {
+	now := time.Now().Unix()
+	return &Schedule{
+		ID:        ulid.Make().String(),
+		OrgID:     orgID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Validate checks the rule and the template's dates, and returns the parsed rule.
+func (s *Schedule) Validate() (Recurrence, error) {
+	if err := validation.Struct(s); err != nil {
+		return Recurrence{}, err
+	}
+	r, err := ParseRRule(s.RRule)
+	if err != nil {
+		return r, err
+	}
+	if s.Template.StartDate == 0 || s.Template.EndDate < s.Template.StartDate {
+		return r, ErrScheduleNoDates
+	}
+	return r, nil
+}
+
+// Duration is how long each instance lasts, taken from the template.
+func (s *Schedule) Duration() int64 {
+	return s.Template.EndDate - s.Template.StartDate
+}
+
+// NewInstance builds the trip for the occurrence starting at start. It copies the
+// template, so instances start with the template's capacity, price and status.
+func (s *Schedule) NewInstance(start int64) *TripBase {
+	now := time.Now().Unix()
+	trip := s.Template
+	trip.ID = ulid.Make().String()
+	trip.OrgID = s.OrgID
+	trip.ScheduleID = s.ID
+	trip.StartDate = start
+	trip.EndDate = start + s.Duration()
+	trip.SeatsTaken = 0
+	trip.LikeCount = 0
+	trip.Sequence = 0
+	trip.ExternalID = ""
+	trip.Cover = nil
+	trip.CreatedAt = now
+	trip.UpdatedAt = now
+	trip.DeletedAt = 0
+	return &trip
+}
+
+// ApplyTripEdit merges a JSON trip patch into trip the way PUT /v1/trips does, keeping
+// the fields that tie it to its schedule. keepDates leaves StartDate and EndDate alone,
+// for edits that span several instances.
+func ApplyTripEdit(trip *TripBase, patch []byte, keepDates bool) error {
+	keep := *trip
+	if err := json.Unmarshal(patch, trip); err != nil {
+		return err
+	}
+	trip.ID, trip.OrgID, trip.ScheduleID, trip.CreatedAt = keep.ID, keep.OrgID, keep.ScheduleID, keep.CreatedAt
+	trip.SeatsTaken, trip.LikeCount, trip.Cover = keep.SeatsTaken, keep.LikeCount, keep.Cover
+	if keepDates {
+		trip.StartDate, trip.EndDate = keep.StartDate, keep.EndDate
+	}
+	trip.UpdatedAt = time.Now().Unix()
+	return trip.Validate()
+}
@@ -1,11 +1,11 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"time"
 
-	validate "github.com/go-playground/validator/v10"
 	"github.com/oklog/ulid/v2"
 )
 
@@ -25,6 +25,16 @@ type Trip interface {
 	GetTripType() TripType
 	GetStatus() TripStatus
 	GetDeletedAt() int64
+	GetStartDate() int64
+	GetEndDate() int64
+	GetVolunteerLimit() int
+	GetSeatsTaken() int
+	SeatsRemaining() *int
+	IsFull() bool
+	GetQuestions() []Question
+	GetLikeCount() int64
+	GetCover() *Media
+	GetSequence() int
 
 	SetID(string)
 	SetOrgID(string)
@@ -33,6 +43,11 @@ type Trip interface {
 	SetTripType(TripType)
 	SetStatus(TripStatus)
 	SetDeletedAt(int64)
+	SetSeatsTaken(int)
+	SetLikeCount(int64)
+	SetCover(*Media)
+	SetSequence(int)
+	NormalizePrice(*ExchangeRates) bool
 
 	Validate() error
 	Tokenize() [][]byte
@@ -41,6 +56,9 @@ type Trip interface {
 type TripBase struct {
 	ID    string `json:"id"`
 	OrgID string `json:"org_id" validate:"required" index:"equality"`
+	// ExternalID is the org's own key for the trip, such as a spreadsheet row ID; imports
+	// update the trip with a matching one instead of creating another
+	ExternalID string `json:"external_id" updateable:"true" validate:"max=200" index:"equality"`
 
 	HousingType    HousingType `json:"housing_type" updateable:"true" index:"equality"`
 	PrivacyType    PrivacyType `json:"privacy_type" updateable:"true" index:"equality"`
@@ -50,8 +68,25 @@ type TripBase struct {
 	Name           string      `json:"name" updateable:"true"`
 	Description    string      `json:"description" updateable:"true"`
 	Mission        string      `json:"mission" updateable:"true"`
-	Price          float64     `json:"price" updateable:"true" index:"equality"`
-	Currency       string      `json:"currency" updateable:"true" index:"equality"`
+	// Price is in minor units of Currency, such as cents for USD
+	Price    int64  `json:"price" updateable:"true" validate:"min=0" index:"equality"`
+	Currency string `json:"currency" updateable:"true" validate:"required_with=Price,omitempty,iso4217" index:"equality"`
+	// PriceUSD is Price converted at the exchange rates of the last write or rate update,
+	// for the price range index; it is null when Currency has no rate
+	PriceUSD *int64 `json:"price_usd" index:"price"`
+	// Questions are asked of every volunteer who applies
+	Questions []Question `json:"questions" updateable:"true" validate:"max=20,dive"`
+	// SeatsTaken counts approved volunteers plus open seat offers; only application transitions change it
+	SeatsTaken int `json:"seats_taken"`
+	// LikeCount is filled from the likes counter on every read
+	LikeCount int64 `json:"like_count"`
+	// Cover is the trip's cover photo, filled from its photo list on every read
+	Cover *Media `json:"cover,omitempty"`
+	// ScheduleID is set on trips created from a recurring schedule
+	ScheduleID string `json:"schedule_id" index:"equality"`
+	// Sequence counts revisions; storage bumps it on every update, and calendar exports
+	// send it so subscribers replace their copy of the trip
+	Sequence int `json:"sequence"`
 
 	City      string  `json:"city" updateable:"true" index:"equality"`
 	Country   string  `json:"country" updateable:"true" index:"equality"`
@@ -81,8 +116,7 @@ func NewTrip() Trip {
 
 // Validate validates the TripBase struct
 func (t *TripBase) Validate() error {
-	validate := validate.New()
-	return validate.Struct(t)
+	return validation.Struct(t)
 }
 
 // GetID returns the ID of the trip
@@ -120,6 +154,159 @@ func (t *TripBase) GetDeletedAt() int64 {
 	return t.DeletedAt
 }
 
+// GetStartDate returns when the trip begins
+func (t *TripBase) GetStartDate() int64 {
+	return t.StartDate
+}
+
+// GetEndDate returns when the trip ends
+func (t *TripBase) GetEndDate() int64 {
+	return t.EndDate
+}
+
+// GetVolunteerLimit returns how many volunteers the trip can take; 0 means unlimited
+func (t *TripBase) GetVolunteerLimit() int {
+	return t.VolunteerLimit
+}
+
+// GetSeatsTaken returns how many seats are held by approved or offered volunteers
+func (t *TripBase) GetSeatsTaken() int {
+	return t.SeatsTaken
+}
+
+// SeatsRemaining returns the open seats, or nil when the trip has no volunteer limit
+func (t *TripBase) SeatsRemaining() *int {
+	if t.VolunteerLimit <= 0 {
+		return nil
+	}
+	remaining := max(t.VolunteerLimit-t.SeatsTaken, 0)
+	return &remaining
+}
+
+// IsFull reports whether every seat under the volunteer limit is taken
+func (t *TripBase) IsFull() bool {
+	return t.VolunteerLimit > 0 && t.SeatsTaken >= t.VolunteerLimit
+}
+
+// SetSeatsTaken records how many seats are held
+func (t *TripBase) SetSeatsTaken(seats int) {
+	t.SeatsTaken = seats
+}
+
+// MarshalJSON adds the computed seats_remaining, full and price_decimal fields
+func (t *TripBase) MarshalJSON() ([]byte, error) {
+	type trip TripBase
+	return json.Marshal(struct {
+		*trip
+		SeatsRemaining *int `json:"seats_remaining"`
+		Full           bool `json:"full"`
+		// PriceDecimal is Price in major units, such as "499.99"
+		PriceDecimal string `json:"price_decimal"`
+	}{(*trip)(t), t.SeatsRemaining(), t.IsFull(), FormatAmount(t.Price, t.Currency)})
+}
+
+// PublicTrip is a listed trip as anonymous visitors see it: the trip without the org
+// running it, the org's own keys for it, or the price index.
+type PublicTrip struct {
+	ID             string      `json:"id"`
+	HousingType    HousingType `json:"housing_type"`
+	PrivacyType    PrivacyType `json:"privacy_type"`
+	TripType       TripType    `json:"trip_type"`
+	Status         TripStatus  `json:"status"`
+	VolunteerLimit int         `json:"volunteer_limit"`
+	Name           string      `json:"name"`
+	Description    string      `json:"description"`
+	Mission        string      `json:"mission"`
+	Price          int64       `json:"price"`
+	Currency       string      `json:"currency"`
+	PriceDecimal   string      `json:"price_decimal"`
+	Questions      []Question  `json:"questions"`
+	SeatsTaken     int         `json:"seats_taken"`
+	SeatsRemaining *int        `json:"seats_remaining"`
+	Full           bool        `json:"full"`
+	LikeCount      int64       `json:"like_count"`
+	Cover          *Media      `json:"cover,omitempty"`
+	Sequence       int         `json:"sequence"`
+
+	City      string  `json:"city"`
+	Country   string  `json:"country"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+
+	StartDate int64 `json:"start_date"`
+	EndDate   int64 `json:"end_date"`
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// Public returns the trip as anonymous visitors see it.
+func (t *TripBase) Public() *PublicTrip {
+	return &PublicTrip{
+		ID:             t.ID,
+		HousingType:    t.HousingType,
+		PrivacyType:    t.PrivacyType,
+		TripType:       t.TripType,
+		Status:         t.Status,
+		VolunteerLimit: t.VolunteerLimit,
+		Name:           t.Name,
+		Description:    t.Description,
+		Mission:        t.Mission,
+		Price:          t.Price,
+		Currency:       t.Currency,
+		PriceDecimal:   FormatAmount(t.Price, t.Currency),
+		Questions:      t.Questions,
+		SeatsTaken:     t.SeatsTaken,
+		SeatsRemaining: t.SeatsRemaining(),
+		Full:           t.IsFull(),
+		LikeCount:      t.LikeCount,
+		Cover:          t.Cover,
+		Sequence:       t.Sequence,
+		City:           t.City,
+		Country:        t.Country,
+		Latitude:       t.Latitude,
+		Longitude:      t.Longitude,
+		StartDate:      t.StartDate,
+		EndDate:        t.EndDate,
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
+	}
+}
+
+// GetQuestions returns the questions volunteers answer when applying
+func (t *TripBase) GetQuestions() []Question {
+	return t.Questions
+}
+
+// GetLikeCount returns how many volunteers have liked the trip
+func (t *TripBase) GetLikeCount() int64 {
+	return t.LikeCount
+}
+
+// SetLikeCount records how many volunteers have liked the trip
+func (t *TripBase) SetLikeCount(count int64) {
+	t.LikeCount = count
+}
+
+// GetCover returns the photo shown on trip cards, or nil when the trip has none
+func (t *TripBase) GetCover() *Media {
+	return t.Cover
+}
+
+// SetCover records the photo shown on trip cards
+func (t *TripBase) SetCover(cover *Media) {
+	t.Cover = cover
+}
+
+// GetSequence returns how many times the trip has been revised
+func (t *TripBase) GetSequence() int {
+	return t.Sequence
+}
+
+// SetSequence records how many times the trip has been revised
+func (t *TripBase) SetSequence(sequence int) {
+	t.Sequence = sequence
+}
+
 // SetID sets the ID of the trip
 func (t *TripBase) SetID(id string) {
 	t.ID = id
@@ -172,7 +359,11 @@ func (t *TripBase) Tokenize() [][]byte {
 			token := MakeKey(field.Tag.Get("json"), fmt.Sprintf("%v", value))
 			tokens = append(tokens, token)
 		case "geoposition":
-		// todo(t8): implement
+		// latitude and longitude are indexed together below
+		case "price":
+			if price := v.Field(i); !price.IsNil() {
+				tokens = append(tokens, priceToken(field.Tag.Get("json"), priceBand(price.Elem().Int())))
+			}
 		case "equality":
 			value := v.Field(i).Interface()
 			if value == nil || value == "" {
@@ -182,5 +373,12 @@ func (t *TripBase) Tokenize() [][]byte {
 			tokens = append(tokens, token)
 		}
 	}
+	if t.HasCoordinates() {
+		tokens = append(tokens, GeoTokens(t.Latitude, t.Longitude)...)
+	}
+	// full is a computed status so GetTrips can filter on status=full
+	if t.IsFull() {
+		tokens = append(tokens, MakeKey("status", string(TripStatusFull)))
+	}
 	return tokens
 }
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+
+	validate "github.com/go-playground/validator/v10"
+)
+
+// validation checks every model. It names fields by their JSON tags, so validation
+// errors point at the fields clients sent.
+var validation = newValidation()
+
+func newValidation() *validate.Validate {
+	v := validate.New()
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
@@ -0,0 +1,6 @@
+// Package tripsv1 is the trips service's gRPC API, generated from
+// proto/trips/v1/trips.proto. Regenerate it with buf, protoc-gen-go and
+// protoc-gen-go-grpc installed.
+package tripsv1
+
+//go:generate sh -c "cd ../.. && buf generate"
@@ -0,0 +1,1172 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: trips/v1/trips.proto
+
+// The trips service's gRPC API, for other services. It answers exactly like the REST API
+// under /v1/trips: the same validation, the same errors and the same storage.
+
+package tripsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TripEvent_Kind int32
+
+const (
+	TripEvent_KIND_UNSPECIFIED TripEvent_Kind = 0
+	TripEvent_KIND_CREATED     TripEvent_Kind = 1
+	TripEvent_KIND_UPDATED     TripEvent_Kind = 2
+	TripEvent_KIND_DELETED     TripEvent_Kind = 3
+)
+
+// Enum value maps for TripEvent_Kind.
+var (
+	TripEvent_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "KIND_CREATED",
+		2: "KIND_UPDATED",
+		3: "KIND_DELETED",
+	}
+	TripEvent_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED": 0,
+		"KIND_CREATED":     1,
+		"KIND_UPDATED":     2,
+		"KIND_DELETED":     3,
+	}
+)
+
+func (x TripEvent_Kind) Enum() *TripEvent_Kind {
+	p := new(TripEvent_Kind)
+	*p = x
+	return p
+}
+
+func (x TripEvent_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TripEvent_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_trips_v1_trips_proto_enumTypes[0].Descriptor()
+}
+
+func (TripEvent_Kind) Type() protoreflect.EnumType {
+	return &file_trips_v1_trips_proto_enumTypes[0]
+}
+
+func (x TripEvent_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TripEvent_Kind.Descriptor instead.
+func (TripEvent_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_trips_v1_trips_proto_rawDescGZIP(), []int{11, 0}
+}
+
+// Trip has the fields of the REST API's Trip, by the same names, except the cover photo,
+// which GET /v1/trips/{trip_id}/media lists. Enums are their names, such as
+// "international"; GET /v1/trips/schema lists them.
+type Trip struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrgId          string                 `protobuf:"bytes,2,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	ExternalId     string                 `protobuf:"bytes,3,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	HousingType    string                 `protobuf:"bytes,4,opt,name=housing_type,json=housingType,proto3" json:"housing_type,omitempty"`
+	PrivacyType    string                 `protobuf:"bytes,5,opt,name=privacy_type,json=privacyType,proto3" json:"privacy_type,omitempty"`
+	TripType       string                 `protobuf:"bytes,6,opt,name=trip_type,json=tripType,proto3" json:"trip_type,omitempty"`
+	Status         string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	VolunteerLimit int64                  `protobuf:"varint,8,opt,name=volunteer_limit,json=volunteerLimit,proto3" json:"volunteer_limit,omitempty"`
+	Name           string                 `protobuf:"bytes,9,opt,name=name,proto3" json:"name,omitempty"`
+	Description    string                 `protobuf:"bytes,10,opt,name=description,proto3" json:"description,omitempty"`
+	Mission        string                 `protobuf:"bytes,11,opt,name=mission,proto3" json:"mission,omitempty"`
+	// price is in minor units of currency, such as cents for USD
+	Price    int64  `protobuf:"varint,12,opt,name=price,proto3" json:"price,omitempty"`
+	Currency string `protobuf:"bytes,13,opt,name=currency,proto3" json:"currency,omitempty"`
+	// price_usd is read-only, and unset when currency has no exchange rate
+	PriceUsd  *int64      `protobuf:"varint,14,opt,name=price_usd,json=priceUsd,proto3,oneof" json:"price_usd,omitempty"`
+	Questions []*Question `protobuf:"bytes,15,rep,name=questions,proto3" json:"questions,omitempty"`
+	// seats_taken, like_count, schedule_id and sequence are read-only
+	SeatsTaken int64   `protobuf:"varint,16,opt,name=seats_taken,json=seatsTaken,proto3" json:"seats_taken,omitempty"`
+	LikeCount  int64   `protobuf:"varint,17,opt,name=like_count,json=likeCount,proto3" json:"like_count,omitempty"`
+	ScheduleId string  `protobuf:"bytes,18,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	Sequence   int64   `protobuf:"varint,19,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	City       string  `protobuf:"bytes,20,opt,name=city,proto3" json:"city,omitempty"`
+	Country    string  `protobuf:"bytes,21,opt,name=country,proto3" json:"country,omitempty"`
+	Latitude   float64 `protobuf:"fixed64,22,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude  float64 `protobuf:"fixed64,23,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	// the dates and times are Unix seconds
+	StartDate int64 `protobuf:"varint,24,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate   int64 `protobuf:"varint,25,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	CreatedAt int64 `protobuf:"varint,26,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt int64 `protobuf:"varint,27,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	DeletedAt int64 `protobuf:"varint,28,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	// seats_remaining, full and price_decimal are computed, as in REST; seats_remaining
+	// is unset when the trip has no volunteer limit
+	SeatsRemaining *int64 `protobuf:"varint,29,opt,name=seats_remaining,json=seatsRemaining,proto3,oneof" json:"seats_remaining,omitempty"`
+	Full           bool   `protobuf:"varint,30,opt,name=full,proto3" json:"full,omitempty"`
+	PriceDecimal   string `protobuf:"bytes,31,opt,name=price_decimal,json=priceDecimal,proto3" json:"price_decimal,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Trip) Reset() {
+	*x = Trip{}
+	mi := &file_trips_v1_trips_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Trip) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trip) ProtoMessage() {}
+
+func (x *Trip) ProtoReflect() protoreflect.Message {
+	mi := &file_trips_v1_trips_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trip.ProtoReflect.Descriptor instead.
+func (*Trip) Descriptor() ([]byte, []int) {
+	return file_trips_v1_trips_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Trip) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Trip) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *Trip) GetExternalId() string {
+	if x != nil {
+		return x.ExternalId
+	}
+	return ""
+}
+
+func (x *Trip) GetHousingType() string {
+	if x != nil {
+		return x.HousingType
+	}
+	return ""
+}
+
+func (x *Trip) GetPrivacyType() string {
+	if x != nil {
+		return x.PrivacyType
+	}
+	return ""
+}
+
+func (x *Trip) GetTripType() string {
+	if x != nil {
+		return x.TripType
+	}
+	return ""
+}
+
+func (x *Trip) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Trip) GetVolunteerLimit() int64 {
+	if x != nil {
+		return x.VolunteerLimit
+	}
+	return 0
+}
+
+func (x *Trip) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Trip) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Trip) GetMission() string {
+	if x != nil {
+		return x.Mission
+	}
+	return ""
+}
+
+func (x *Trip) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Trip) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Trip) GetPriceUsd() int64 {
+	if x != nil && x.PriceUsd != nil {
+		return *x.PriceUsd
+	}
+	return 0
+}
+
+func (x *Trip) GetQuestions() []*Question {
+	if x != nil {
+		return x.Questions
+	}
+	return nil
+}
+
+func (x *Trip) GetSeatsTaken() int64 {
+	if x != nil {
+		return x.SeatsTaken
+	}
+	return 0
+}
+
+func (x *Trip) GetLikeCount() int64 {
+	if x != nil {
+		return x.LikeCount
+	}
+	return 0
+}
+
+func (x *Trip) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
+}
+
+func (x *Trip) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *Trip) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Trip) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Trip) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Trip) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Trip) GetStartDate() int64 {
+	if x != nil {
+		return x.StartDate
+	}
+	return 0
+}
+
+func (x *Trip) GetEndDate() int64 {
+	if x != nil {
+		return x.EndDate
+	}
+	return 0
+}
+
+func (x *Trip) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Trip) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+func (x *Trip) GetDeletedAt() int64 {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return 0
+}
+
+func (x *Trip) GetSeatsRemaining() int64 {
+	if x != nil && x.SeatsRemaining != nil {
+		return *x.SeatsRemaining
+	}
+	return 0
+}
+
+func (x *Trip) GetFull() bool {
+	if x != nil {
+		return x.Full
+	}
+	return false
+}
+
+func (x *Trip) GetPriceDecimal() string {
+	if x != nil {
+		return x.PriceDecimal
+	}
+	return ""
+}
+
+// Question is asked of every volunteer who applies to the trip.
+type Question struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Prompt        string                 `protobuf:"bytes,2,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	Required      bool                   `protobuf:"varint,3,opt,name=required,proto3" json:"required,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Question) Reset() {
+	*x = Question{}
+	mi := &file_trips_v1_trips_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Question) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Question) ProtoMessage() {}
+
+func (x *Question) ProtoReflect() protoreflect.Message {
+	mi := &file_trips_v1_trips_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Question.ProtoReflect.Descriptor instead.
+func (*Question) Descriptor() ([]byte, []int) {
+	return file_trips_v1_trips_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Question) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Question) GetPrompt() string {
+	if x != nil {
+		return x.Prompt
+	}
+	return ""
+}
+
+func (x *Question) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+type CreateTripRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// trip's unset fields take the same defaults as in REST
+	Trip          *Trip `protobuf:"bytes,1,opt,name=trip,proto3" json:"trip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTripRequest) Reset() {
+	*x = CreateTripRequest{}
+	mi := &file_trips_v1_trips_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTripRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTripRequest) ProtoMessage() {}
+
+func (x *CreateTripRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trips_v1_trips_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTripRequest.ProtoReflect.Descriptor instead.
+func (*CreateTripRequest) Descriptor() ([]byte, []int) {
+	return file_trips_v1_trips_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateTripRequest) GetTrip() *Trip {
+	if x != nil {
+		return x.Trip
+	}
+	return nil
+}
+
+type GetTripRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrgId         string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	TripId        string                 `protobuf:"bytes,2,opt,name=trip_id,json=tripId,proto3" json:"trip_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTripRequest) Reset() {
+	*x = GetTripRequest{}
+	mi := &file_trips_v1_trips_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTripRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTripRequest) ProtoMessage() {}
+
+func (x *GetTripRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trips_v1_trips_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTripRequest.ProtoReflect.Descriptor instead.
+func (*GetTripRequest) Descriptor() ([]byte, []int) {
+	return file_trips_v1_trips_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetTripRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *GetTripRequest) GetTripId() string {
+	if x != nil {
+		return x.TripId
+	}
+	return ""
+}
+
+type UpdateTripRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	OrgId  string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	TripId string                 `protobuf:"bytes,2,opt,name=trip_id,json=tripId,proto3" json:"trip_id,omitempty"`
+	Trip   *Trip                  `protobuf:"bytes,3,opt,name=trip,proto3" json:"trip,omitempty"`
+	// update_mask names the fields of trip to change, such as "name" or "questions";
+	// other fields keep their stored values
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,4,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTripRequest) Reset() {
+	*x = UpdateTripRequest{}
+	mi := &file_trips_v1_trips_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTripRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTripRequest) ProtoMessage() {}
+
+func (x *UpdateTripRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trips_v1_trips_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTripRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTripRequest) Descriptor() ([]byte, []int) {
+	return file_trips_v1_trips_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpdateTripRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *UpdateTripRequest) GetTripId() string {
+	if x != nil {
+		return x.TripId
+	}
+	return ""
+}
+
+func (x *UpdateTripRequest) GetTrip() *Trip {
+	if x != nil {
+		return x.Trip
+	}
+	return nil
+}
+
+func (x *UpdateTripRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type DeleteTripRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrgId         string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	TripId        string                 `protobuf:"bytes,2,opt,name=trip_id,json=tripId,proto3" json:"trip_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTripRequest) Reset() {
+	*x = DeleteTripRequest{}
+	mi := &file_trips_v1_trips_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTripRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTripRequest) ProtoMessage() {}
+
+func (x *DeleteTripRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trips_v1_trips_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTripRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTripRequest) Descriptor() ([]byte, []int) {
+	return file_trips_v1_trips_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteTripRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *DeleteTripRequest) GetTripId() string {
+	if x != nil {
+		return x.TripId
+	}
+	return ""
+}
+
+type DeleteTripResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TripId        string                 `protobuf:"bytes,1,opt,name=trip_id,json=tripId,proto3" json:"trip_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTripResponse) Reset() {
+	*x = DeleteTripResponse{}
+	mi := &file_trips_v1_trips_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTripResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTripResponse) ProtoMessage() {}
+
+func (x *DeleteTripResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trips_v1_trips_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTripResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTripResponse) Descriptor() ([]byte, []int) {
+	return file_trips_v1_trips_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteTripResponse) GetTripId() string {
+	if x != nil {
+		return x.TripId
+	}
+	return ""
+}
+
+type QueryTripsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// filters are REST's field filters, such as org_id or trip_type; a trip must match
+	// one value of every field
+	Filters map[string]*FilterValues `protobuf:"bytes,1,rep,name=filters,proto3" json:"filters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// min_price and max_price are in major units of currency, such as "499.99"; currency
+	// is USD when unset
+	MinPrice string `protobuf:"bytes,2,opt,name=min_price,json=minPrice,proto3" json:"min_price,omitempty"`
+	MaxPrice string `protobuf:"bytes,3,opt,name=max_price,json=maxPrice,proto3" json:"max_price,omitempty"`
+	Currency string `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	// bbox limits the trips to west,south,east,north
+	Bbox          string `protobuf:"bytes,5,opt,name=bbox,proto3" json:"bbox,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryTripsRequest) Reset() {
+	*x = QueryTripsRequest{}
+	mi := &file_trips_v1_trips_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryTripsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryTripsRequest) ProtoMessage() {}
+
+func (x *QueryTripsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trips_v1_trips_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryTripsRequest.ProtoReflect.Descriptor instead.
+func (*QueryTripsRequest) Descriptor() ([]byte, []int) {
+	return file_trips_v1_trips_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *QueryTripsRequest) GetFilters() map[string]*FilterValues {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *QueryTripsRequest) GetMinPrice() string {
+	if x != nil {
+		return x.MinPrice
+	}
+	return ""
+}
+
+func (x *QueryTripsRequest) GetMaxPrice() string {
+	if x != nil {
+		return x.MaxPrice
+	}
+	return ""
+}
+
+func (x *QueryTripsRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *QueryTripsRequest) GetBbox() string {
+	if x != nil {
+		return x.Bbox
+	}
+	return ""
+}
+
+type FilterValues struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []string               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FilterValues) Reset() {
+	*x = FilterValues{}
+	mi := &file_trips_v1_trips_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FilterValues) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterValues) ProtoMessage() {}
+
+func (x *FilterValues) ProtoReflect() protoreflect.Message {
+	mi := &file_trips_v1_trips_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilterValues.ProtoReflect.Descriptor instead.
+func (*FilterValues) Descriptor() ([]byte, []int) {
+	return file_trips_v1_trips_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FilterValues) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type QueryTripsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trips         []*Trip                `protobuf:"bytes,1,rep,name=trips,proto3" json:"trips,omitempty"`
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	ScannedCount  int64                  `protobuf:"varint,3,opt,name=scanned_count,json=scannedCount,proto3" json:"scanned_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryTripsResponse) Reset() {
+	*x = QueryTripsResponse{}
+	mi := &file_trips_v1_trips_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryTripsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryTripsResponse) ProtoMessage() {}
+
+func (x *QueryTripsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trips_v1_trips_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryTripsResponse.ProtoReflect.Descriptor instead.
+func (*QueryTripsResponse) Descriptor() ([]byte, []int) {
+	return file_trips_v1_trips_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *QueryTripsResponse) GetTrips() []*Trip {
+	if x != nil {
+		return x.Trips
+	}
+	return nil
+}
+
+func (x *QueryTripsResponse) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *QueryTripsResponse) GetScannedCount() int64 {
+	if x != nil {
+		return x.ScannedCount
+	}
+	return 0
+}
+
+type WatchTripsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// org_id is the org whose trips to watch; it must be the session's, which it
+	// defaults to
+	OrgId         string `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTripsRequest) Reset() {
+	*x = WatchTripsRequest{}
+	mi := &file_trips_v1_trips_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTripsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTripsRequest) ProtoMessage() {}
+
+func (x *WatchTripsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trips_v1_trips_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTripsRequest.ProtoReflect.Descriptor instead.
+func (*WatchTripsRequest) Descriptor() ([]byte, []int) {
+	return file_trips_v1_trips_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WatchTripsRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+type TripEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Kind  TripEvent_Kind         `protobuf:"varint,1,opt,name=kind,proto3,enum=trips.v1.TripEvent_Kind" json:"kind,omitempty"`
+	// trip is as stored after the change, or as it was before a deletion
+	Trip          *Trip `protobuf:"bytes,2,opt,name=trip,proto3" json:"trip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TripEvent) Reset() {
+	*x = TripEvent{}
+	mi := &file_trips_v1_trips_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TripEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TripEvent) ProtoMessage() {}
+
+func (x *TripEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_trips_v1_trips_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TripEvent.ProtoReflect.Descriptor instead.
+func (*TripEvent) Descriptor() ([]byte, []int) {
+	return file_trips_v1_trips_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TripEvent) GetKind() TripEvent_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return TripEvent_KIND_UNSPECIFIED
+}
+
+func (x *TripEvent) GetTrip() *Trip {
+	if x != nil {
+		return x.Trip
+	}
+	return nil
+}
+
+var File_trips_v1_trips_proto protoreflect.FileDescriptor
+
+const file_trips_v1_trips_proto_rawDesc = "" +
+	"\n" +
+	"\x14trips/v1/trips.proto\x12\btrips.v1\x1a google/protobuf/field_mask.proto\"\xcd\a\n" +
+	"\x04Trip\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x15\n" +
+	"\x06org_id\x18\x02 \x01(\tR\x05orgId\x12\x1f\n" +
+	"\vexternal_id\x18\x03 \x01(\tR\n" +
+	"externalId\x12!\n" +
+	"\fhousing_type\x18\x04 \x01(\tR\vhousingType\x12!\n" +
+	"\fprivacy_type\x18\x05 \x01(\tR\vprivacyType\x12\x1b\n" +
+	"\ttrip_type\x18\x06 \x01(\tR\btripType\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12'\n" +
+	"\x0fvolunteer_limit\x18\b \x01(\x03R\x0evolunteerLimit\x12\x12\n" +
+	"\x04name\x18\t \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\n" +
+	" \x01(\tR\vdescription\x12\x18\n" +
+	"\amission\x18\v \x01(\tR\amission\x12\x14\n" +
+	"\x05price\x18\f \x01(\x03R\x05price\x12\x1a\n" +
+	"\bcurrency\x18\r \x01(\tR\bcurrency\x12 \n" +
+	"\tprice_usd\x18\x0e \x01(\x03H\x00R\bpriceUsd\x88\x01\x01\x120\n" +
+	"\tquestions\x18\x0f \x03(\v2\x12.trips.v1.QuestionR\tquestions\x12\x1f\n" +
+	"\vseats_taken\x18\x10 \x01(\x03R\n" +
+	"seatsTaken\x12\x1d\n" +
+	"\n" +
+	"like_count\x18\x11 \x01(\x03R\tlikeCount\x12\x1f\n" +
+	"\vschedule_id\x18\x12 \x01(\tR\n" +
+	"scheduleId\x12\x1a\n" +
+	"\bsequence\x18\x13 \x01(\x03R\bsequence\x12\x12\n" +
+	"\x04city\x18\x14 \x01(\tR\x04city\x12\x18\n" +
+	"\acountry\x18\x15 \x01(\tR\acountry\x12\x1a\n" +
+	"\blatitude\x18\x16 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x17 \x01(\x01R\tlongitude\x12\x1d\n" +
+	"\n" +
+	"start_date\x18\x18 \x01(\x03R\tstartDate\x12\x19\n" +
+	"\bend_date\x18\x19 \x01(\x03R\aendDate\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x1a \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x1b \x01(\x03R\tupdatedAt\x12\x1d\n" +
+	"\n" +
+	"deleted_at\x18\x1c \x01(\x03R\tdeletedAt\x12,\n" +
+	"\x0fseats_remaining\x18\x1d \x01(\x03H\x01R\x0eseatsRemaining\x88\x01\x01\x12\x12\n" +
+	"\x04full\x18\x1e \x01(\bR\x04full\x12#\n" +
+	"\rprice_decimal\x18\x1f \x01(\tR\fpriceDecimalB\f\n" +
+	"\n" +
+	"_price_usdB\x12\n" +
+	"\x10_seats_remaining\"N\n" +
+	"\bQuestion\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06prompt\x18\x02 \x01(\tR\x06prompt\x12\x1a\n" +
+	"\brequired\x18\x03 \x01(\bR\brequired\"7\n" +
+	"\x11CreateTripRequest\x12\"\n" +
+	"\x04trip\x18\x01 \x01(\v2\x0e.trips.v1.TripR\x04trip\"@\n" +
+	"\x0eGetTripRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12\x17\n" +
+	"\atrip_id\x18\x02 \x01(\tR\x06tripId\"\xa4\x01\n" +
+	"\x11UpdateTripRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12\x17\n" +
+	"\atrip_id\x18\x02 \x01(\tR\x06tripId\x12\"\n" +
+	"\x04trip\x18\x03 \x01(\v2\x0e.trips.v1.TripR\x04trip\x12;\n" +
+	"\vupdate_mask\x18\x04 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\"C\n" +
+	"\x11DeleteTripRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12\x17\n" +
+	"\atrip_id\x18\x02 \x01(\tR\x06tripId\"-\n" +
+	"\x12DeleteTripResponse\x12\x17\n" +
+	"\atrip_id\x18\x01 \x01(\tR\x06tripId\"\x95\x02\n" +
+	"\x11QueryTripsRequest\x12B\n" +
+	"\afilters\x18\x01 \x03(\v2(.trips.v1.QueryTripsRequest.FiltersEntryR\afilters\x12\x1b\n" +
+	"\tmin_price\x18\x02 \x01(\tR\bminPrice\x12\x1b\n" +
+	"\tmax_price\x18\x03 \x01(\tR\bmaxPrice\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency\x12\x12\n" +
+	"\x04bbox\x18\x05 \x01(\tR\x04bbox\x1aR\n" +
+	"\fFiltersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.trips.v1.FilterValuesR\x05value:\x028\x01\"&\n" +
+	"\fFilterValues\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\tR\x06values\"u\n" +
+	"\x12QueryTripsResponse\x12$\n" +
+	"\x05trips\x18\x01 \x03(\v2\x0e.trips.v1.TripR\x05trips\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\x12#\n" +
+	"\rscanned_count\x18\x03 \x01(\x03R\fscannedCount\"*\n" +
+	"\x11WatchTripsRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\"\xb1\x01\n" +
+	"\tTripEvent\x12,\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x18.trips.v1.TripEvent.KindR\x04kind\x12\"\n" +
+	"\x04trip\x18\x02 \x01(\v2\x0e.trips.v1.TripR\x04trip\"R\n" +
+	"\x04Kind\x12\x14\n" +
+	"\x10KIND_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fKIND_CREATED\x10\x01\x12\x10\n" +
+	"\fKIND_UPDATED\x10\x02\x12\x10\n" +
+	"\fKIND_DELETED\x10\x032\x8d\x03\n" +
+	"\fTripsService\x129\n" +
+	"\n" +
+	"CreateTrip\x12\x1b.trips.v1.CreateTripRequest\x1a\x0e.trips.v1.Trip\x123\n" +
+	"\aGetTrip\x12\x18.trips.v1.GetTripRequest\x1a\x0e.trips.v1.Trip\x129\n" +
+	"\n" +
+	"UpdateTrip\x12\x1b.trips.v1.UpdateTripRequest\x1a\x0e.trips.v1.Trip\x12G\n" +
+	"\n" +
+	"DeleteTrip\x12\x1b.trips.v1.DeleteTripRequest\x1a\x1c.trips.v1.DeleteTripResponse\x12G\n" +
+	"\n" +
+	"QueryTrips\x12\x1b.trips.v1.QueryTripsRequest\x1a\x1c.trips.v1.QueryTripsResponse\x12@\n" +
+	"\n" +
+	"WatchTrips\x12\x1b.trips.v1.WatchTripsRequest\x1a\x13.trips.v1.TripEvent0\x01B=Z;github.com/Taiterbase/vtrips/apps/trips/pkg/tripsv1;tripsv1b\x06proto3"
+
+var (
+	file_trips_v1_trips_proto_rawDescOnce sync.Once
+	file_trips_v1_trips_proto_rawDescData []byte
+)
+
+func file_trips_v1_trips_proto_rawDescGZIP() []byte {
+	file_trips_v1_trips_proto_rawDescOnce.Do(func() {
+		file_trips_v1_trips_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_trips_v1_trips_proto_rawDesc), len(file_trips_v1_trips_proto_rawDesc)))
+	})
+	return file_trips_v1_trips_proto_rawDescData
+}
+
+var file_trips_v1_trips_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_trips_v1_trips_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_trips_v1_trips_proto_goTypes = []any{
+	(TripEvent_Kind)(0),           // 0: trips.v1.TripEvent.Kind
+	(*Trip)(nil),                  // 1: trips.v1.Trip
+	(*Question)(nil),              // 2: trips.v1.Question
+	(*CreateTripRequest)(nil),     // 3: trips.v1.CreateTripRequest
+	(*GetTripRequest)(nil),        // 4: trips.v1.GetTripRequest
+	(*UpdateTripRequest)(nil),     // 5: trips.v1.UpdateTripRequest
+	(*DeleteTripRequest)(nil),     // 6: trips.v1.DeleteTripRequest
+	(*DeleteTripResponse)(nil),    // 7: trips.v1.DeleteTripResponse
+	(*QueryTripsRequest)(nil),     // 8: trips.v1.QueryTripsRequest
+	(*FilterValues)(nil),          // 9: trips.v1.FilterValues
+	(*QueryTripsResponse)(nil),    // 10: trips.v1.QueryTripsResponse
+	(*WatchTripsRequest)(nil),     // 11: trips.v1.WatchTripsRequest
+	(*TripEvent)(nil),             // 12: trips.v1.TripEvent
+	nil,                           // 13: trips.v1.QueryTripsRequest.FiltersEntry
+	(*fieldmaskpb.FieldMask)(nil), // 14: google.protobuf.FieldMask
+}
+var file_trips_v1_trips_proto_depIdxs = []int32{
+	2,  // 0: trips.v1.Trip.questions:type_name -> trips.v1.Question
+	1,  // 1: trips.v1.CreateTripRequest.trip:type_name -> trips.v1.Trip
+	1,  // 2: trips.v1.UpdateTripRequest.trip:type_name -> trips.v1.Trip
+	14, // 3: trips.v1.UpdateTripRequest.update_mask:type_name -> google.protobuf.FieldMask
+	13, // 4: trips.v1.QueryTripsRequest.filters:type_name -> trips.v1.QueryTripsRequest.FiltersEntry
+	1,  // 5: trips.v1.QueryTripsResponse.trips:type_name -> trips.v1.Trip
+	0,  // 6: trips.v1.TripEvent.kind:type_name -> trips.v1.TripEvent.Kind
+	1,  // 7: trips.v1.TripEvent.trip:type_name -> trips.v1.Trip
+	9,  // 8: trips.v1.QueryTripsRequest.FiltersEntry.value:type_name -> trips.v1.FilterValues
+	3,  // 9: trips.v1.TripsService.CreateTrip:input_type -> trips.v1.CreateTripRequest
+	4,  // 10: trips.v1.TripsService.GetTrip:input_type -> trips.v1.GetTripRequest
+	5,  // 11: trips.v1.TripsService.UpdateTrip:input_type -> trips.v1.UpdateTripRequest
+	6,  // 12: trips.v1.TripsService.DeleteTrip:input_type -> trips.v1.DeleteTripRequest
+	8,  // 13: trips.v1.TripsService.QueryTrips:input_type -> trips.v1.QueryTripsRequest
+	11, // 14: trips.v1.TripsService.WatchTrips:input_type -> trips.v1.WatchTripsRequest
+	1,  // 15: trips.v1.TripsService.CreateTrip:output_type -> trips.v1.Trip
+	1,  // 16: trips.v1.TripsService.GetTrip:output_type -> trips.v1.Trip
+	1,  // 17: trips.v1.TripsService.UpdateTrip:output_type -> trips.v1.Trip
+	7,  // 18: trips.v1.TripsService.DeleteTrip:output_type -> trips.v1.DeleteTripResponse
+	10, // 19: trips.v1.TripsService.QueryTrips:output_type -> trips.v1.QueryTripsResponse
+	12, // 20: trips.v1.TripsService.WatchTrips:output_type -> trips.v1.TripEvent
+	15, // [15:21] is the sub-list for method output_type
+	9,  // [9:15] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_trips_v1_trips_proto_init() }
+func file_trips_v1_trips_proto_init() {
+	if File_trips_v1_trips_proto != nil {
+		return
+	}
+	file_trips_v1_trips_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_trips_v1_trips_proto_rawDesc), len(file_trips_v1_trips_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_trips_v1_trips_proto_goTypes,
+		DependencyIndexes: file_trips_v1_trips_proto_depIdxs,
+		EnumInfos:         file_trips_v1_trips_proto_enumTypes,
+		MessageInfos:      file_trips_v1_trips_proto_msgTypes,
+	}.Build()
+	File_trips_v1_trips_proto = out.File
+	file_trips_v1_trips_proto_goTypes = nil
+	file_trips_v1_trips_proto_depIdxs = nil
+}
@@ -0,0 +1,336 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: trips/v1/trips.proto
+
+// The trips service's gRPC API, for other services. It answers exactly like the REST API
+// under /v1/trips: the same validation, the same errors and the same storage.
+
+package tripsv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TripsService_CreateTrip_FullMethodName = "/trips.v1.TripsService/CreateTrip"
+	TripsService_GetTrip_FullMethodName    = "/trips.v1.TripsService/GetTrip"
+	TripsService_UpdateTrip_FullMethodName = "/trips.v1.TripsService/UpdateTrip"
+	TripsService_DeleteTrip_FullMethodName = "/trips.v1.TripsService/DeleteTrip"
+	TripsService_QueryTrips_FullMethodName = "/trips.v1.TripsService/QueryTrips"
+	TripsService_WatchTrips_FullMethodName = "/trips.v1.TripsService/WatchTrips"
+)
+
+// TripsServiceClient is the client API for TripsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TripsServiceClient interface {
+	// CreateTrip is POST /v1/trips.
+	CreateTrip(ctx context.Context, in *CreateTripRequest, opts ...grpc.CallOption) (*Trip, error)
+	// GetTrip is GET /v1/trips/{trip_id}.
+	GetTrip(ctx context.Context, in *GetTripRequest, opts ...grpc.CallOption) (*Trip, error)
+	// UpdateTrip is PUT /v1/trips/{trip_id}, changing the fields in update_mask.
+	UpdateTrip(ctx context.Context, in *UpdateTripRequest, opts ...grpc.CallOption) (*Trip, error)
+	// DeleteTrip is DELETE /v1/trips/{trip_id}.
+	DeleteTrip(ctx context.Context, in *DeleteTripRequest, opts ...grpc.CallOption) (*DeleteTripResponse, error)
+	// QueryTrips is GET /v1/trips.
+	QueryTrips(ctx context.Context, in *QueryTripsRequest, opts ...grpc.CallOption) (*QueryTripsResponse, error)
+	// WatchTrips streams an org's trips as they are created, updated and deleted, from
+	// when it's called. It needs a session of one of the org's members, sent as
+	// "authorization: Bearer <token>" metadata. Callers that fall behind are cut off with
+	// RESOURCE_EXHAUSTED and should watch again.
+	WatchTrips(ctx context.Context, in *WatchTripsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TripEvent], error)
+}
+
+type tripsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTripsServiceClient(cc grpc.ClientConnInterface) TripsServiceClient {
+	return &tripsServiceClient{cc}
+}
+
+func (c *tripsServiceClient) CreateTrip(ctx context.Context, in *CreateTripRequest, opts ...grpc.CallOption) (*Trip, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Trip)
+	err := c.cc.Invoke(ctx, TripsService_CreateTrip_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tripsServiceClient) GetTrip(ctx context.Context, in *GetTripRequest, opts ...grpc.CallOption) (*Trip, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Trip)
+	err := c.cc.Invoke(ctx, TripsService_GetTrip_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tripsServiceClient) UpdateTrip(ctx context.Context, in *UpdateTripRequest, opts ...grpc.CallOption) (*Trip, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Trip)
+	err := c.cc.Invoke(ctx, TripsService_UpdateTrip_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tripsServiceClient) DeleteTrip(ctx context.Context, in *DeleteTripRequest, opts ...grpc.CallOption) (*DeleteTripResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteTripResponse)
+	err := c.cc.Invoke(ctx, TripsService_DeleteTrip_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tripsServiceClient) QueryTrips(ctx context.Context, in *QueryTripsRequest, opts ...grpc.CallOption) (*QueryTripsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryTripsResponse)
+	err := c.cc.Invoke(ctx, TripsService_QueryTrips_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tripsServiceClient) WatchTrips(ctx context.Context, in *WatchTripsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TripEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TripsService_ServiceDesc.Streams[0], TripsService_WatchTrips_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchTripsRequest, TripEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TripsService_WatchTripsClient = grpc.ServerStreamingClient[TripEvent]
+
+// TripsServiceServer is the server API for TripsService service.
+// All implementations must embed UnimplementedTripsServiceServer
+// for forward compatibility.
+type TripsServiceServer interface {
+	// CreateTrip is POST /v1/trips.
+	CreateTrip(context.Context, *CreateTripRequest) (*Trip, error)
+	// GetTrip is GET /v1/trips/{trip_id}.
+	GetTrip(context.Context, *GetTripRequest) (*Trip, error)
+	// UpdateTrip is PUT /v1/trips/{trip_id}, changing the fields in update_mask.
+	UpdateTrip(context.Context, *UpdateTripRequest) (*Trip, error)
+	// DeleteTrip is DELETE /v1/trips/{trip_id}.
+	DeleteTrip(context.Context, *DeleteTripRequest) (*DeleteTripResponse, error)
+	// QueryTrips is GET /v1/trips.
+	QueryTrips(context.Context, *QueryTripsRequest) (*QueryTripsResponse, error)
+	// WatchTrips streams an org's trips as they are created, updated and deleted, from
+	// when it's called. It needs a session of one of the org's members, sent as
+	// "authorization: Bearer <token>" metadata. Callers that fall behind are cut off with
+	// RESOURCE_EXHAUSTED and should watch again.
+	WatchTrips(*WatchTripsRequest, grpc.ServerStreamingServer[TripEvent]) error
+	mustEmbedUnimplementedTripsServiceServer()
+}
+
+// UnimplementedTripsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTripsServiceServer struct{}
+
+func (UnimplementedTripsServiceServer) CreateTrip(context.Context, *CreateTripRequest) (*Trip, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateTrip not implemented")
+}
+func (UnimplementedTripsServiceServer) GetTrip(context.Context, *GetTripRequest) (*Trip, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTrip not implemented")
+}
+func (UnimplementedTripsServiceServer) UpdateTrip(context.Context, *UpdateTripRequest) (*Trip, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateTrip not implemented")
+}
+func (UnimplementedTripsServiceServer) DeleteTrip(context.Context, *DeleteTripRequest) (*DeleteTripResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteTrip not implemented")
+}
+func (UnimplementedTripsServiceServer) QueryTrips(context.Context, *QueryTripsRequest) (*QueryTripsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method QueryTrips not implemented")
+}
+func (UnimplementedTripsServiceServer) WatchTrips(*WatchTripsRequest, grpc.ServerStreamingServer[TripEvent]) error {
+	return status.Error(codes.Unimplemented, "method WatchTrips not implemented")
+}
+func (UnimplementedTripsServiceServer) mustEmbedUnimplementedTripsServiceServer() {}
+func (UnimplementedTripsServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeTripsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TripsServiceServer will
+// result in compilation errors.
+type UnsafeTripsServiceServer interface {
+	mustEmbedUnimplementedTripsServiceServer()
+}
+
+func RegisterTripsServiceServer(s grpc.ServiceRegistrar, srv TripsServiceServer) {
+	// If the following call panics, it indicates UnimplementedTripsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TripsService_ServiceDesc, srv)
+}
+
+func _TripsService_CreateTrip_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTripRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TripsServiceServer).CreateTrip(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TripsService_CreateTrip_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TripsServiceServer).CreateTrip(ctx, req.(*CreateTripRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TripsService_GetTrip_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTripRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TripsServiceServer).GetTrip(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TripsService_GetTrip_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TripsServiceServer).GetTrip(ctx, req.(*GetTripRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TripsService_UpdateTrip_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTripRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TripsServiceServer).UpdateTrip(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TripsService_UpdateTrip_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TripsServiceServer).UpdateTrip(ctx, req.(*UpdateTripRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TripsService_DeleteTrip_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTripRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TripsServiceServer).DeleteTrip(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TripsService_DeleteTrip_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TripsServiceServer).DeleteTrip(ctx, req.(*DeleteTripRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TripsService_QueryTrips_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryTripsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TripsServiceServer).QueryTrips(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TripsService_QueryTrips_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TripsServiceServer).QueryTrips(ctx, req.(*QueryTripsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TripsService_WatchTrips_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTripsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TripsServiceServer).WatchTrips(m, &grpc.GenericServerStream[WatchTripsRequest, TripEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TripsService_WatchTripsServer = grpc.ServerStreamingServer[TripEvent]
+
+// TripsService_ServiceDesc is the grpc.ServiceDesc for TripsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TripsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "trips.v1.TripsService",
+	HandlerType: (*TripsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateTrip",
+			Handler:    _TripsService_CreateTrip_Handler,
+		},
+		{
+			MethodName: "GetTrip",
+			Handler:    _TripsService_GetTrip_Handler,
+		},
+		{
+			MethodName: "UpdateTrip",
+			Handler:    _TripsService_UpdateTrip_Handler,
+		},
+		{
+			MethodName: "DeleteTrip",
+			Handler:    _TripsService_DeleteTrip_Handler,
+		},
+		{
+			MethodName: "QueryTrips",
+			Handler:    _TripsService_QueryTrips_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTrips",
+			Handler:       _TripsService_WatchTrips_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "trips/v1/trips.proto",
+}
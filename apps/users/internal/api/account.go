@@ -0,0 +1,160 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/users/internal/auth"
+	"github.com/Taiterbase/vtrips/apps/users/internal/notify"
+	"github.com/Taiterbase/vtrips/apps/users/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var notifier notify.Notifier = notify.NewMemory()
+
+// appBaseURL is the public origin used to build links in outbound messages.
+func appBaseURL() string {
+	if v := os.Getenv("APP_BASE_URL"); v != "" {
+		return v
+	}
+	return "http://localhost:8080"
+}
+
+func sendContactVerification(c echo.Context, u *models.User) error {
+	token, err := auth.GenerateContactToken(u.ID, u.Contact)
+	if err != nil {
+		return err
+	}
+	link := fmt.Sprintf("%s/auth/verify?token=%s", appBaseURL(), url.QueryEscape(token))
+	return notifier.Send(c.Request().Context(), notify.Message{
+		To:      u.Contact,
+		Subject: "Verify your Voluntrips email",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm this address by opening the link below within %s.\n\n%s\n",
+			u.Username, auth.VerifyContactTTL, link),
+	})
+}
+
+func sendPasswordReset(c echo.Context, u *models.User) error {
+	token, err := auth.GenerateActionToken(u.ID, auth.PurposePasswordReset, auth.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+	link := fmt.Sprintf("%s/auth/password-reset?token=%s", appBaseURL(), url.QueryEscape(token))
+	return notifier.Send(c.Request().Context(), notify.Message{
+		To:      u.Contact,
+		Subject: "Reset your Voluntrips password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset your password. The link below works once within %s.\n\n%s\n\nIf this wasn't you, you can ignore this message.\n",
+			u.Username, auth.PasswordResetTTL, link),
+	})
+}
+
+func VerifyContactHandler(c echo.Context) error {
+	token := c.FormValue("token")
+	if token == "" {
+		return problem.Message(c, http.StatusBadRequest, "missing token")
+	}
+	userID, contact, err := auth.ConsumeContactToken(token)
+	switch err {
+	case nil:
+		break
+	case auth.ErrInvalidActionToken:
+		return problem.JSON(c, http.StatusBadRequest, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	usr, err := storage.ReadUser(c, userID)
+	switch err {
+	case nil:
+		break
+	case models.ErrUserNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	// the link was sent to an address the user has since replaced
+	if usr.Contact != contact {
+		return problem.JSON(c, http.StatusBadRequest, auth.ErrInvalidActionToken)
+	}
+	usr.ContactVerified = true
+	usr.SetUpdatedAt(time.Now().Unix())
+	if err = storage.UpdateUser(c, usr); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"id": usr.ID, "contact_verified": true})
+}
+
+// PasswordResetRequestHandler always answers 200 so callers can't probe which contacts exist.
+func PasswordResetRequestHandler(c echo.Context) error {
+	contact := c.FormValue("contact")
+	if contact == "" {
+		return problem.Message(c, http.StatusBadRequest, "missing contact")
+	}
+	usr, err := findUserBy(c, "contact", contact)
+	switch err {
+	case nil:
+		if err = sendPasswordReset(c, usr); err != nil {
+			c.Logger().Error(err)
+		}
+	case models.ErrUserNotFound:
+	default:
+		c.Logger().Error(err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"ok": true})
+}
+
+func PasswordResetConfirmHandler(c echo.Context) error {
+	token := c.FormValue("token")
+	password := c.FormValue("password")
+	if token == "" || password == "" {
+		return problem.Message(c, http.StatusBadRequest, "missing required fields")
+	}
+	userID, err := auth.ConsumeActionToken(token, auth.PurposePasswordReset)
+	switch err {
+	case nil:
+		break
+	case auth.ErrInvalidActionToken:
+		return problem.JSON(c, http.StatusBadRequest, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	usr, err := storage.ReadUser(c, userID)
+	switch err {
+	case nil:
+		break
+	case models.ErrUserNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	hashBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	usr.Hash = string(hashBytes)
+	// receiving the reset message proves the user controls the contact
+	usr.ContactVerified = true
+	usr.SetUpdatedAt(time.Now().Unix())
+	if err = storage.UpdateUser(c, usr); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	// sessions issued with the old password must not outlive it
+	if err = storage.SetRevokedBefore(usr.ID, time.Now()); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	// proving control of the contact also lifts any brute-force lockout
+	if err = loginGuard.Unlock(usr.Username); err != nil {
+		c.Logger().Error(err)
+	}
+	auth.InvalidateCookie(c.Response().Writer)
+	return c.JSON(http.StatusOK, echo.Map{"ok": true})
+}
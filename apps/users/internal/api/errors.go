@@ -0,0 +1,23 @@
+package api
+
+import (
+	"github.com/Taiterbase/vtrips/apps/users/internal/auth"
+	"github.com/Taiterbase/vtrips/apps/users/internal/oidc"
+	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+)
+
+// The codes clients see for the models' errors. Errors not listed get their status's
+// code, such as not_found.
+func init() {
+	problem.Register("user_not_found", models.ErrUserNotFound)
+	problem.Register("org_not_found", models.ErrOrgNotFound)
+	problem.Register("certification_not_found", models.ErrCertificationNotFound)
+	problem.Register("identity_linked", models.ErrIdentityLinked)
+	problem.Register("unknown_provider", oidc.ErrUnknownProvider)
+	problem.Register("invalid_id_token", oidc.ErrInvalidIDToken)
+	problem.Register("invalid_action_token", auth.ErrInvalidActionToken)
+	problem.Register(problem.CodeValidationFailed,
+		models.ErrInvalidPayload, models.ErrInvalidUserID, models.ErrInvalidOrgID,
+		models.ErrInvalidAvailability)
+}
@@ -2,15 +2,19 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"reflect"
 	"time"
 
 	"github.com/RoaringBitmap/roaring/roaring64"
 	"github.com/Taiterbase/vtrips/apps/users/internal/auth"
 	"github.com/Taiterbase/vtrips/apps/users/internal/storage"
 	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
 	"github.com/cockroachdb/pebble"
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo"
 	"github.com/labstack/gommon/log"
 	"golang.org/x/crypto/bcrypt"
@@ -41,74 +45,73 @@ func DatabaseDebug(c echo.Context) error {
 	})
 }
 
-func CreateUser(c echo.Context) error {
-	trip := models.NewUser()
-	err := c.Bind(&trip)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+// badBind answers a request whose body Bind rejected, naming the field that was wrong
+// when it can.
+func badBind(c echo.Context, err error) error {
+	status := http.StatusBadRequest
+	var (
+		httpErr *echo.HTTPError
+		typeErr *json.UnmarshalTypeError
+	)
+	if errors.As(err, &httpErr) {
+		status, err = httpErr.Code, fmt.Errorf("%v", httpErr.Message)
+		if httpErr.Internal != nil {
+			err = httpErr.Internal
+		}
 	}
-
-	if err = trip.Validate(); err != nil {
-		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, err.Error())
+	if errors.As(err, &typeErr) {
+		msg := "must be " + jsonKind(typeErr.Type)
+		err = problem.Invalid(fmt.Errorf("%s %s", typeErr.Field, msg), problem.FieldError{Field: typeErr.Field, Rule: "type", Message: msg})
 	}
-
-	err = storage.CreateUser(c, trip)
-	if err != nil {
-		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, err.Error())
-	}
-
-	return c.JSON(http.StatusOK, trip)
+	return problem.JSON(c, status, err)
 }
 
-func getUser(c echo.Context, orgID, tripID string) (*models.User, error) {
-	numID, ok, err := storage.Lookup(storage.Client, tripID)
-	if err != nil {
-		return nil, err
-	}
-	if !ok { // unknown ULID
-		return nil, models.ErrUserNotFound
-	}
-
-	orgToken := models.MakeKey("org_id", orgID)
-	bm, err := storage.BitmapForToken(orgToken)
-	if err != nil {
-		return nil, err
-	}
-	if !bm.Contains(numID) {
-		return nil, models.ErrUserNotFound
+// jsonKind names a Go type the way the JSON it's read from looks.
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Slice:
+		return "a list"
+	default:
+		return "an object"
 	}
-
-	return storage.ReadUser(c, tripID)
 }
 
+// GetUser returns the public profile of any user.
 func GetUser(c echo.Context) error {
-	orgID := c.QueryParam("org_id")
-	tripID := c.Param("trip_id")
-	if orgID == "" || tripID == "" {
-		return c.JSON(http.StatusBadRequest, models.ErrInvalidUserID)
+	userID := c.Param("user_id")
+	if userID == "" {
+		return problem.JSON(c, http.StatusBadRequest, models.ErrInvalidUserID)
 	}
-	trip, err := getUser(c, orgID, tripID)
+	usr, err := storage.ReadUser(c, userID)
 	switch err {
 	case nil:
-		return c.JSON(http.StatusOK, trip)
+		return c.JSON(http.StatusOK, usr.PublicProfile())
 	case models.ErrUserNotFound:
-		return c.JSON(http.StatusNotFound, err.Error())
+		return problem.JSON(c, http.StatusNotFound, err)
 	default:
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return problem.JSON(c, http.StatusInternalServerError, err)
 	}
 }
 
+// publicFilters are the indexed fields anyone may filter users by. The rest, such as
+// contact or org_id, would let callers test which account has a private value.
+var publicFilters = []string{"skill", "language", "home_city", "home_country"}
+
 func GetUsers(c echo.Context) error {
 	scannedCount := 0
 	bitmapMap := make(map[string]*roaring64.Bitmap)
-	for key, vals := range c.QueryParams() {
-		for _, v := range vals {
-			tk := models.MakeKey(key, v)
+	for _, key := range publicFilters {
+		for _, v := range c.QueryParams()[key] {
+			tk := models.IndexKey(key, v)
 			bm, err := storage.BitmapForToken(tk)
 			if err != nil {
-				return c.JSON(http.StatusInternalServerError, err.Error())
+				return problem.JSON(c, http.StatusInternalServerError, err)
 			}
 			if orBm, ok := bitmapMap[key]; !ok {
 				bitmapMap[key] = bm
@@ -125,105 +128,44 @@ func GetUsers(c echo.Context) error {
 		bms = append(bms, bm)
 	}
 	intersection := roaring64.FastAnd(bms...)
-	if intersection.IsEmpty() {
-		return c.JSON(http.StatusOK, []models.User{})
-	}
 	c.Logger().Debugj(log.JSON{"message": "we have an intersection", "intersection": intersection})
 
-	var trips []*models.User
+	users := []*models.PublicProfile{}
 	it := intersection.Iterator()
 	for it.HasNext() {
 		numID := it.Next()
 		ulid, ok, err := storage.Reverse(storage.Client, numID)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, err.Error())
+			return problem.JSON(c, http.StatusInternalServerError, err)
 		}
 		if !ok {
 			continue
 		} // should not happen
 		t, err := storage.ReadUser(c, ulid)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, err.Error())
+			return problem.JSON(c, http.StatusInternalServerError, err)
 		}
-		trips = append(trips, t)
+		users = append(users, t.PublicProfile())
 	}
 	return c.JSON(http.StatusOK, log.JSON{
-		"trips":         trips,
-		"count":         len(trips),
+		"users":         users,
+		"count":         len(users),
 		"scanned_count": scannedCount,
 	})
 }
 
-func UpdateUser(c echo.Context) error {
-	var (
-		tripID = c.Param("trip_id")
-		orgID  = c.QueryParam("org_id")
-	)
-	trip, err := getUser(c, orgID, tripID)
-	switch err {
-	case models.ErrUserNotFound:
-		return c.JSON(http.StatusNotFound, err.Error())
-	case nil:
-		break
-	default:
-		return c.JSON(http.StatusInternalServerError, err.Error())
-	}
-	if err = c.Bind(&trip); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
-	}
-	trip.SetUpdatedAt(time.Now().Unix())
-	err = storage.UpdateUser(c, trip)
-	if err != nil {
-		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, err.Error())
-	}
-
-	return c.JSON(http.StatusOK, nil)
-}
-
-func DeleteUser(c echo.Context) error {
-	var (
-		orgID  = c.QueryParam("org_id")
-		tripID = c.Param("trip_id")
-	)
-	trip, err := getUser(c, orgID, tripID)
-	switch err {
-	case models.ErrUserNotFound:
-		return c.JSON(http.StatusNotFound, err.Error())
-	case nil:
-		break
-	default:
-		return c.JSON(http.StatusInternalServerError, err.Error())
-	}
-	err = storage.DeleteUser(c, trip)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
-	}
-	return c.JSON(http.StatusOK, tripID)
-}
-
 func SignUpHandler(c echo.Context) error {
 	username := c.FormValue("username")
 	contact := c.FormValue("contact")
 	password := c.FormValue("password")
 	if username == "" || contact == "" || password == "" {
-		return c.JSON(http.StatusBadRequest, "missing required fields")
-	}
-
-	// Enforce unique username via equality index bitmap
-	unameToken := models.MakeKey("username", username)
-	existingBm, err := storage.BitmapForToken(unameToken)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
-	}
-	if !existingBm.IsEmpty() {
-		return c.JSON(http.StatusConflict, "username already exists")
+		return problem.Message(c, http.StatusBadRequest, "missing required fields")
 	}
 
 	// hash password
 	hashBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		return problem.JSON(c, http.StatusInternalServerError, err)
 	}
 
 	u := models.NewUser()
@@ -233,53 +175,102 @@ func SignUpHandler(c echo.Context) error {
 	u.Hash = string(hashBytes)
 
 	if err := u.Validate(); err != nil {
-		return c.JSON(http.StatusBadRequest, err.Error())
+		return problem.JSON(c, http.StatusBadRequest, err)
 	}
 
+	// storage enforces unique usernames and contacts atomically with the write
 	if err := storage.CreateUser(c, u); err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+		if isConflict(err) {
+			return problem.JSON(c, http.StatusConflict, err)
+		}
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	if err := sendContactVerification(c, u); err != nil {
+		c.Logger().Error(err)
 	}
 
 	_ = auth.SetCookieWithJWT(c.Response().Writer, u.ID, u.Username, "")
 	return c.JSON(http.StatusOK, echo.Map{"id": u.ID, "username": u.Username})
 }
 
+// isConflict reports whether err is a unique-constraint violation from storage.
+func isConflict(err error) bool {
+	var conflict *models.ErrConflict
+	return errors.As(err, &conflict)
+}
+
+// findUserBy resolves a user through an equality index such as username or contact.
+func findUserBy(c echo.Context, field, value string) (*models.User, error) {
+	bm, err := storage.BitmapForToken(models.IndexKey(field, value))
+	if err != nil {
+		return nil, err
+	}
+	// usernames and contacts are unique, so there is at most one match
+	it := bm.Iterator()
+	if !it.HasNext() {
+		return nil, models.ErrUserNotFound
+	}
+	ulid, ok, err := storage.Reverse(storage.Client, it.Next())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, models.ErrUserNotFound
+	}
+	return storage.ReadUser(c, ulid)
+}
+
 func LoginHandler(c echo.Context) error {
 	username := c.FormValue("username")
 	password := c.FormValue("password")
 	if username == "" || password == "" {
-		return c.JSON(http.StatusBadRequest, "missing credentials")
+		return problem.Message(c, http.StatusBadRequest, "missing credentials")
 	}
 
-	// Lookup by username index
-	token := models.MakeKey("username", username)
-	bm, err := storage.BitmapForToken(token)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+	if throttled, err := loginThrottled(c, username); throttled {
+		return err
 	}
-	if bm.IsEmpty() {
-		return c.JSON(http.StatusUnauthorized, "invalid credentials")
+
+	usr, err := findUserBy(c, "username", username)
+	switch err {
+	case nil:
+		break
+	case models.ErrUserNotFound:
+		loginFailed(c, username)
+		return problem.Message(c, http.StatusUnauthorized, "invalid credentials")
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
 	}
-	// Use the first match (usernames should be unique)
-	it := bm.Iterator()
-	if !it.HasNext() {
-		return c.JSON(http.StatusUnauthorized, "invalid credentials")
+	// compare hash
+	if err := bcrypt.CompareHashAndPassword([]byte(usr.GetHash()), []byte(password)); err != nil {
+		loginFailed(c, username)
+		return problem.Message(c, http.StatusUnauthorized, "invalid credentials")
 	}
-	numID := it.Next()
-	ulid, ok, err := storage.Reverse(storage.Client, numID)
-	if err != nil || !ok {
-		return c.JSON(http.StatusUnauthorized, "invalid credentials")
+
+	// password is right, so its counter goes even if a second factor is still owed;
+	// the TOTP step has a counter of its own
+	loginSucceeded(c, username)
+	if usr.TOTPEnabled {
+		mfaToken, err := auth.GenerateActionToken(usr.GetID(), auth.PurposeLoginTOTP, auth.LoginTOTPTTL)
+		if err != nil {
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+		return c.JSON(http.StatusAccepted, echo.Map{"totp_required": true, "mfa_token": mfaToken})
 	}
-	usr, err := storage.ReadUser(c, ulid)
+	required, err := totpRequired(usr)
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, "invalid credentials")
+		return problem.JSON(c, http.StatusInternalServerError, err)
 	}
-	// compare hash
-	if err := bcrypt.CompareHashAndPassword([]byte(usr.GetHash()), []byte(password)); err != nil {
-		return c.JSON(http.StatusUnauthorized, "invalid credentials")
+	if required {
+		mfaToken, err := auth.GenerateActionToken(usr.GetID(), auth.PurposeEnrollTOTP, auth.EnrollTOTPTTL)
+		if err != nil {
+			return problem.JSON(c, http.StatusInternalServerError, err)
+		}
+		return c.JSON(http.StatusAccepted, echo.Map{"totp_enrollment_required": true, "mfa_token": mfaToken})
 	}
 
-	_ = auth.SetCookieWithJWT(c.Response().Writer, usr.GetID(), usr.GetUsername(), "")
+	_ = auth.SetCookieWithJWT(c.Response().Writer, usr.GetID(), usr.GetUsername(), usr.OrgID)
 	return c.JSON(http.StatusOK, echo.Map{"id": usr.GetID(), "username": usr.GetUsername()})
 }
 
@@ -290,7 +281,7 @@ func LogoutHandler(c echo.Context) error {
 		if claims, err := auth.Validate(cookie.Value); err == nil {
 			if m, ok := claims.(jwt.MapClaims); ok {
 				if sub, ok := m["sub"].(string); ok && sub != "" {
-					_ = storage.SetRevokedBefore(sub, time.Now().Unix())
+					_ = storage.SetRevokedBefore(sub, time.Now())
 				}
 			}
 		}
@@ -298,3 +289,9 @@ func LogoutHandler(c echo.Context) error {
 	auth.InvalidateCookie(c.Response().Writer)
 	return c.JSON(http.StatusOK, echo.Map{"ok": true})
 }
+
+// SessionHandler answers 204 while the caller's session is live: signed, unexpired and
+// not revoked. Other services ask it before trusting a session.
+func SessionHandler(c echo.Context) error {
+	return c.NoContent(http.StatusNoContent)
+}
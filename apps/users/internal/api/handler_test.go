@@ -0,0 +1,183 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/users/internal/auth"
+	"github.com/Taiterbase/vtrips/apps/users/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newTestAPI serves the users API from a scratch database.
+func newTestAPI(t *testing.T) *echo.Echo {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret")
+	t.Setenv("ENVIRONMENT", "development")
+	t.Setenv("APP_BASE_URL", "http://app.test")
+	if err := storage.Open(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.Client.Close() })
+
+	e := echo.New()
+	e.Logger.SetOutput(io.Discard)
+	e.HTTPErrorHandler = problem.HTTPErrorHandler
+	setupRouters(e)
+	return e
+}
+
+// createUser stores a user signed up with password, or with no password when it's "".
+func createUser(t *testing.T, e *echo.Echo, username, password string, skills ...string) *models.User {
+	t.Helper()
+	usr := models.NewUser()
+	usr.Username = username
+	usr.Contact = username + "@example.com"
+	usr.ContactMethod = "email"
+	usr.Skills = skills
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		usr.Hash = string(hash)
+	}
+	if err := storage.CreateUser(e.NewContext(nil, nil), usr); err != nil {
+		t.Fatal(err)
+	}
+	return usr
+}
+
+func sessionCookie(t *testing.T, usr *models.User) *http.Cookie {
+	t.Helper()
+	token, err := auth.GenerateJWT(usr.ID, usr.Username, usr.OrgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &http.Cookie{Name: auth.AuthCookieName, Value: token}
+}
+
+func serve(e *echo.Echo, target string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	return serveForm(e, http.MethodGet, target, nil, cookies...)
+}
+
+func serveForm(e *echo.Echo, method, target string, form url.Values, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+	if form != nil {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	}
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func responseCookie(rec *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestGetUsersOnlyFiltersByPublicFields(t *testing.T) {
+	e := newTestAPI(t)
+	alice := createUser(t, e, "alice", "", "first aid")
+	createUser(t, e, "bob", "", "first aid", "carpentry")
+
+	for query, want := range map[string][]string{
+		"?skill=carpentry":                           {"bob"},
+		"?skill=first%20aid&skill=carpentry":         {"alice", "bob"},
+		"?skill=first%20aid&contact=bob@example.com": {"alice", "bob"},
+		"?contact=" + url.QueryEscape(alice.Contact): nil,
+		"?org_id=&username=alice":                    nil,
+	} {
+		rec := serve(e, "/v1/users"+query)
+		var got struct {
+			Users []models.PublicProfile `json:"users"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); rec.Code != http.StatusOK || err != nil {
+			t.Fatalf("GET /v1/users%s: %d %s", query, rec.Code, rec.Body)
+		}
+		var names []string
+		for _, u := range got.Users {
+			names = append(names, u.Username)
+		}
+		if strings.Join(names, ",") != strings.Join(want, ",") {
+			t.Errorf("GET /v1/users%s = %v, want %v", query, names, want)
+		}
+	}
+}
+
+func TestChangePasswordSignsOutEarlierSessions(t *testing.T) {
+	e := newTestAPI(t)
+	usr := createUser(t, e, "alice", "old password")
+	// issued in the same second as the change, which a whole-second cutoff let through
+	session := sessionCookie(t, usr)
+
+	rec := serveForm(e, http.MethodPost, "/v1/users/me/password", url.Values{
+		"current_password": {"old password"},
+		"new_password":     {"new password"},
+	}, session)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("change password: %d %s", rec.Code, rec.Body)
+	}
+	replacement := responseCookie(rec, auth.AuthCookieName)
+	if replacement == nil {
+		t.Fatal("change password didn't reissue the session")
+	}
+	if rec = serve(e, "/v1/users/me", session); rec.Code != http.StatusUnauthorized {
+		t.Errorf("the session from before the change: %d %s", rec.Code, rec.Body)
+	}
+	if rec = serve(e, "/v1/users/me", replacement); rec.Code != http.StatusOK {
+		t.Errorf("the replacement session: %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestSessionCheckRejectsLapsedSessions(t *testing.T) {
+	e := newTestAPI(t)
+	usr := createUser(t, e, "alice", "password")
+	signed := func(claims jwt.MapClaims) *http.Cookie {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &http.Cookie{Name: auth.AuthCookieName, Value: token}
+	}
+	now := time.Now()
+	// issued a moment ago, so signing out now revokes it
+	session := signed(jwt.MapClaims{"sub": usr.ID, "iat": now.Unix(), "iat_ms": now.Add(-time.Second).UnixMilli(), "exp": now.Add(time.Hour).Unix()})
+	for _, tc := range []struct {
+		name    string
+		session *http.Cookie
+		want    int
+	}{
+		{"live", session, http.StatusNoContent},
+		{"without an expiry", signed(jwt.MapClaims{"sub": usr.ID, "iat": now.Unix()}), http.StatusUnauthorized},
+		{"expired", signed(jwt.MapClaims{"sub": usr.ID, "iat": now.Unix(), "exp": now.Add(-time.Minute).Unix()}), http.StatusUnauthorized},
+	} {
+		if rec := serve(e, "/v1/users/auth/session", tc.session); rec.Code != tc.want {
+			t.Errorf("%s session: %d, want %d", tc.name, rec.Code, tc.want)
+		}
+	}
+
+	if rec := serveForm(e, http.MethodPost, "/v1/users/auth/logout", nil, session); rec.Code != http.StatusOK {
+		t.Fatalf("logout: %d %s", rec.Code, rec.Body)
+	}
+	if rec := serve(e, "/v1/users/auth/session", session); rec.Code != http.StatusUnauthorized {
+		t.Errorf("session after signing out everywhere: %d", rec.Code)
+	}
+}
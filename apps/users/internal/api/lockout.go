@@ -0,0 +1,180 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/users/internal/auth"
+	"github.com/Taiterbase/vtrips/apps/users/internal/lockout"
+	"github.com/Taiterbase/vtrips/apps/users/internal/notify"
+	"github.com/Taiterbase/vtrips/apps/users/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+)
+
+var loginGuard = lockout.New(lockout.DefaultConfig(), lockout.SystemClock)
+
+// trustedProxies are the networks, from the comma-separated CIDRs in TRUSTED_PROXIES,
+// whose X-Forwarded-For is believed. Anyone else could name any address in it and get a
+// fresh IP counter for every guess.
+var trustedProxies []*net.IPNet
+
+func trustedProxiesFromEnv() ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, cidr := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if cidr = strings.TrimSpace(cidr); cidr == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("TRUSTED_PROXIES: %w", err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+func trustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	for _, ipNet := range trustedProxies {
+		if ip != nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP is the address the login counters key on: the peer's, or when the peer is a
+// trusted proxy, the last X-Forwarded-For hop that isn't one.
+func clientIP(c echo.Context) string {
+	addr, _, err := net.SplitHostPort(c.Request().RemoteAddr)
+	if err != nil {
+		addr = c.Request().RemoteAddr
+	}
+	hops := strings.Split(c.Request().Header.Get(echo.HeaderXForwardedFor), ",")
+	for i := len(hops) - 1; i >= 0 && trustedProxy(addr); i-- {
+		if hop := strings.TrimSpace(hops[i]); hop != "" {
+			addr = hop
+		}
+	}
+	return addr
+}
+
+// loginThrottled answers 429 with Retry-After when username or the client IP must back off.
+// It returns true when the response has been written and the handler should stop.
+func loginThrottled(c echo.Context, username string) (bool, error) {
+	wait, locked, err := loginGuard.Check(username, clientIP(c))
+	return throttled(c, wait, locked, err)
+}
+
+// secondFactorThrottled is loginThrottled for u's TOTP and recovery codes.
+func secondFactorThrottled(c echo.Context, u *models.User) (bool, error) {
+	wait, locked, err := loginGuard.CheckSecondFactor(u.ID, clientIP(c))
+	return throttled(c, wait, locked, err)
+}
+
+func throttled(c echo.Context, wait time.Duration, locked bool, err error) (bool, error) {
+	if err != nil {
+		return true, problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if wait <= 0 {
+		return false, nil
+	}
+	c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	if locked {
+		return true, problem.Message(c, http.StatusTooManyRequests, "account temporarily locked")
+	}
+	return true, problem.Message(c, http.StatusTooManyRequests, "too many login attempts")
+}
+
+// loginFailed records a failed attempt and emails an unlock link when it locks the account.
+func loginFailed(c echo.Context, username string) {
+	lockedNow, err := loginGuard.Fail(username, clientIP(c))
+	if err != nil {
+		c.Logger().Error(err)
+		return
+	}
+	if !lockedNow {
+		return
+	}
+	usr, err := findUserBy(c, "username", username)
+	if err != nil {
+		if err != models.ErrUserNotFound {
+			c.Logger().Error(err)
+		}
+		return
+	}
+	if err = sendUnlock(c, usr); err != nil {
+		c.Logger().Error(err)
+	}
+}
+
+// secondFactorFailed records a wrong TOTP or recovery code for u.
+func secondFactorFailed(c echo.Context, u *models.User) {
+	if _, err := loginGuard.FailSecondFactor(u.ID, clientIP(c)); err != nil {
+		c.Logger().Error(err)
+	}
+}
+
+func loginSucceeded(c echo.Context, username string) {
+	if err := loginGuard.Succeed(username); err != nil {
+		c.Logger().Error(err)
+	}
+}
+
+func secondFactorSucceeded(c echo.Context, u *models.User) {
+	if err := loginGuard.SucceedSecondFactor(u.ID); err != nil {
+		c.Logger().Error(err)
+	}
+}
+
+func sendUnlock(c echo.Context, u *models.User) error {
+	token, err := auth.GenerateActionToken(u.ID, auth.PurposeUnlockAccount, auth.UnlockAccountTTL)
+	if err != nil {
+		return err
+	}
+	link := fmt.Sprintf("%s/auth/unlock?token=%s", appBaseURL(), url.QueryEscape(token))
+	return notifier.Send(c.Request().Context(), notify.Message{
+		To:      u.Contact,
+		Subject: "Your Voluntrips account was locked",
+		Body: fmt.Sprintf("Hi %s,\n\nWe locked your account after several failed sign-in attempts. If that was you, unlock it now with the link below (valid for %s). If not, consider resetting your password.\n\n%s\n",
+			u.Username, auth.UnlockAccountTTL, link),
+	})
+}
+
+func UnlockAccountHandler(c echo.Context) error {
+	token := c.FormValue("token")
+	if token == "" {
+		return problem.Message(c, http.StatusBadRequest, "missing token")
+	}
+	userID, err := auth.ConsumeActionToken(token, auth.PurposeUnlockAccount)
+	switch err {
+	case nil:
+		break
+	case auth.ErrInvalidActionToken:
+		return problem.JSON(c, http.StatusBadRequest, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	usr, err := storage.ReadUser(c, userID)
+	switch err {
+	case nil:
+		break
+	case models.ErrUserNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if err = loginGuard.Unlock(usr.Username); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"ok": true, "unlocked_at": time.Now().Unix()})
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+)
+
+func TestClientIP(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.1/32")
+	proxies, err := trustedProxiesFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trustedProxies = proxies
+	t.Cleanup(func() { trustedProxies = nil })
+
+	e := echo.New()
+	for _, tc := range []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{"direct", "203.0.113.7:5000", "", "203.0.113.7"},
+		{"a client naming any address it likes", "203.0.113.7:5000", "198.51.100.1", "203.0.113.7"},
+		{"through a trusted proxy", "10.1.2.3:5000", "198.51.100.1", "198.51.100.1"},
+		{"a spoofed hop before the proxies", "10.1.2.3:5000", "1.1.1.1, 198.51.100.1, 192.0.2.1", "198.51.100.1"},
+		{"a trusted proxy without the header", "10.1.2.3:5000", "", "10.1.2.3"},
+		{"only proxies", "10.1.2.3:5000", "10.9.9.9", "10.9.9.9"},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/users/auth/login", nil)
+		req.RemoteAddr = tc.remoteAddr
+		if tc.forwarded != "" {
+			req.Header.Set(echo.HeaderXForwardedFor, tc.forwarded)
+		}
+		if got := clientIP(e.NewContext(req, httptest.NewRecorder())); got != tc.want {
+			t.Errorf("%s: clientIP = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0")
+	if _, err = trustedProxiesFromEnv(); err == nil {
+		t.Error("TRUSTED_PROXIES without a prefix length was accepted")
+	}
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/users/internal/auth"
+	"github.com/Taiterbase/vtrips/apps/users/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// currentUser loads the user behind the request's session. Routes using it sit behind requireSession.
+func currentUser(c echo.Context) (*models.User, error) {
+	return storage.ReadUser(c, sessionUserID(c))
+}
+
+func GetMe(c echo.Context) error {
+	usr, err := currentUser(c)
+	switch err {
+	case nil:
+		return c.JSON(http.StatusOK, usr.Profile())
+	case models.ErrUserNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+}
+
+// UpdateMe applies a partial profile update. Changing the contact clears its verified
+// flag and sends a fresh verification message; passwords change through ChangePasswordHandler.
+func UpdateMe(c echo.Context) error {
+	usr, err := currentUser(c)
+	switch err {
+	case nil:
+		break
+	case models.ErrUserNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	var update models.ProfileUpdate
+	if err = c.Bind(&update); err != nil {
+		return badBind(c, err)
+	}
+	if (update.Username != nil && strings.TrimSpace(*update.Username) == "") ||
+		(update.Contact != nil && strings.TrimSpace(*update.Contact) == "") {
+		return problem.Message(c, http.StatusBadRequest, "username and contact cannot be empty")
+	}
+	oldUsername := usr.Username
+	contactChanged := update.Apply(usr)
+	if contactChanged {
+		usr.ContactVerified = false
+	}
+	if err = usr.Validate(); err != nil {
+		return problem.JSON(c, http.StatusBadRequest, err)
+	}
+	usr.SetUpdatedAt(time.Now().Unix())
+	err = storage.UpdateUser(c, usr)
+	if isConflict(err) {
+		return problem.JSON(c, http.StatusConflict, err)
+	}
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	if contactChanged {
+		if err = sendContactVerification(c, usr); err != nil {
+			c.Logger().Error(err)
+		}
+	}
+	// the session token carries the username, so reissue it after a rename
+	if usr.Username != oldUsername {
+		_ = auth.SetCookieWithJWT(c.Response().Writer, usr.ID, usr.Username, usr.OrgID)
+	}
+	return c.JSON(http.StatusOK, usr.Profile())
+}
+
+// DeleteMe removes the signed-in user's account, releases their linked identities and ends every session.
+func DeleteMe(c echo.Context) error {
+	usr, err := currentUser(c)
+	switch err {
+	case nil:
+		break
+	case models.ErrUserNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if err = storage.DeleteUser(c, usr); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if usr.AvatarType != "" {
+		if err = storage.DeleteAvatar(usr.ID); err != nil {
+			c.Logger().Error(err)
+		}
+	}
+	for _, identity := range usr.Identities {
+		if err = storage.UnlinkIdentity(identity.Issuer, identity.Subject); err != nil {
+			c.Logger().Error(err)
+		}
+	}
+	if err = storage.SetRevokedBefore(usr.ID, time.Now()); err != nil {
+		c.Logger().Error(err)
+	}
+	auth.InvalidateCookie(c.Response().Writer)
+	return c.JSON(http.StatusOK, echo.Map{"id": usr.ID, "deleted": true})
+}
+
+// ChangePasswordHandler requires the current password again before setting a new one,
+// so a stolen session alone can't take over the account. Wrong guesses count toward
+// the same lockout as failed logins. Other sessions are signed out.
+func ChangePasswordHandler(c echo.Context) error {
+	current := c.FormValue("current_password")
+	password := c.FormValue("new_password")
+	if current == "" || password == "" {
+		return problem.Message(c, http.StatusBadRequest, "missing required fields")
+	}
+	usr, err := currentUser(c)
+	switch err {
+	case nil:
+		break
+	case models.ErrUserNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if usr.Hash == "" {
+		return problem.Message(c, http.StatusConflict, "no password is set; use password reset to create one")
+	}
+
+	if throttled, err := loginThrottled(c, usr.Username); throttled {
+		return err
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(usr.Hash), []byte(current)); err != nil {
+		loginFailed(c, usr.Username)
+		return problem.Message(c, http.StatusUnauthorized, "invalid credentials")
+	}
+	loginSucceeded(c, usr.Username)
+
+	hashBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	usr.Hash = string(hashBytes)
+	usr.SetUpdatedAt(time.Now().Unix())
+	if err = storage.UpdateUser(c, usr); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	// sign out every session issued before now; the replacement cookie below comes after
+	if err = storage.SetRevokedBefore(usr.ID, time.Now()); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	_ = auth.SetCookieWithJWT(c.Response().Writer, usr.ID, usr.Username, usr.OrgID)
+	return c.JSON(http.StatusOK, echo.Map{"ok": true})
+}
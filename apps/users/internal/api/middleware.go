@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Taiterbase/vtrips/apps/users/internal/auth"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+	"github.com/oklog/ulid/v2"
+)
+
+const ctxUserID = "user_id"
+
+// requestID tags each request with an ID, the caller's X-Request-ID when it sent one,
+// and echoes it back so error responses and log lines can be matched up.
+func requestID(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Request().Header.Get(echo.HeaderXRequestID)
+		if id == "" || len(id) > 128 {
+			id = ulid.Make().String()
+		}
+		c.Response().Header().Set(echo.HeaderXRequestID, id)
+		return next(c)
+	}
+}
+
+// sessionUserID returns the user ID carried by the request's auth cookie, or "" when there is no valid session.
+func sessionUserID(c echo.Context) string {
+	if v, ok := c.Get(ctxUserID).(string); ok {
+		return v
+	}
+	cookie, err := c.Cookie(auth.AuthCookieName)
+	if err != nil || cookie == nil || cookie.Value == "" {
+		return ""
+	}
+	sub, err := auth.SubjectFromToken(cookie.Value)
+	if err != nil {
+		return ""
+	}
+	return sub
+}
+
+// requireSession rejects requests without a valid auth cookie and stores the caller's user ID on the context.
+func requireSession(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		sub := sessionUserID(c)
+		if sub == "" {
+			return problem.Message(c, http.StatusUnauthorized, "unauthorized")
+		}
+		c.Set(ctxUserID, sub)
+		return next(c)
+	}
+}
@@ -0,0 +1,285 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/users/internal/auth"
+	"github.com/Taiterbase/vtrips/apps/users/internal/oidc"
+	"github.com/Taiterbase/vtrips/apps/users/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+)
+
+// oidcStateTTL bounds how long a user may spend at the provider before the callback.
+const oidcStateTTL = 10 * time.Minute
+
+var (
+	oidcProviders = map[string]*oidc.Provider{}
+
+	usernameUnsafe = regexp.MustCompile(`[^a-z0-9_.-]+`)
+)
+
+func OIDCProvidersHandler(c echo.Context) error {
+	names := make([]string, 0, len(oidcProviders))
+	for name := range oidcProviders {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return c.JSON(http.StatusOK, echo.Map{"providers": names, "count": len(names)})
+}
+
+// OIDCStartHandler redirects the browser to the provider. When the caller is already
+// signed in, the resulting identity is linked to their account instead of signing in.
+func OIDCStartHandler(c echo.Context) error {
+	provider, ok := oidcProviders[c.Param("provider")]
+	if !ok {
+		return problem.JSON(c, http.StatusNotFound, oidc.ErrUnknownProvider)
+	}
+	state, err := oidc.RandomString(24)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	nonce, err := oidc.RandomString(24)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	err = storage.PutOIDCState(state, &models.OIDCLoginState{
+		Provider:     provider.Name,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		LinkUserID:   sessionUserID(c),
+		ExpiresAt:    time.Now().Add(oidcStateTTL).Unix(),
+	})
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	authURL, err := provider.AuthCodeURL(c.Request().Context(), state, nonce, challenge)
+	if err != nil {
+		return problem.JSON(c, http.StatusBadGateway, err)
+	}
+	auth.SetOIDCStateCookie(c.Response().Writer, state, oidcStateTTL)
+	return c.Redirect(http.StatusFound, authURL)
+}
+
+func OIDCCallbackHandler(c echo.Context) error {
+	provider, ok := oidcProviders[c.Param("provider")]
+	if !ok {
+		return problem.JSON(c, http.StatusNotFound, oidc.ErrUnknownProvider)
+	}
+	if e := c.QueryParam("error"); e != "" {
+		return problem.Message(c, http.StatusUnauthorized, "the identity provider refused the sign-in: "+e)
+	}
+	// the state must come back to the browser that started the flow, or anyone could
+	// finish their own sign-in or link request in someone else's browser
+	state := c.QueryParam("state")
+	if !auth.OIDCStateMatches(c.Request(), state) {
+		return problem.Message(c, http.StatusBadRequest, "invalid or expired state")
+	}
+	auth.ClearOIDCStateCookie(c.Response().Writer)
+	login, ok, err := storage.ConsumeOIDCState(state)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if !ok || login.Provider != provider.Name {
+		return problem.Message(c, http.StatusBadRequest, "invalid or expired state")
+	}
+	if login.LinkUserID != "" && login.LinkUserID != sessionUserID(c) {
+		return problem.Message(c, http.StatusForbidden, "sign in as the account you are linking to")
+	}
+	claims, err := provider.Exchange(c.Request().Context(), c.QueryParam("code"), login.CodeVerifier, login.Nonce)
+	if err != nil {
+		c.Logger().Error(err)
+		return problem.JSON(c, http.StatusUnauthorized, oidc.ErrInvalidIDToken)
+	}
+
+	usr, err := resolveIdentity(c, provider, claims, login.LinkUserID)
+	if isConflict(err) {
+		// an unverified account already holds this email; it must be linked explicitly
+		return problem.JSON(c, http.StatusConflict, err)
+	}
+	switch err {
+	case nil:
+		break
+	case models.ErrIdentityLinked:
+		return problem.JSON(c, http.StatusConflict, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	if login.LinkUserID != "" {
+		return c.Redirect(http.StatusFound, appBaseURL()+"/")
+	}
+	// social sign-in is a first factor like a password; 2FA still applies
+	if usr.TOTPEnabled {
+		return redirectSecondStep(c, usr, auth.PurposeLoginTOTP, auth.LoginTOTPTTL, "totp")
+	}
+	required, err := totpRequired(usr)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if required {
+		return redirectSecondStep(c, usr, auth.PurposeEnrollTOTP, auth.EnrollTOTPTTL, "enroll")
+	}
+	_ = auth.SetCookieWithJWT(c.Response().Writer, usr.ID, usr.Username, usr.OrgID)
+	return c.Redirect(http.StatusFound, appBaseURL()+"/")
+}
+
+func redirectSecondStep(c echo.Context, usr *models.User, purpose string, ttl time.Duration, kind string) error {
+	mfaToken, err := auth.GenerateActionToken(usr.ID, purpose, ttl)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	q := url.Values{}
+	q.Set("mfa_token", mfaToken)
+	q.Set("kind", kind)
+	return c.Redirect(http.StatusFound, appBaseURL()+"/auth/second-step?"+q.Encode())
+}
+
+// resolveIdentity finds or creates the user for an external identity. Identities already
+// linked sign in as their user; otherwise the identity is linked to linkUserID, to an
+// existing account whose verified contact matches the provider's verified email, or to a
+// brand new account.
+func resolveIdentity(c echo.Context, provider *oidc.Provider, claims *oidc.Claims, linkUserID string) (*models.User, error) {
+	userID, linked, err := storage.LookupIdentity(claims.Issuer, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if linked {
+		if linkUserID != "" && linkUserID != userID {
+			return nil, models.ErrIdentityLinked
+		}
+		return storage.ReadUser(c, userID)
+	}
+
+	identity := models.Identity{
+		Provider: provider.Name,
+		Issuer:   claims.Issuer,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+		LinkedAt: time.Now().Unix(),
+	}
+
+	var usr *models.User
+	if linkUserID != "" {
+		usr, err = storage.ReadUser(c, linkUserID)
+		if err != nil {
+			return nil, err
+		}
+	} else if claims.EmailVerified && claims.Email != "" {
+		usr, err = findUserBy(c, "contact", claims.Email)
+		if err != nil && err != models.ErrUserNotFound {
+			return nil, err
+		}
+		// only trust the match when both sides have proven ownership of the address
+		if usr != nil && !usr.ContactVerified {
+			usr = nil
+		}
+	}
+
+	if usr == nil {
+		return createUserFromIdentity(c, claims, identity)
+	}
+	usr.Identities = append(usr.Identities, identity)
+	usr.SetUpdatedAt(time.Now().Unix())
+	if err = storage.UpdateUser(c, usr); err != nil {
+		return nil, err
+	}
+	return usr, storage.LinkIdentity(claims.Issuer, claims.Subject, usr.ID)
+}
+
+func createUserFromIdentity(c echo.Context, claims *oidc.Claims, identity models.Identity) (*models.User, error) {
+	username, err := availableUsername(c, claims)
+	if err != nil {
+		return nil, err
+	}
+	u := models.NewUser()
+	u.Username = username
+	u.Contact = claims.Email
+	u.ContactMethod = "email"
+	u.ContactVerified = claims.EmailVerified
+	u.Identities = []models.Identity{identity}
+	if err = u.Validate(); err != nil {
+		return nil, err
+	}
+	if err = storage.CreateUser(c, u); err != nil {
+		return nil, err
+	}
+	return u, storage.LinkIdentity(claims.Issuer, claims.Subject, u.ID)
+}
+
+// availableUsername derives a username from the provider's claims, adding a numeric
+// suffix until it doesn't collide with an existing account.
+func availableUsername(c echo.Context, claims *oidc.Claims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	if base == "" {
+		base = claims.Name
+	}
+	base = strings.Trim(usernameUnsafe.ReplaceAllString(strings.ToLower(base), "-"), "-")
+	if base == "" {
+		base = "volunteer"
+	}
+	for i := 0; ; i++ {
+		candidate := base
+		if i > 0 {
+			candidate = base + strconv.Itoa(i)
+		}
+		_, err := findUserBy(c, "username", candidate)
+		if err == models.ErrUserNotFound {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// UnlinkIdentityHandler removes a linked identity from the signed-in user, as long as
+// it isn't their only way to sign in.
+func UnlinkIdentityHandler(c echo.Context) error {
+	provider, ok := oidcProviders[c.Param("provider")]
+	if !ok {
+		return problem.JSON(c, http.StatusNotFound, oidc.ErrUnknownProvider)
+	}
+	usr, err := storage.ReadUser(c, sessionUserID(c))
+	switch err {
+	case nil:
+		break
+	case models.ErrUserNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	i := slices.IndexFunc(usr.Identities, func(id models.Identity) bool { return id.Issuer == provider.Issuer })
+	if i < 0 {
+		return problem.Message(c, http.StatusNotFound, "identity not linked")
+	}
+	if usr.Hash == "" && len(usr.Identities) == 1 {
+		return problem.Message(c, http.StatusConflict, "set a password before removing your only sign-in method")
+	}
+	identity := usr.Identities[i]
+	usr.Identities = slices.Delete(usr.Identities, i, i+1)
+	usr.SetUpdatedAt(time.Now().Unix())
+	if err = storage.UpdateUser(c, usr); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if err = storage.UnlinkIdentity(identity.Issuer, identity.Subject); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"id": usr.ID, "identities": usr.Identities})
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/users/internal/auth"
+	"github.com/Taiterbase/vtrips/apps/users/internal/oidc"
+	"github.com/Taiterbase/vtrips/apps/users/internal/storage"
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo"
+)
+
+const mockClientID = "vtrips-test"
+
+// mockProvider is an OpenID Provider on an httptest server: discovery, a JWKS with one
+// RSA key, and a token endpoint that redeems the codes authorize hands out.
+type mockProvider struct {
+	srv *httptest.Server
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]mockGrant
+}
+
+type mockGrant struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockProvider{key: key, codes: map[string]mockGrant{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.srv.URL,
+			"authorization_endpoint": m.srv.URL + "/authorize",
+			"token_endpoint":         m.srv.URL + "/token",
+			"jwks_uri":               m.srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", m.token)
+	m.srv = httptest.NewServer(mux)
+	t.Cleanup(m.srv.Close)
+	return m
+}
+
+// authorize plays the user approving the sign-in at the provider: it takes the
+// authorization request the start handler redirected to and returns the callback URL
+// the provider would send the browser back to.
+func (m *mockProvider) authorize(t *testing.T, authURL, subject, email string) string {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if !strings.HasPrefix(authURL, m.srv.URL+"/authorize?") || q.Get("client_id") != mockClientID {
+		t.Fatalf("start redirected to %s", authURL)
+	}
+	code, err := oidc.RandomString(16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.mu.Lock()
+	m.codes[code] = mockGrant{challenge: q.Get("code_challenge"), claims: jwt.MapClaims{
+		"iss":            m.srv.URL,
+		"aud":            mockClientID,
+		"sub":            subject,
+		"email":          email,
+		"email_verified": true,
+		"nonce":          q.Get("nonce"),
+		"exp":            time.Now().Add(time.Minute).Unix(),
+	}}
+	m.mu.Unlock()
+	callback := url.Values{"code": {code}, "state": {q.Get("state")}}
+	return q.Get("redirect_uri") + "?" + callback.Encode()
+}
+
+func (m *mockProvider) token(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	grant, ok := m.codes[r.FormValue("code")]
+	delete(m.codes, r.FormValue("code"))
+	m.mu.Unlock()
+	sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, grant.claims)
+	token.Header["kid"] = "test"
+	idToken, err := token.SignedString(m.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+}
+
+// newOIDCTest serves the users API from a scratch database with the mock as provider "mock".
+func newOIDCTest(t *testing.T) (*echo.Echo, *mockProvider) {
+	t.Helper()
+	e := newTestAPI(t)
+	mock := newMockProvider(t)
+	oidcProviders = map[string]*oidc.Provider{
+		"mock": oidc.NewProvider("mock", mock.srv.URL, mockClientID, "", "http://users.test/v1/users/auth/oidc/mock/callback", nil),
+	}
+	t.Cleanup(func() { oidcProviders = map[string]*oidc.Provider{} })
+	return e, mock
+}
+
+// start begins a sign-in at the mock and returns where it redirected and the state cookie.
+func start(t *testing.T, e *echo.Echo, cookies ...*http.Cookie) (string, *http.Cookie) {
+	t.Helper()
+	rec := serve(e, "/v1/users/auth/oidc/mock/start", cookies...)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("start: %d %s", rec.Code, rec.Body)
+	}
+	state := responseCookie(rec, auth.OIDCStateCookieName)
+	if state == nil || !state.HttpOnly || state.Path != "/v1/users/auth/oidc" {
+		t.Fatalf("start set state cookie %v", state)
+	}
+	return rec.Header().Get(echo.HeaderLocation), state
+}
+
+func TestOIDCSignIn(t *testing.T) {
+	e, mock := newOIDCTest(t)
+	authURL, state := start(t, e)
+	callback := mock.authorize(t, authURL, "subject-1", "alice@example.com")
+
+	rec := serve(e, callback, state)
+	if rec.Code != http.StatusFound || rec.Header().Get(echo.HeaderLocation) != "http://app.test/" {
+		t.Fatalf("callback: %d %s %s", rec.Code, rec.Header().Get(echo.HeaderLocation), rec.Body)
+	}
+	session := responseCookie(rec, auth.AuthCookieName)
+	if session == nil || session.Value == "" {
+		t.Fatal("callback didn't sign in")
+	}
+	if cleared := responseCookie(rec, auth.OIDCStateCookieName); cleared == nil || cleared.Value != "" {
+		t.Errorf("callback left the state cookie: %v", cleared)
+	}
+	userID, err := auth.SubjectFromToken(session.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	usr, err := storage.ReadUser(e.NewContext(nil, nil), userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usr.Contact != "alice@example.com" || !usr.ContactVerified || len(usr.Identities) != 1 {
+		t.Errorf("signed in as %+v", usr)
+	}
+
+	// the same identity signs in to the same account next time
+	authURL, state = start(t, e)
+	rec = serve(e, mock.authorize(t, authURL, "subject-1", "alice@example.com"), state)
+	again := responseCookie(rec, auth.AuthCookieName)
+	if again == nil {
+		t.Fatalf("second sign-in: %d %s", rec.Code, rec.Body)
+	}
+	if sub, _ := auth.SubjectFromToken(again.Value); sub != userID {
+		t.Errorf("second sign-in was as %s, want %s", sub, userID)
+	}
+}
+
+func TestOIDCCallbackNeedsTheStartingBrowser(t *testing.T) {
+	e, mock := newOIDCTest(t)
+	// the attacker starts a sign-in with their own account at the provider
+	authURL, attackerState := start(t, e)
+	callback := mock.authorize(t, authURL, "attacker", "mallory@example.com")
+	state, _ := url.Parse(callback)
+
+	// and gets a victim's browser to finish it, without the state cookie or with another flow's
+	_, victimState := start(t, e)
+	for name, cookies := range map[string][]*http.Cookie{
+		"without a state cookie":          nil,
+		"with another flow's cookie":      {victimState},
+		"with a forged cookie":            {{Name: auth.OIDCStateCookieName, Value: state.Query().Get("state") + ".forged"}},
+		"with an unsigned state cookie":   {{Name: auth.OIDCStateCookieName, Value: state.Query().Get("state")}},
+		"with the state but no signature": {{Name: auth.OIDCStateCookieName, Value: state.Query().Get("state") + "."}},
+	} {
+		rec := serve(e, callback, cookies...)
+		if rec.Code != http.StatusBadRequest || responseCookie(rec, auth.AuthCookieName) != nil {
+			t.Errorf("callback %s: %d %s", name, rec.Code, rec.Body)
+		}
+	}
+
+	// the rejected attempts didn't use the state up
+	if rec := serve(e, callback, attackerState); rec.Code != http.StatusFound || responseCookie(rec, auth.AuthCookieName) == nil {
+		t.Errorf("callback from the starting browser: %d %s", rec.Code, rec.Body)
+	}
+	// and it only works once
+	if rec := serve(e, callback, attackerState); rec.Code != http.StatusBadRequest {
+		t.Errorf("replayed callback: %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestOIDCLinkStaysWithItsAccount(t *testing.T) {
+	e, mock := newOIDCTest(t)
+	c := e.NewContext(nil, nil)
+	usr := createUser(t, e, "alice", "")
+	session := sessionCookie(t, usr)
+
+	// alice starts linking, but the callback arrives without her session
+	authURL, state := start(t, e, session)
+	rec := serve(e, mock.authorize(t, authURL, "attacker", "mallory@example.com"), state)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("link callback without the session: %d %s", rec.Code, rec.Body)
+	}
+
+	authURL, state = start(t, e, session)
+	rec = serve(e, mock.authorize(t, authURL, "alice-at-mock", "alice@example.com"), state, session)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("link callback: %d %s", rec.Code, rec.Body)
+	}
+	usr, err := storage.ReadUser(c, usr.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usr.Identities) != 1 || usr.Identities[0].Subject != "alice-at-mock" {
+		t.Errorf("alice's identities are %+v", usr.Identities)
+	}
+}
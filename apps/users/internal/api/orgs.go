@@ -0,0 +1,75 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/users/internal/auth"
+	"github.com/Taiterbase/vtrips/apps/users/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+	"github.com/oklog/ulid/v2"
+)
+
+// CreateOrgHandler starts a new org with the signed-in user as its first admin. Users
+// who already belong to an org can't start another.
+func CreateOrgHandler(c echo.Context) error {
+	usr, err := currentUser(c)
+	switch err {
+	case nil:
+		break
+	case models.ErrUserNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if usr.OrgID != "" {
+		return problem.Message(c, http.StatusConflict, "already a member of an org")
+	}
+	usr.OrgID = ulid.Make().String()
+	usr.Role = models.RoleAdmin
+	usr.SetUpdatedAt(time.Now().Unix())
+	if err = storage.UpdateUser(c, usr); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	// the session token carries the org as its tenant
+	_ = auth.SetCookieWithJWT(c.Response().Writer, usr.ID, usr.Username, usr.OrgID)
+	return c.JSON(http.StatusCreated, usr.Profile())
+}
+
+// SetMemberRoleHandler lets an org admin add a user without an org to theirs, or change
+// a member's role (form field "role", admin or member). Admins can't change their own
+// role, so an org always keeps one.
+func SetMemberRoleHandler(c echo.Context) error {
+	orgID := c.Param("org_id")
+	admin, err := orgAdmin(c, orgID)
+	if err != nil {
+		return problem.Message(c, http.StatusForbidden, "forbidden")
+	}
+	role := c.FormValue("role")
+	if role != models.RoleAdmin && role != models.RoleMember {
+		msg := fmt.Sprintf("must be %s or %s", models.RoleAdmin, models.RoleMember)
+		return problem.JSON(c, http.StatusBadRequest, problem.Invalid(fmt.Errorf("role %s", msg),
+			problem.FieldError{Field: "role", Rule: "oneof", Message: msg}))
+	}
+	member, err := storage.ReadUser(c, c.Param("user_id"))
+	if err == models.ErrUserNotFound || (err == nil && member.OrgID != "" && member.OrgID != orgID) {
+		return problem.JSON(c, http.StatusNotFound, models.ErrUserNotFound)
+	}
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if member.ID == admin.ID {
+		return problem.Message(c, http.StatusConflict, "admins can't change their own role")
+	}
+
+	member.OrgID = orgID
+	member.Role = role
+	member.SetUpdatedAt(time.Now().Unix())
+	if err = storage.UpdateUser(c, member); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"id": member.ID, "org_id": member.OrgID, "role": member.Role})
+}
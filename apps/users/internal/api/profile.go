@@ -0,0 +1,146 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/users/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+)
+
+// maxAvatarBytes caps avatar uploads; they're stored inline next to the user record.
+const maxAvatarBytes = 2 << 20
+
+var avatarTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// UploadAvatarHandler stores the multipart "avatar" file for the signed-in user. The
+// content type is sniffed from the bytes rather than trusted from the upload.
+func UploadAvatarHandler(c echo.Context) error {
+	usr, err := currentUser(c)
+	switch err {
+	case nil:
+		break
+	case models.ErrUserNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	file, err := c.FormFile("avatar")
+	if err != nil {
+		return problem.Message(c, http.StatusBadRequest, "missing avatar file")
+	}
+	if file.Size > maxAvatarBytes {
+		return problem.Message(c, http.StatusRequestEntityTooLarge, "avatar must be 2MB or smaller")
+	}
+	src, err := file.Open()
+	if err != nil {
+		return problem.JSON(c, http.StatusBadRequest, err)
+	}
+	defer src.Close()
+	image, err := io.ReadAll(io.LimitReader(src, maxAvatarBytes+1))
+	if err != nil {
+		return problem.JSON(c, http.StatusBadRequest, err)
+	}
+	if len(image) > maxAvatarBytes {
+		return problem.Message(c, http.StatusRequestEntityTooLarge, "avatar must be 2MB or smaller")
+	}
+	contentType := http.DetectContentType(image)
+	if !slices.Contains(avatarTypes, contentType) {
+		return problem.Message(c, http.StatusUnsupportedMediaType, "avatar must be a JPEG, PNG, GIF or WebP image")
+	}
+
+	if err = storage.WriteAvatar(usr.ID, image); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	usr.AvatarType = contentType
+	usr.SetUpdatedAt(time.Now().Unix())
+	if err = storage.UpdateUser(c, usr); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, usr.Profile())
+}
+
+func DeleteAvatarHandler(c echo.Context) error {
+	usr, err := currentUser(c)
+	switch err {
+	case nil:
+		break
+	case models.ErrUserNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if err = storage.DeleteAvatar(usr.ID); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	usr.AvatarType = ""
+	usr.SetUpdatedAt(time.Now().Unix())
+	if err = storage.UpdateUser(c, usr); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, usr.Profile())
+}
+
+// GetAvatar serves a user's avatar image. Avatars are public, like the rest of PublicProfile.
+func GetAvatar(c echo.Context) error {
+	usr, err := storage.ReadUser(c, c.Param("user_id"))
+	switch err {
+	case nil:
+		break
+	case models.ErrUserNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if usr.AvatarType == "" {
+		return problem.Message(c, http.StatusNotFound, "no avatar")
+	}
+	image, err := storage.ReadAvatar(usr.ID)
+	switch err {
+	case nil:
+		break
+	case models.ErrUserNotFound:
+		return problem.Message(c, http.StatusNotFound, "no avatar")
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.Blob(http.StatusOK, usr.AvatarType, image)
+}
+
+// VerifyCertificationHandler lets an org admin mark one of their member's certifications
+// (form field "name") as checked.
+func VerifyCertificationHandler(c echo.Context) error {
+	orgID := c.Param("org_id")
+	admin, err := orgAdmin(c, orgID)
+	if err != nil {
+		return problem.Message(c, http.StatusForbidden, "forbidden")
+	}
+	member, err := storage.ReadUser(c, c.Param("user_id"))
+	if err == models.ErrUserNotFound || (err == nil && member.OrgID != orgID) {
+		return problem.JSON(c, http.StatusNotFound, models.ErrUserNotFound)
+	}
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	name := c.FormValue("name")
+	i := slices.IndexFunc(member.Certifications, func(cert models.Certification) bool { return cert.Name == name })
+	if i < 0 {
+		return problem.JSON(c, http.StatusNotFound, models.ErrCertificationNotFound)
+	}
+	now := time.Now().Unix()
+	member.Certifications[i].Verified = true
+	member.Certifications[i].VerifiedBy = admin.ID
+	member.Certifications[i].VerifiedAt = now
+	member.SetUpdatedAt(now)
+	if err = storage.UpdateUser(c, member); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, member.Certifications[i])
+}
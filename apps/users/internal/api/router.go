@@ -3,6 +3,11 @@ package api
 import (
 	"fmt"
 
+	"github.com/Taiterbase/vtrips/apps/users/internal/lockout"
+	"github.com/Taiterbase/vtrips/apps/users/internal/notify"
+	"github.com/Taiterbase/vtrips/apps/users/internal/oidc"
+	"github.com/Taiterbase/vtrips/apps/users/internal/storage"
+	"github.com/Taiterbase/vtrips/pkg/problem"
 	"github.com/go-playground/validator"
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
@@ -23,12 +28,25 @@ func StartAPI() {
 	e.Logger.SetLevel(log.DEBUG)
 	e.Debug = true
 	e.HideBanner = true
+	e.HTTPErrorHandler = problem.HTTPErrorHandler
+	e.Use(requestID)
 	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
-		Format: "${time_rfc3339} ${status} ${method} ${uri} ${latency_human}\n",
+		Format: "${time_rfc3339} ${id} ${status} ${method} ${uri} ${latency_human}\n",
 	}))
 	e.Use(middleware.Recover())
 
 	e.Validator = &Validator{validator: validator.New()}
+	notifier = notify.FromEnv()
+	loginGuard = lockout.New(lockout.ConfigFromEnv(), lockout.SystemClock)
+	proxies, err := trustedProxiesFromEnv()
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+	trustedProxies = proxies
+	oidcProviders = oidc.ProvidersFromEnv()
+	if err = storage.Migrate(e.NewContext(nil, nil)); err != nil {
+		e.Logger.Fatal(err)
+	}
 	setupRouters(e)
 	e.Logger.Fatal(e.Start(fmt.Sprintf("%s:%s", "0.0.0.0", "8080")))
 }
@@ -37,13 +55,42 @@ func setupRouters(eng *echo.Echo) {
 	eng.GET("/v1/users", GetUsers)
 
 	// item operations
-	eng.POST("/v1/users", CreateUser)
 	eng.GET("/v1/users/:user_id", GetUser)
-	eng.PUT("/v1/users/:user_id", UpdateUser)
-	eng.DELETE("/v1/users/:user_id", DeleteUser)
+	eng.GET("/v1/users/:user_id/avatar", GetAvatar)
+
+	// the signed-in user's own account
+	meGroup := eng.Group("/v1/users/me", requireSession)
+	meGroup.GET("", GetMe)
+	meGroup.PATCH("", UpdateMe)
+	meGroup.DELETE("", DeleteMe)
+	meGroup.POST("/password", ChangePasswordHandler)
+	meGroup.PUT("/avatar", UploadAvatarHandler)
+	meGroup.DELETE("/avatar", DeleteAvatarHandler)
 
 	authGroup := eng.Group("/v1/users/auth")
 	authGroup.POST("/login", LoginHandler)
 	authGroup.POST("/signup", SignUpHandler)
 	authGroup.POST("/logout", LogoutHandler)
+	authGroup.GET("/session", SessionHandler, requireSession)
+	authGroup.POST("/verify", VerifyContactHandler)
+	authGroup.POST("/password-reset/request", PasswordResetRequestHandler)
+	authGroup.POST("/password-reset/confirm", PasswordResetConfirmHandler)
+	authGroup.POST("/login/totp", TOTPLoginHandler)
+	authGroup.POST("/unlock", UnlockAccountHandler)
+	authGroup.GET("/oidc", OIDCProvidersHandler)
+	authGroup.GET("/oidc/:provider/start", OIDCStartHandler)
+	authGroup.GET("/oidc/:provider/callback", OIDCCallbackHandler)
+	authGroup.DELETE("/oidc/:provider", UnlinkIdentityHandler, requireSession)
+	authGroup.POST("/totp/enroll", TOTPEnrollHandler)
+	authGroup.POST("/totp/activate", TOTPActivateHandler)
+	authGroup.POST("/totp/disable", TOTPDisableHandler, requireSession)
+
+	eng.POST("/v1/users/orgs", CreateOrgHandler, requireSession)
+	orgGroup := eng.Group("/v1/users/orgs/:org_id", requireSession)
+	orgGroup.GET("/policy", GetOrgPolicy)
+	orgGroup.PUT("/policy", UpdateOrgPolicy)
+	orgGroup.PUT("/members/:user_id/role", SetMemberRoleHandler)
+	orgGroup.POST("/members/:user_id/certifications/verify", VerifyCertificationHandler)
+
+	eng.GET("/openapi.json", GetOpenAPI)
 }
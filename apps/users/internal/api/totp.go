@@ -0,0 +1,277 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/users/internal/auth"
+	"github.com/Taiterbase/vtrips/apps/users/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+	"github.com/Taiterbase/vtrips/pkg/problem"
+	"github.com/labstack/echo"
+)
+
+// totpRequired reports whether the user's org forces them to use TOTP.
+func totpRequired(usr *models.User) (bool, error) {
+	if !usr.IsOrgAdmin() {
+		return false, nil
+	}
+	policy, err := storage.ReadOrgPolicy(usr.OrgID)
+	if err != nil {
+		return false, err
+	}
+	return policy.RequireAdminTOTP, nil
+}
+
+// enrollingUser resolves the user managing their TOTP settings, either from an existing
+// session or from the enrollment token handed out by LoginHandler when 2FA is mandatory.
+// enrollToken is returned non-empty only in the latter case.
+func enrollingUser(c echo.Context) (usr *models.User, enrollToken string, err error) {
+	userID := sessionUserID(c)
+	if userID == "" {
+		enrollToken = c.FormValue("mfa_token")
+		if enrollToken == "" {
+			return nil, "", auth.ErrInvalidActionToken
+		}
+		if userID, _, err = auth.ParseActionToken(enrollToken, auth.PurposeEnrollTOTP); err != nil {
+			return nil, "", err
+		}
+	}
+	usr, err = storage.ReadUser(c, userID)
+	return usr, enrollToken, err
+}
+
+// TOTPEnrollHandler creates a pending secret and returns the provisioning URI for the authenticator app.
+// The secret only takes effect once TOTPActivateHandler sees a valid code for it.
+func TOTPEnrollHandler(c echo.Context) error {
+	usr, _, err := enrollingUser(c)
+	switch err {
+	case nil:
+		break
+	case auth.ErrInvalidActionToken:
+		return problem.Message(c, http.StatusUnauthorized, "unauthorized")
+	case models.ErrUserNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if usr.TOTPEnabled {
+		return problem.Message(c, http.StatusConflict, "two-factor authentication is already enabled")
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	usr.TOTPPendingSecret = secret
+	usr.SetUpdatedAt(time.Now().Unix())
+	if err = storage.UpdateUser(c, usr); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"secret":           secret,
+		"provisioning_uri": auth.TOTPProvisioningURI(usr.Username, secret),
+	})
+}
+
+// TOTPActivateHandler confirms the pending secret with a code and returns one-time recovery codes.
+func TOTPActivateHandler(c echo.Context) error {
+	code := c.FormValue("code")
+	if code == "" {
+		return problem.Message(c, http.StatusBadRequest, "missing code")
+	}
+	usr, enrollToken, err := enrollingUser(c)
+	switch err {
+	case nil:
+		break
+	case auth.ErrInvalidActionToken:
+		return problem.Message(c, http.StatusUnauthorized, "unauthorized")
+	case models.ErrUserNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if usr.TOTPPendingSecret == "" {
+		return problem.Message(c, http.StatusBadRequest, "no pending enrollment")
+	}
+	step, ok := auth.ValidateTOTP(usr.TOTPPendingSecret, code, time.Now(), 0)
+	if !ok {
+		return problem.Message(c, http.StatusUnauthorized, "invalid code")
+	}
+
+	codes, hashes, err := auth.GenerateRecoveryCodes()
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if enrollToken != "" {
+		if _, err = auth.ConsumeActionToken(enrollToken, auth.PurposeEnrollTOTP); err != nil {
+			return problem.Message(c, http.StatusUnauthorized, "unauthorized")
+		}
+	}
+	usr.TOTPSecret = usr.TOTPPendingSecret
+	usr.TOTPPendingSecret = ""
+	usr.TOTPEnabled = true
+	usr.TOTPLastStep = step
+	usr.RecoveryCodeHashes = hashes
+	usr.SetUpdatedAt(time.Now().Unix())
+	if err = storage.UpdateUser(c, usr); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	if enrollToken != "" {
+		_ = auth.SetCookieWithJWT(c.Response().Writer, usr.ID, usr.Username, usr.OrgID)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"id": usr.ID, "recovery_codes": codes})
+}
+
+// TOTPLoginHandler completes a two-step login with either a TOTP code or an unused recovery code.
+func TOTPLoginHandler(c echo.Context) error {
+	mfaToken := c.FormValue("mfa_token")
+	code := c.FormValue("code")
+	recoveryCode := c.FormValue("recovery_code")
+	if mfaToken == "" || (code == "" && recoveryCode == "") {
+		return problem.Message(c, http.StatusBadRequest, "missing credentials")
+	}
+	userID, _, err := auth.ParseActionToken(mfaToken, auth.PurposeLoginTOTP)
+	if err != nil {
+		return problem.Message(c, http.StatusUnauthorized, "invalid credentials")
+	}
+	usr, err := storage.ReadUser(c, userID)
+	switch err {
+	case nil:
+		break
+	case models.ErrUserNotFound:
+		return problem.Message(c, http.StatusUnauthorized, "invalid credentials")
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if !usr.TOTPEnabled {
+		return problem.Message(c, http.StatusUnauthorized, "invalid credentials")
+	}
+	if throttled, err := secondFactorThrottled(c, usr); throttled {
+		return err
+	}
+
+	if code != "" {
+		step, ok := auth.ValidateTOTP(usr.TOTPSecret, code, time.Now(), usr.TOTPLastStep)
+		if !ok {
+			secondFactorFailed(c, usr)
+			return problem.Message(c, http.StatusUnauthorized, "invalid credentials")
+		}
+		usr.TOTPLastStep = step
+	} else {
+		i := auth.MatchRecoveryCode(usr.RecoveryCodeHashes, recoveryCode)
+		if i < 0 {
+			secondFactorFailed(c, usr)
+			return problem.Message(c, http.StatusUnauthorized, "invalid credentials")
+		}
+		usr.RecoveryCodeHashes = slices.Delete(usr.RecoveryCodeHashes, i, i+1)
+	}
+
+	// redeem the intermediate token only once the second factor checks out
+	if _, err = auth.ConsumeActionToken(mfaToken, auth.PurposeLoginTOTP); err != nil {
+		return problem.Message(c, http.StatusUnauthorized, "invalid credentials")
+	}
+	usr.SetUpdatedAt(time.Now().Unix())
+	if err = storage.UpdateUser(c, usr); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+
+	secondFactorSucceeded(c, usr)
+	_ = auth.SetCookieWithJWT(c.Response().Writer, usr.ID, usr.Username, usr.OrgID)
+	return c.JSON(http.StatusOK, echo.Map{
+		"id":                       usr.ID,
+		"username":                 usr.Username,
+		"recovery_codes_remaining": len(usr.RecoveryCodeHashes),
+	})
+}
+
+// TOTPDisableHandler turns 2FA off for the signed-in user, unless their org requires it.
+func TOTPDisableHandler(c echo.Context) error {
+	code := c.FormValue("code")
+	if code == "" {
+		return problem.Message(c, http.StatusBadRequest, "missing code")
+	}
+	usr, err := storage.ReadUser(c, sessionUserID(c))
+	switch err {
+	case nil:
+		break
+	case models.ErrUserNotFound:
+		return problem.JSON(c, http.StatusNotFound, err)
+	default:
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if !usr.TOTPEnabled {
+		return problem.Message(c, http.StatusBadRequest, "two-factor authentication is not enabled")
+	}
+	required, err := totpRequired(usr)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if required {
+		return problem.Message(c, http.StatusForbidden, "your organization requires two-factor authentication")
+	}
+	if throttled, err := secondFactorThrottled(c, usr); throttled {
+		return err
+	}
+	if _, ok := auth.ValidateTOTP(usr.TOTPSecret, code, time.Now(), usr.TOTPLastStep); !ok {
+		secondFactorFailed(c, usr)
+		return problem.Message(c, http.StatusUnauthorized, "invalid code")
+	}
+	secondFactorSucceeded(c, usr)
+
+	usr.TOTPEnabled = false
+	usr.TOTPSecret = ""
+	usr.TOTPLastStep = 0
+	usr.RecoveryCodeHashes = nil
+	usr.SetUpdatedAt(time.Now().Unix())
+	if err = storage.UpdateUser(c, usr); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{"id": usr.ID, "totp_enabled": false})
+}
+
+// orgAdmin loads the signed-in user and checks they administer orgID.
+func orgAdmin(c echo.Context, orgID string) (*models.User, error) {
+	usr, err := storage.ReadUser(c, sessionUserID(c))
+	if err != nil {
+		return nil, err
+	}
+	if !usr.IsOrgAdmin() || usr.OrgID != orgID {
+		return nil, models.ErrOrgNotFound
+	}
+	return usr, nil
+}
+
+func GetOrgPolicy(c echo.Context) error {
+	orgID := c.Param("org_id")
+	if _, err := orgAdmin(c, orgID); err != nil {
+		return problem.Message(c, http.StatusForbidden, "forbidden")
+	}
+	policy, err := storage.ReadOrgPolicy(orgID)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+func UpdateOrgPolicy(c echo.Context) error {
+	orgID := c.Param("org_id")
+	if _, err := orgAdmin(c, orgID); err != nil {
+		return problem.Message(c, http.StatusForbidden, "forbidden")
+	}
+	policy, err := storage.ReadOrgPolicy(orgID)
+	if err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	if err = c.Bind(policy); err != nil {
+		return badBind(c, err)
+	}
+	policy.OrgID = orgID
+	policy.UpdatedAt = time.Now().Unix()
+	if err = storage.WriteOrgPolicy(policy); err != nil {
+		return problem.JSON(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, policy)
+}
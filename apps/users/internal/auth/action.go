@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/users/internal/storage"
+	"github.com/golang-jwt/jwt"
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	// PurposeVerifyContact tokens confirm the user owns their contact address.
+	PurposeVerifyContact = "verify_contact"
+	// PurposePasswordReset tokens allow setting a new password without the old one.
+	PurposePasswordReset = "password_reset"
+	// PurposeLoginTOTP tokens prove the password step of a two-step login succeeded.
+	PurposeLoginTOTP = "login_totp"
+	// PurposeEnrollTOTP tokens let a user who must use 2FA enroll before getting a session.
+	PurposeEnrollTOTP = "enroll_totp"
+	// PurposeUnlockAccount tokens lift a brute-force lockout early.
+	PurposeUnlockAccount = "unlock_account"
+
+	VerifyContactTTL = 48 * time.Hour
+	PasswordResetTTL = 30 * time.Minute
+	LoginTOTPTTL     = 5 * time.Minute
+	EnrollTOTPTTL    = 15 * time.Minute
+	UnlockAccountTTL = time.Hour
+)
+
+// actionAudience is the aud claim of every action token.
+const actionAudience = "action"
+
+var ErrInvalidActionToken = errors.New("invalid or expired token")
+
+// actionKey signs action tokens. It is derived from JWT_SECRET but differs from the
+// session key, so no action token passes for a session anywhere JWT_SECRET is checked.
+func actionKey() []byte {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("JWT_SECRET")))
+	mac.Write([]byte("vtrips action token"))
+	return mac.Sum(nil)
+}
+
+// GenerateActionToken issues a signed, single-use token for purpose that expires after ttl.
+func GenerateActionToken(userID, purpose string, ttl time.Duration) (string, error) {
+	return generateActionToken(userID, purpose, ttl, jwt.MapClaims{})
+}
+
+// GenerateContactToken issues a PurposeVerifyContact token for contact. It only verifies
+// the user's contact while that is still contact, so an old link can't verify an
+// address set later.
+func GenerateContactToken(userID, contact string) (string, error) {
+	return generateActionToken(userID, PurposeVerifyContact, VerifyContactTTL, jwt.MapClaims{"contact": contact})
+}
+
+func generateActionToken(userID, purpose string, ttl time.Duration, claims jwt.MapClaims) (string, error) {
+	now := time.Now()
+	jti := ulid.Make().String()
+	claims["sub"] = userID
+	claims["aud"] = actionAudience
+	claims["purpose"] = purpose
+	claims["jti"] = jti
+	claims["iat"] = now.Unix()
+	claims["iat_ms"] = now.UnixMilli()
+	claims["exp"] = now.Add(ttl).Unix()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(actionKey())
+	if err != nil {
+		return "", err
+	}
+	if err = storage.PutActionToken(jti, userID, now.Add(ttl).Unix()); err != nil {
+		return "", err
+	}
+	return tokenString, nil
+}
+
+// ParseActionToken validates the signature, expiry and purpose of tokenString without redeeming it.
+// It returns the user the token was issued to and the token's ID.
+func ParseActionToken(tokenString, purpose string) (userID, tokenID string, err error) {
+	claims, err := parseActionToken(tokenString, purpose)
+	if err != nil {
+		return "", "", err
+	}
+	return claims["sub"].(string), claims["jti"].(string), nil
+}
+
+func parseActionToken(tokenString, purpose string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return actionKey(), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidActionToken
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != purpose || !claims.VerifyAudience(actionAudience, true) {
+		return nil, ErrInvalidActionToken
+	}
+	userID, _ := claims["sub"].(string)
+	tokenID, _ := claims["jti"].(string)
+	if userID == "" || tokenID == "" {
+		return nil, ErrInvalidActionToken
+	}
+	// signing out everywhere or changing the password also voids outstanding links
+	revokedBefore, err := storage.GetRevokedBefore(userID)
+	if err != nil {
+		return nil, err
+	}
+	if issuedAt(claims).Before(revokedBefore) {
+		return nil, ErrInvalidActionToken
+	}
+	return claims, nil
+}
+
+// ConsumeActionToken validates tokenString like ParseActionToken and redeems it so it
+// cannot be used again. It returns the user the token was issued to.
+func ConsumeActionToken(tokenString, purpose string) (string, error) {
+	claims, err := consumeActionToken(tokenString, purpose)
+	if err != nil {
+		return "", err
+	}
+	return claims["sub"].(string), nil
+}
+
+// ConsumeContactToken redeems a GenerateContactToken token like ConsumeActionToken. It
+// returns the user and the contact the token verifies.
+func ConsumeContactToken(tokenString string) (userID, contact string, err error) {
+	claims, err := consumeActionToken(tokenString, PurposeVerifyContact)
+	if err != nil {
+		return "", "", err
+	}
+	contact, _ = claims["contact"].(string)
+	if contact == "" {
+		return "", "", ErrInvalidActionToken
+	}
+	return claims["sub"].(string), contact, nil
+}
+
+func consumeActionToken(tokenString, purpose string) (jwt.MapClaims, error) {
+	claims, err := parseActionToken(tokenString, purpose)
+	if err != nil {
+		return nil, err
+	}
+	userID, ok, err := storage.ConsumeActionToken(claims["jti"].(string))
+	if err != nil {
+		return nil, err
+	}
+	if !ok || userID != claims["sub"] {
+		return nil, ErrInvalidActionToken
+	}
+	return claims, nil
+}
@@ -12,15 +12,20 @@ import (
 const (
 	// AuthCookieName is the name of the cookie that holds the JWT
 	AuthCookieName = "auth_token"
+	// SessionTTL is how long a session JWT, and the cookie holding it, lasts
+	SessionTTL = 14 * 24 * time.Hour
 )
 
 func GenerateJWT(userID, username, tenant string) (string, error) {
 	secret := os.Getenv("JWT_SECRET")
+	now := time.Now()
 	claims := jwt.MapClaims{
 		"sub":      userID,
 		"username": username,
 		"name":     username,
-        "iat":      time.Now().Unix(),
+        "iat":      now.Unix(),
+        "iat_ms":   now.UnixMilli(),
+        "exp":      now.Add(SessionTTL).Unix(),
 	}
 	if tenant != "" {
 		claims["tenant"] = tenant
@@ -59,7 +64,7 @@ func SetCookieWithJWT(w http.ResponseWriter, userID, username, tenant string) er
 	cookie := &http.Cookie{
 		Name:     AuthCookieName,
 		Value:    tokenString,
-		Expires:  time.Now().Add(time.Hour * 24 * 14),
+		Expires:  time.Now().Add(SessionTTL),
 		HttpOnly: true,
 		SameSite: http.SameSiteLaxMode,
 		Path:     "/",
@@ -93,14 +98,49 @@ func Validate(tokenString string) (jwt.Claims, error) {
 		return nil, jwt.ErrInvalidKey
 	}
     claims := token.Claims.(jwt.MapClaims)
+    // action tokens (see GenerateActionToken) carry a purpose and are never sessions
+    if _, ok := claims["purpose"]; ok {
+        return nil, jwt.ErrInvalidKey
+    }
+    // jwt only checks exp when it is set; sessions without one would never lapse
+    if _, ok := claims["exp"].(float64); !ok {
+        return nil, jwt.ErrInvalidKey
+    }
     // Check revocation by user ID and issued-at
     if sub, ok := claims["sub"].(string); ok {
-        if iatf, ok := claims["iat"].(float64); ok {
+        if _, ok := claims["iat"].(float64); ok {
             revokedBefore, _ := storage.GetRevokedBefore(sub)
-            if revokedBefore > 0 && int64(iatf) <= revokedBefore {
+            if issuedAt(claims).Before(revokedBefore) {
                 return nil, jwt.ErrSignatureInvalid
             }
         }
     }
 	return claims, nil
 }
+
+// issuedAt is when a token was issued: iat_ms, or for tokens from before it was set, the
+// start of the iat second.
+func issuedAt(claims jwt.MapClaims) time.Time {
+    if ms, ok := claims["iat_ms"].(float64); ok {
+        return time.UnixMilli(int64(ms))
+    }
+    iat, _ := claims["iat"].(float64)
+    return time.Unix(int64(iat), 0)
+}
+
+// SubjectFromToken validates tokenString and returns the user ID it was issued to.
+func SubjectFromToken(tokenString string) (string, error) {
+	claims, err := Validate(tokenString)
+	if err != nil {
+		return "", err
+	}
+	m, ok := claims.(jwt.MapClaims)
+	if !ok {
+		return "", jwt.ErrInvalidKey
+	}
+	sub, _ := m["sub"].(string)
+	if sub == "" {
+		return "", jwt.ErrInvalidKey
+	}
+	return sub, nil
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// OIDCStateCookieName holds the state of the sign-in the browser started with an
+	// identity provider, so the callback only completes flows started in that browser.
+	OIDCStateCookieName = "oidc_state"
+
+	oidcStatePath = "/v1/users/auth/oidc"
+)
+
+// stateKey signs OIDC state cookies; like actionKey it is derived from JWT_SECRET.
+func stateKey() []byte {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("JWT_SECRET")))
+	mac.Write([]byte("vtrips oidc state"))
+	return mac.Sum(nil)
+}
+
+func signState(state string) string {
+	mac := hmac.New(sha256.New, stateKey())
+	mac.Write([]byte(state))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SetOIDCStateCookie binds state to the browser until ttl passes.
+func SetOIDCStateCookie(w http.ResponseWriter, state string, ttl time.Duration) {
+	setStateCookie(w, state+"."+signState(state), time.Now().Add(ttl))
+}
+
+// ClearOIDCStateCookie removes the state cookie once its flow is over.
+func ClearOIDCStateCookie(w http.ResponseWriter) {
+	setStateCookie(w, "", time.Now().Add(-1*time.Hour))
+}
+
+// OIDCStateMatches reports whether r carries a state cookie this service signed for state.
+func OIDCStateMatches(r *http.Request, state string) bool {
+	cookie, err := r.Cookie(OIDCStateCookieName)
+	if err != nil || state == "" {
+		return false
+	}
+	got, sig, ok := strings.Cut(cookie.Value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(signState(got))) {
+		return false
+	}
+	return hmac.Equal([]byte(got), []byte(state))
+}
+
+func setStateCookie(w http.ResponseWriter, value string, expires time.Time) {
+	cookie := &http.Cookie{
+		Name:     OIDCStateCookieName,
+		Value:    value,
+		Expires:  expires,
+		HttpOnly: true,
+		// Lax still sends it on the provider's top-level redirect back to the callback
+		SameSite: http.SameSiteLaxMode,
+		Path:     oidcStatePath,
+	}
+	if os.Getenv("ENVIRONMENT") == "development" {
+		cookie.Secure = false
+	} else {
+		cookie.Secure = true
+	}
+	http.SetCookie(w, cookie)
+}
@@ -0,0 +1,231 @@
+// Package lockout throttles password and second-factor guessing with per-username,
+// per-user and per-IP failure counters, exponential backoff and temporary account lockout.
+package lockout
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/users/internal/storage"
+	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+)
+
+const (
+	scopeUser = "user"
+	scopeTOTP = "totp"
+	scopeIP   = "ip"
+)
+
+// Clock lets tests control time.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock reads the wall clock.
+var SystemClock Clock = systemClock{}
+
+type Config struct {
+	// FreeAttempts is how many failures a username may have before backoff starts.
+	FreeAttempts int
+	// IPFreeAttempts is the same allowance for a single client IP across all usernames.
+	IPFreeAttempts int
+	// BaseDelay is the wait after the first failure past the allowance; it doubles with each further failure.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay.
+	MaxDelay time.Duration
+	// LockoutThreshold is the number of username failures that locks the account.
+	LockoutThreshold int
+	// LockoutDuration is how long a locked account stays locked unless unlocked by email.
+	LockoutDuration time.Duration
+	// Window is how long after the last failure a counter is forgotten.
+	Window time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		FreeAttempts:     3,
+		IPFreeAttempts:   20,
+		BaseDelay:        time.Second,
+		MaxDelay:         15 * time.Minute,
+		LockoutThreshold: 10,
+		LockoutDuration:  30 * time.Minute,
+		Window:           24 * time.Hour,
+	}
+}
+
+// ConfigFromEnv overrides DefaultConfig with any LOGIN_* environment variables that are set.
+func ConfigFromEnv() Config {
+	cfg := DefaultConfig()
+	envInt("LOGIN_FREE_ATTEMPTS", &cfg.FreeAttempts)
+	envInt("LOGIN_IP_FREE_ATTEMPTS", &cfg.IPFreeAttempts)
+	envDuration("LOGIN_BACKOFF_BASE", &cfg.BaseDelay)
+	envDuration("LOGIN_BACKOFF_MAX", &cfg.MaxDelay)
+	envInt("LOGIN_LOCKOUT_THRESHOLD", &cfg.LockoutThreshold)
+	envDuration("LOGIN_LOCKOUT_DURATION", &cfg.LockoutDuration)
+	envDuration("LOGIN_ATTEMPT_WINDOW", &cfg.Window)
+	return cfg
+}
+
+func envInt(name string, dst *int) {
+	if v, err := strconv.Atoi(os.Getenv(name)); err == nil {
+		*dst = v
+	}
+}
+
+func envDuration(name string, dst *time.Duration) {
+	if v, err := time.ParseDuration(os.Getenv(name)); err == nil {
+		*dst = v
+	}
+}
+
+// Guard decides whether a login attempt may proceed and records its outcome.
+type Guard struct {
+	mu    sync.Mutex
+	cfg   Config
+	clock Clock
+}
+
+func New(cfg Config, clock Clock) *Guard {
+	return &Guard{cfg: cfg, clock: clock}
+}
+
+// normalizeUsername keys the counter the way usernames are matched at sign-in, so every
+// spelling of one account, such as "Alice" and "Ａlice", shares it.
+func normalizeUsername(username string) string {
+	return models.NormalizeUnique(username)
+}
+
+// Check returns how long the caller must wait before trying username from ip again.
+// A zero duration means the attempt may proceed. locked reports whether the wait is an account lockout.
+func (g *Guard) Check(username, ip string) (wait time.Duration, locked bool, err error) {
+	return g.check(scopeUser, normalizeUsername(username), ip)
+}
+
+// CheckSecondFactor is Check for the TOTP or recovery code of the user with userID,
+// which has a counter of its own so getting the password right can't reset it.
+func (g *Guard) CheckSecondFactor(userID, ip string) (wait time.Duration, locked bool, err error) {
+	return g.check(scopeTOTP, userID, ip)
+}
+
+// Fail records a failed attempt. lockedNow is true when this failure locked the account,
+// so the caller can send the unlock email exactly once.
+func (g *Guard) Fail(username, ip string) (lockedNow bool, err error) {
+	return g.fail(scopeUser, normalizeUsername(username), ip)
+}
+
+// FailSecondFactor records a wrong TOTP or recovery code. A second-factor lockout only
+// expires; an unlock email proves the contact, not the second factor.
+func (g *Guard) FailSecondFactor(userID, ip string) (lockedNow bool, err error) {
+	return g.fail(scopeTOTP, userID, ip)
+}
+
+// Succeed clears the username counter. The IP counter is left to expire on its own so
+// one valid account can't be used to launder guesses against others.
+func (g *Guard) Succeed(username string) error {
+	return g.Unlock(username)
+}
+
+// SucceedSecondFactor clears the second-factor counter of the user with userID.
+func (g *Guard) SucceedSecondFactor(userID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return storage.DeleteLoginAttempts(scopeTOTP, userID)
+}
+
+// Unlock clears any lockout and failure history for username.
+func (g *Guard) Unlock(username string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return storage.DeleteLoginAttempts(scopeUser, normalizeUsername(username))
+}
+
+func (g *Guard) check(scope, subject, ip string) (wait time.Duration, locked bool, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.clock.Now()
+
+	attempts, err := storage.ReadLoginAttempts(scope, subject)
+	if err != nil {
+		return 0, false, err
+	}
+	if attempts.LockedUntil > now.Unix() {
+		return time.Unix(attempts.LockedUntil, 0).Sub(now), true, nil
+	}
+	wait = g.backoff(attempts, g.cfg.FreeAttempts, now)
+
+	if ip != "" {
+		addr, err := storage.ReadLoginAttempts(scopeIP, ip)
+		if err != nil {
+			return 0, false, err
+		}
+		wait = max(wait, g.backoff(addr, g.cfg.IPFreeAttempts, now))
+	}
+	return wait, false, nil
+}
+
+func (g *Guard) fail(scope, subject, ip string) (lockedNow bool, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.clock.Now()
+
+	attempts, err := storage.ReadLoginAttempts(scope, subject)
+	if err != nil {
+		return false, err
+	}
+	g.record(attempts, now)
+	if g.cfg.LockoutThreshold > 0 && attempts.Failures >= g.cfg.LockoutThreshold && attempts.LockedUntil <= now.Unix() {
+		attempts.LockedUntil = now.Add(g.cfg.LockoutDuration).Unix()
+		attempts.Failures = 0
+		lockedNow = true
+	}
+	if err = storage.WriteLoginAttempts(scope, subject, attempts); err != nil {
+		return false, err
+	}
+
+	if ip != "" {
+		addr, err := storage.ReadLoginAttempts(scopeIP, ip)
+		if err != nil {
+			return false, err
+		}
+		g.record(addr, now)
+		if err = storage.WriteLoginAttempts(scopeIP, ip, addr); err != nil {
+			return false, err
+		}
+	}
+	return lockedNow, nil
+}
+
+func (g *Guard) expired(a *models.LoginAttempts, now time.Time) bool {
+	return g.cfg.Window > 0 && now.Sub(time.Unix(a.LastFailure, 0)) > g.cfg.Window
+}
+
+func (g *Guard) record(a *models.LoginAttempts, now time.Time) {
+	if g.expired(a, now) {
+		a.Failures = 0
+	}
+	a.Failures++
+	a.LastFailure = now.Unix()
+}
+
+// backoff returns the remaining wait for a counter: nothing within the free allowance,
+// then BaseDelay doubling per extra failure up to MaxDelay, measured from the last failure.
+func (g *Guard) backoff(a *models.LoginAttempts, free int, now time.Time) time.Duration {
+	if a.Failures <= free || g.expired(a, now) {
+		return 0
+	}
+	delay := g.cfg.BaseDelay
+	for i := 1; i < a.Failures-free && delay < g.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, g.cfg.MaxDelay)
+	if wait := time.Unix(a.LastFailure, 0).Add(delay).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
@@ -0,0 +1,192 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/users/internal/storage"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (f *fakeClock) Now() time.Time { return f.now }
+
+func (f *fakeClock) advance(d time.Duration) { f.now = f.now.Add(d) }
+
+// newGuard returns a Guard on a scratch database whose clock only moves when told to.
+func newGuard(t *testing.T, cfg Config) (*Guard, *fakeClock) {
+	t.Helper()
+	if err := storage.Open(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.Client.Close() })
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	return New(cfg, clock), clock
+}
+
+func testConfig() Config {
+	return Config{
+		FreeAttempts:     3,
+		IPFreeAttempts:   5,
+		BaseDelay:        time.Second,
+		MaxDelay:         4 * time.Second,
+		LockoutThreshold: 8,
+		LockoutDuration:  30 * time.Minute,
+		Window:           time.Hour,
+	}
+}
+
+func fail(t *testing.T, g *Guard, username, ip string) bool {
+	t.Helper()
+	lockedNow, err := g.Fail(username, ip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lockedNow
+}
+
+func expectWait(t *testing.T, g *Guard, username, ip string, want time.Duration, wantLocked bool) {
+	t.Helper()
+	wait, locked, err := g.Check(username, ip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wait != want || locked != wantLocked {
+		t.Errorf("Check(%q, %q) = %s, locked %t; want %s, locked %t", username, ip, wait, locked, want, wantLocked)
+	}
+}
+
+func TestBackoffDoublesUpToMax(t *testing.T) {
+	g, clock := newGuard(t, testConfig())
+	for range 3 {
+		fail(t, g, "alice", "")
+	}
+	expectWait(t, g, "alice", "", 0, false)
+
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		fail(t, g, "alice", "")
+		expectWait(t, g, "alice", "", want, false)
+		clock.advance(want / 2)
+		expectWait(t, g, "alice", "", want/2, false)
+		clock.advance(want / 2)
+		expectWait(t, g, "alice", "", 0, false)
+	}
+}
+
+func TestUsernamesShareACounterAcrossCase(t *testing.T) {
+	g, _ := newGuard(t, testConfig())
+	for _, name := range []string{"alice", "Alice", " ALICE ", "alice", "Ａlice"} {
+		fail(t, g, name, "")
+	}
+	expectWait(t, g, "aLiCe", "", 2*time.Second, false)
+	expectWait(t, g, "bob", "", 0, false)
+}
+
+func TestLockoutLastsItsDuration(t *testing.T) {
+	g, clock := newGuard(t, testConfig())
+	for i := 1; i <= 8; i++ {
+		clock.advance(5 * time.Second)
+		if lockedNow := fail(t, g, "alice", ""); lockedNow != (i == 8) {
+			t.Fatalf("failure %d: lockedNow = %t", i, lockedNow)
+		}
+	}
+	expectWait(t, g, "alice", "", 30*time.Minute, true)
+
+	// failures while locked neither extend the lock nor send another unlock email
+	clock.advance(10 * time.Minute)
+	if fail(t, g, "alice", "") {
+		t.Error("a failure while locked locked the account again")
+	}
+	expectWait(t, g, "alice", "", 20*time.Minute, true)
+
+	clock.advance(20 * time.Minute)
+	expectWait(t, g, "alice", "", 0, false)
+}
+
+func TestUnlockClearsTheCounter(t *testing.T) {
+	g, _ := newGuard(t, testConfig())
+	for range 8 {
+		fail(t, g, "alice", "")
+	}
+	expectWait(t, g, "alice", "", 30*time.Minute, true)
+	if err := g.Unlock("Alice"); err != nil {
+		t.Fatal(err)
+	}
+	expectWait(t, g, "alice", "", 0, false)
+}
+
+func TestWindowForgetsOldFailures(t *testing.T) {
+	g, clock := newGuard(t, testConfig())
+	for range 5 {
+		fail(t, g, "alice", "")
+	}
+	clock.advance(time.Hour + time.Second)
+	expectWait(t, g, "alice", "", 0, false)
+
+	// the count starts over, so the next failure is within the allowance again
+	fail(t, g, "alice", "")
+	expectWait(t, g, "alice", "", 0, false)
+}
+
+func TestIPCounterSpansUsernames(t *testing.T) {
+	g, clock := newGuard(t, testConfig())
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		fail(t, g, name, "203.0.113.7")
+	}
+	expectWait(t, g, "someone-else", "203.0.113.7", time.Second, false)
+	expectWait(t, g, "someone-else", "198.51.100.1", 0, false)
+
+	// a good login elsewhere doesn't clear the address's counter
+	if err := g.Succeed("a"); err != nil {
+		t.Fatal(err)
+	}
+	expectWait(t, g, "a", "203.0.113.7", time.Second, false)
+	clock.advance(time.Second)
+	expectWait(t, g, "a", "203.0.113.7", 0, false)
+}
+
+func TestSecondFactorHasItsOwnCounter(t *testing.T) {
+	g, clock := newGuard(t, testConfig())
+	for range 4 {
+		if _, err := g.FailSecondFactor("user-1", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// getting the password right again must not reset second-factor guessing
+	if err := g.Succeed("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Unlock("alice"); err != nil {
+		t.Fatal(err)
+	}
+	wait, _, err := g.CheckSecondFactor("user-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wait != time.Second {
+		t.Errorf("CheckSecondFactor wait = %s, want 1s", wait)
+	}
+	expectWait(t, g, "alice", "", 0, false)
+
+	clock.advance(time.Second)
+	for i := 5; i <= 8; i++ {
+		clock.advance(5 * time.Second)
+		lockedNow, err := g.FailSecondFactor("user-1", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lockedNow != (i == 8) {
+			t.Fatalf("second-factor failure %d: lockedNow = %t", i, lockedNow)
+		}
+	}
+	if _, locked, _ := g.CheckSecondFactor("user-1", ""); !locked {
+		t.Error("second factor not locked after the threshold")
+	}
+
+	if err := g.SucceedSecondFactor("user-1"); err != nil {
+		t.Fatal(err)
+	}
+	if wait, locked, _ := g.CheckSecondFactor("user-1", ""); wait != 0 || locked {
+		t.Errorf("after SucceedSecondFactor: wait %s, locked %t", wait, locked)
+	}
+}
@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"context"
+	"os"
+)
+
+// Message is a single outbound notification addressed to a user's contact.
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Notifier delivers messages to users. Implementations must be safe for concurrent use.
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// FromEnv picks a Notifier based on the NOTIFIER environment variable.
+// Supported values are "smtp", "file" and "memory" (the default).
+func FromEnv() Notifier {
+	switch os.Getenv("NOTIFIER") {
+	case "smtp":
+		return NewSMTP(
+			os.Getenv("SMTP_ADDR"),
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+			os.Getenv("SMTP_FROM"),
+		)
+	case "file":
+		path := os.Getenv("NOTIFIER_FILE")
+		if path == "" {
+			path = "/tmp/notifications.jsonl"
+		}
+		return NewFile(path)
+	default:
+		return NewMemory()
+	}
+}
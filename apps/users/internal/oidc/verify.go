@@ -0,0 +1,165 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// keysMaxAge bounds how long a fetched JWKS is trusted before refetching.
+const keysMaxAge = time.Hour
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// key returns the public key for kid, refetching the JWKS once when the kid is unknown
+// so provider key rotation is picked up without a restart.
+func (p *Provider) key(ctx context.Context, kid string) (any, error) {
+	p.mu.Lock()
+	k, ok := p.keys[kid]
+	fresh := time.Since(p.keysTime) < keysMaxAge
+	p.mu.Unlock()
+	if ok && fresh {
+		return k, nil
+	}
+
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err = p.getJSON(ctx, meta.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if pub, err := k.publicKey(); err == nil {
+			keys[k.Kid] = pub
+		}
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.keysTime = time.Now()
+	p.mu.Unlock()
+
+	if k, ok = keys[kid]; !ok {
+		return nil, ErrInvalidIDToken
+	}
+	return k, nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, ErrInvalidIDToken
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, ErrInvalidIDToken
+	}
+}
+
+// verify checks the ID token signature against the provider's JWKS and validates
+// iss, aud, exp and nonce as required by OpenID Connect Core §3.1.3.7.
+func (p *Provider) verify(ctx context.Context, idToken, nonce string) (*Claims, error) {
+	token, err := jwt.Parse(idToken, func(t *jwt.Token) (interface{}, error) {
+		switch t.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			return nil, jwt.ErrSignatureInvalid
+		}
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidIDToken
+	}
+	m, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidIDToken
+	}
+	if iss, _ := m["iss"].(string); strings.TrimSuffix(iss, "/") != p.Issuer {
+		return nil, ErrInvalidIDToken
+	}
+	if !m.VerifyAudience(p.ClientID, true) {
+		if !audienceContains(m["aud"], p.ClientID) {
+			return nil, ErrInvalidIDToken
+		}
+	}
+	if _, ok := m["exp"]; !ok {
+		return nil, ErrInvalidIDToken
+	}
+	if got, _ := m["nonce"].(string); got != nonce {
+		return nil, ErrInvalidIDToken
+	}
+
+	claims := &Claims{Issuer: p.Issuer}
+	claims.Subject, _ = m["sub"].(string)
+	claims.Email, _ = m["email"].(string)
+	claims.Name, _ = m["name"].(string)
+	claims.PreferredUsername, _ = m["preferred_username"].(string)
+	switch v := m["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = v
+	case string:
+		claims.EmailVerified = v == "true"
+	}
+	if claims.Subject == "" {
+		return nil, ErrInvalidIDToken
+	}
+	return claims, nil
+}
+
+// audienceContains handles aud as an array, which jwt-go v3's VerifyAudience doesn't.
+func audienceContains(aud any, clientID string) bool {
+	list, ok := aud.([]any)
+	if !ok {
+		return false
+	}
+	for _, a := range list {
+		if s, _ := a.(string); s == clientID {
+			return true
+		}
+	}
+	return false
+}
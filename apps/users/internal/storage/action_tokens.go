@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// consumeMu serializes the read-then-delete in ConsumeActionToken so a token can only be redeemed once.
+var consumeMu sync.Mutex
+
+func actionTokenKey(tokenID string) []byte {
+	return []byte(fmt.Sprintf("auth_action:%s", tokenID))
+}
+
+// PutActionToken records an outstanding single-use token for userID that expires at expiresAt (UNIX seconds).
+func PutActionToken(tokenID, userID string, expiresAt int64) error {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(expiresAt))
+	return Client.Set(actionTokenKey(tokenID), append(buf[:], userID...), pebble.Sync)
+}
+
+// ConsumeActionToken deletes the outstanding token and returns the user it was issued to.
+// ok is false when the token was never issued, was already used, or has expired.
+func ConsumeActionToken(tokenID string) (userID string, ok bool, err error) {
+	consumeMu.Lock()
+	defer consumeMu.Unlock()
+
+	key := actionTokenKey(tokenID)
+	v, closer, err := Client.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if len(v) < 8 {
+		closer.Close()
+		return "", false, Client.Delete(key, pebble.Sync)
+	}
+	expiresAt := int64(binary.BigEndian.Uint64(v[:8]))
+	userID = string(v[8:])
+	closer.Close()
+
+	if err = Client.Delete(key, pebble.Sync); err != nil {
+		return "", false, err
+	}
+	if time.Now().Unix() > expiresAt {
+		return "", false, nil
+	}
+	return userID, true, nil
+}
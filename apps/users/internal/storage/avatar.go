@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"slices"
+
+	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+	"github.com/cockroachdb/pebble"
+)
+
+func avatarKey(userID string) []byte {
+	return models.MakeKey("avatar", userID)
+}
+
+// ReadAvatar returns the stored avatar image bytes, or ErrUserNotFound when there is none.
+func ReadAvatar(userID string) ([]byte, error) {
+	v, closer, err := Client.Get(avatarKey(userID))
+	if err == pebble.ErrNotFound {
+		return nil, models.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return slices.Clone(v), nil
+}
+
+func WriteAvatar(userID string, image []byte) error {
+	return Client.Set(avatarKey(userID), image, pebble.Sync)
+}
+
+func DeleteAvatar(userID string) error {
+	return Client.Delete(avatarKey(userID), pebble.Sync)
+}
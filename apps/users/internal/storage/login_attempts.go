@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"encoding/json"
+
+	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+	"github.com/cockroachdb/pebble"
+)
+
+func loginAttemptsKey(scope, subject string) []byte {
+	return models.MakeKey("login_attempts", scope+":"+subject)
+}
+
+// ReadLoginAttempts returns the failure record for subject within scope ("user", "totp" or "ip").
+// A missing record reads as zero failures.
+func ReadLoginAttempts(scope, subject string) (*models.LoginAttempts, error) {
+	var attempts models.LoginAttempts
+	v, closer, err := Client.Get(loginAttemptsKey(scope, subject))
+	if err == pebble.ErrNotFound {
+		return &attempts, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	err = json.Unmarshal(v, &attempts)
+	return &attempts, err
+}
+
+func WriteLoginAttempts(scope, subject string, attempts *models.LoginAttempts) error {
+	j, err := json.Marshal(attempts)
+	if err != nil {
+		return err
+	}
+	return Client.Set(loginAttemptsKey(scope, subject), j, pebble.Sync)
+}
+
+func DeleteLoginAttempts(scope, subject string) error {
+	return Client.Delete(loginAttemptsKey(scope, subject), pebble.Sync)
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+	"github.com/cockroachdb/pebble"
+	"github.com/labstack/echo"
+)
+
+// schemaVersionKey holds how many of migrations the database has had.
+var schemaVersionKey = []byte("schema_version")
+
+// migration brings data written by older releases up to date, staging its writes in
+// batch. It returns how many records it changed.
+type migration struct {
+	name string
+	run  func(c echo.Context, batch *pebble.Batch) (int, error)
+}
+
+// migrations run in order, each once; only ever append to them.
+var migrations = []migration{
+	{"unique_claims", migrateUniqueClaims},
+}
+
+// Migrate runs the migrations the database hasn't had yet. Each commits together with
+// the new schema version, so a failed one runs again on the next start.
+func Migrate(c echo.Context) error {
+	uniqueMu.Lock()
+	defer uniqueMu.Unlock()
+
+	version, err := schemaVersion()
+	if err != nil {
+		return err
+	}
+	for i := version; i < len(migrations); i++ {
+		m := migrations[i]
+		batch := Client.NewIndexedBatch()
+		n, err := m.run(c, batch)
+		if err == nil {
+			err = batch.Set(schemaVersionKey, binary.BigEndian.AppendUint64(nil, uint64(i+1)), pebble.Sync)
+		}
+		if err == nil {
+			err = batch.Commit(pebble.Sync)
+		}
+		batch.Close()
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", m.name, err)
+		}
+		c.Logger().Infof("migration %s changed %d records", m.name, n)
+	}
+	return nil
+}
+
+func schemaVersion() (int, error) {
+	v, closer, err := Client.Get(schemaVersionKey)
+	if err == pebble.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer closer.Close()
+	return int(binary.BigEndian.Uint64(v)), nil
+}
+
+// migrateUniqueClaims claims the usernames and contacts of users stored before they
+// were unique, and moves their postings from the raw values to the normalized ones
+// lookups use. Users who already share a normalized value can't both keep it, so it
+// fails naming every clash for an operator to resolve first.
+func migrateUniqueClaims(c echo.Context, batch *pebble.Batch) (int, error) {
+	prefix := models.MakeKey("user_id", "")
+	iter, err := Client.NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: append(prefix[:len(prefix):len(prefix)], 0xff),
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Close()
+
+	var clashes []error
+	migrated := 0
+	for valid := iter.First(); valid; valid = iter.Next() {
+		var user models.User
+		if err = json.Unmarshal(iter.Value(), &user); err != nil {
+			return 0, err
+		}
+		for field, key := range user.UniqueClaims() {
+			owner, closer, err := batch.Get(key)
+			if err == pebble.ErrNotFound {
+				if err = batch.Set(key, []byte(user.ID), pebble.Sync); err != nil {
+					return 0, err
+				}
+				continue
+			}
+			if err != nil {
+				return 0, err
+			}
+			if other := string(owner); other != user.ID {
+				clashes = append(clashes, fmt.Errorf("users %s and %s have the same %s", other, user.ID, field))
+			}
+			closer.Close()
+		}
+
+		numID, ok, err := Lookup(Client, user.ID)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			continue
+		}
+		// the raw values' postings, from when these fields were equality indexed
+		var raw [][]byte
+		if key := models.MakeKey("username", user.Username); user.Username != "" && string(key) != string(models.IndexKey("username", user.Username)) {
+			raw = append(raw, key)
+		}
+		if key := models.MakeKey("contact", user.Contact); user.Contact != "" && string(key) != string(models.IndexKey("contact", user.Contact)) {
+			raw = append(raw, key)
+		}
+		if err = removeTokens(batch, raw, numID); err != nil {
+			return 0, err
+		}
+		if err = writeTokens(c, batch, &user, numID); err != nil {
+			return 0, err
+		}
+		migrated++
+	}
+	if err = iter.Error(); err != nil {
+		return 0, err
+	}
+	if len(clashes) > 0 {
+		return 0, errors.Join(clashes...)
+	}
+	return migrated, nil
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+	"github.com/cockroachdb/pebble"
+)
+
+func identityKey(issuer, subject string) []byte {
+	return models.MakeKey("oidc_identity", issuer+"|"+subject)
+}
+
+// LookupIdentity returns the user an external identity is linked to.
+func LookupIdentity(issuer, subject string) (userID string, ok bool, err error) {
+	v, closer, err := Client.Get(identityKey(issuer, subject))
+	if errors.Is(err, pebble.ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	defer closer.Close()
+	return string(v), true, nil
+}
+
+func LinkIdentity(issuer, subject, userID string) error {
+	return Client.Set(identityKey(issuer, subject), []byte(userID), pebble.Sync)
+}
+
+func UnlinkIdentity(issuer, subject string) error {
+	return Client.Delete(identityKey(issuer, subject), pebble.Sync)
+}
+
+func PutOIDCState(state string, login *models.OIDCLoginState) error {
+	j, err := json.Marshal(login)
+	if err != nil {
+		return err
+	}
+	return Client.Set(models.MakeKey("oidc_state", state), j, pebble.Sync)
+}
+
+// ConsumeOIDCState returns and deletes the login state; ok is false for unknown or expired states.
+func ConsumeOIDCState(state string) (login *models.OIDCLoginState, ok bool, err error) {
+	consumeMu.Lock()
+	defer consumeMu.Unlock()
+
+	key := models.MakeKey("oidc_state", state)
+	v, closer, err := Client.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	login = &models.OIDCLoginState{}
+	err = json.Unmarshal(v, login)
+	closer.Close()
+	if err != nil {
+		return nil, false, err
+	}
+	if err = Client.Delete(key, pebble.Sync); err != nil {
+		return nil, false, err
+	}
+	if time.Now().Unix() > login.ExpiresAt {
+		return nil, false, nil
+	}
+	return login, true, nil
+}
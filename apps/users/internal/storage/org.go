@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"encoding/json"
+
+	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+	"github.com/cockroachdb/pebble"
+)
+
+// ReadOrgPolicy returns the stored policy for orgID, or the default policy when none was saved.
+func ReadOrgPolicy(orgID string) (*models.OrgPolicy, error) {
+	v, closer, err := Client.Get(models.MakeKey("org_policy", orgID))
+	if err == pebble.ErrNotFound {
+		return models.NewOrgPolicy(orgID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	var policy models.OrgPolicy
+	err = json.Unmarshal(v, &policy)
+	return &policy, err
+}
+
+func WriteOrgPolicy(policy *models.OrgPolicy) error {
+	j, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	return Client.Set(models.MakeKey("org_policy", policy.OrgID), j, pebble.Sync)
+}
@@ -10,12 +10,20 @@ import (
 var Client *pebble.DB
 
 func Initialize(ctx context.Context) {
-	db, err := pebble.Open("/tmp/test.db", &pebble.Options{
+	if err := Open("/tmp/test.db"); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Pebble DB initialized")
+}
+
+// Open opens the database at path as Client, such as a scratch one for a test.
+func Open(path string) error {
+	db, err := pebble.Open(path, &pebble.Options{
 		ErrorIfExists: false,
 	})
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	log.Println("Pebble DB initialized")
 	Client = db
+	return nil
 }
@@ -3,23 +3,49 @@ package storage
 import (
     "encoding/binary"
     "fmt"
+    "time"
 )
 
 func revokedKey(userID string) []byte {
+    return []byte(fmt.Sprintf("auth_revoked_before_ms:%s", userID))
+}
+
+// legacyRevokedKey held a UNIX second; tokens issued in or before it are revoked.
+func legacyRevokedKey(userID string) []byte {
     return []byte(fmt.Sprintf("auth_revoked_before:%s", userID))
 }
 
-// SetRevokedBefore sets the UNIX timestamp before which tokens are revoked for a user.
-func SetRevokedBefore(userID string, ts int64) error {
+// SetRevokedBefore revokes a user's tokens issued before t, to the millisecond, so a
+// token issued right after it stays valid.
+func SetRevokedBefore(userID string, t time.Time) error {
     var buf [8]byte
-    binary.BigEndian.PutUint64(buf[:], uint64(ts))
+    binary.BigEndian.PutUint64(buf[:], uint64(t.UnixMilli()))
     return Client.Set(revokedKey(userID), buf[:], nil)
 }
 
-// GetRevokedBefore returns the UNIX timestamp before which tokens are revoked for a user.
-// If not set, returns 0 and nil error.
-func GetRevokedBefore(userID string) (int64, error) {
-    v, closer, err := Client.Get(revokedKey(userID))
+// GetRevokedBefore returns the time before which a user's tokens are revoked.
+// If not set, returns the zero time and nil error.
+func GetRevokedBefore(userID string) (time.Time, error) {
+    ms, err := readRevoked(revokedKey(userID))
+    if err != nil {
+        return time.Time{}, err
+    }
+    seconds, err := readRevoked(legacyRevokedKey(userID))
+    if err != nil {
+        return time.Time{}, err
+    }
+    before := time.Time{}
+    if ms > 0 {
+        before = time.UnixMilli(ms)
+    }
+    if legacy := time.Unix(seconds+1, 0); seconds > 0 && legacy.After(before) {
+        before = legacy
+    }
+    return before, nil
+}
+
+func readRevoked(key []byte) (int64, error) {
+    v, closer, err := Client.Get(key)
     if err != nil {
         if err.Error() == "pebble: not found" {
             return 0, nil
@@ -30,6 +56,5 @@ func GetRevokedBefore(userID string) (int64, error) {
     if len(v) < 8 {
         return 0, nil
     }
-    ts := int64(binary.BigEndian.Uint64(v[:8]))
-    return ts, nil
+    return int64(binary.BigEndian.Uint64(v[:8])), nil
 }
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/Taiterbase/vtrips/apps/users/pkg/models"
+	"github.com/cockroachdb/pebble"
+)
+
+// uniqueMu serializes claim checks so two concurrent writers cannot both see a
+// value as free before either batch commits.
+var uniqueMu sync.Mutex
+
+// claimUnique stages the user's unique claims in batch, returning *models.ErrConflict
+// when another user already owns one. Claims from prev that the user no longer holds
+// are released. Callers must hold uniqueMu until the batch commits.
+func claimUnique(batch *pebble.Batch, user, prev *models.User) error {
+	claims := user.UniqueClaims()
+	for field, key := range claims {
+		owner, closer, err := batch.Get(key)
+		if err == pebble.ErrNotFound {
+			if err = batch.Set(key, []byte(user.GetID()), pebble.Sync); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		taken := string(owner) != user.GetID()
+		closer.Close()
+		if taken {
+			return &models.ErrConflict{Field: field}
+		}
+	}
+	if prev == nil {
+		return nil
+	}
+	return releaseUnique(batch, prev, claims)
+}
+
+// releaseUnique deletes prev's claim keys that are not present in keep.
+func releaseUnique(batch *pebble.Batch, prev *models.User, keep map[string][]byte) error {
+	for field, key := range prev.UniqueClaims() {
+		if k, ok := keep[field]; ok && string(k) == string(key) {
+			continue
+		}
+		if err := batch.Delete(key, pebble.Sync); err != nil {
+			return err
+		}
+	}
+	return nil
+}
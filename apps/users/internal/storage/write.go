@@ -75,13 +75,54 @@ func writeTokens(c echo.Context, batch *pebble.Batch, user *models.User, numeric
 	return nil
 }
 
+// removeTokens takes numericID out of the postings for tokens, deleting emptied ones.
+func removeTokens(batch *pebble.Batch, tokens [][]byte, numericID uint64) error {
+	for _, tk := range tokens {
+		data, closer, err := batch.Get(tk)
+		if err == pebble.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		rb, err := decode(data)
+		closer.Close()
+		if err != nil {
+			return err
+		}
+		if rb.Remove(numericID); rb.IsEmpty() {
+			if err = batch.Delete(tk, pebble.Sync); err != nil {
+				return err
+			}
+			continue
+		}
+		blob, err := encode(rb)
+		if err != nil {
+			return err
+		}
+		if err = batch.Set(tk, blob, pebble.Sync); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateUser stores a new user and its postings. It returns *models.ErrConflict when
+// a unique field is already claimed by another user.
 func CreateUser(c echo.Context, user *models.User) error {
+	uniqueMu.Lock()
+	defer uniqueMu.Unlock()
+
+	batch := Client.NewIndexedBatch()
+	defer batch.Close()
+	if err := claimUnique(batch, user, nil); err != nil {
+		return err
+	}
+
 	numID, err := GetOrAllocate(Client, user.GetID())
 	if err != nil {
 		return err
 	}
-	batch := Client.NewIndexedBatch()
-	defer batch.Close()
 
 	keyUser := models.MakeKey("user_id", user.GetID())
 	j, err := json.Marshal(user)
@@ -100,6 +141,9 @@ func CreateUser(c echo.Context, user *models.User) error {
 
 // DeleteUser removes the user object and its posting-list entries.
 func DeleteUser(c echo.Context, user *models.User) error {
+	uniqueMu.Lock()
+	defer uniqueMu.Unlock()
+
 	batch := Client.NewIndexedBatch()
 	defer batch.Close()
 
@@ -120,7 +164,7 @@ func DeleteUser(c echo.Context, user *models.User) error {
 	}
 	defer closer.Close()
 
-    var oldUser models.User
+	var oldUser models.User
 	if err = json.Unmarshal(oldBytes, &oldUser); err != nil {
 		return err
 	}
@@ -158,6 +202,9 @@ func DeleteUser(c echo.Context, user *models.User) error {
 		}
 	}
 
+	if err = releaseUnique(batch, &oldUser, nil); err != nil {
+		return err
+	}
 	if err = batch.Delete(keyUser, nil); err != nil {
 		return err
 	}
@@ -166,7 +213,11 @@ func DeleteUser(c echo.Context, user *models.User) error {
 
 // UpdateUser overwrites the user JSON and refreshes all bitmap tokens.
 // Simplest strategy: delete old postings then re-add new ones.
+// Unique claims move with the user; a value held by someone else yields *models.ErrConflict.
 func UpdateUser(c echo.Context, user *models.User) error {
+	uniqueMu.Lock()
+	defer uniqueMu.Unlock()
+
 	batch := Client.NewIndexedBatch()
 	defer batch.Close()
 
@@ -189,10 +240,13 @@ func UpdateUser(c echo.Context, user *models.User) error {
 	}
 	defer closer.Close()
 
-    var prev models.User
+	var prev models.User
 	if err = json.Unmarshal(prevBytes, &prev); err != nil {
 		return err
 	}
+	if err = claimUnique(batch, user, &prev); err != nil {
+		return err
+	}
 
 	for _, tk := range prev.Tokenize() {
 		data, closeFn, err := batch.Get(tk)
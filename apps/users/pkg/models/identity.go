@@ -0,0 +1,20 @@
+package models
+
+// Identity is an external OpenID Connect account linked to a User.
+type Identity struct {
+	Provider string `json:"provider"`
+	Issuer   string `json:"issuer"`
+	Subject  string `json:"subject"`
+	Email    string `json:"email"`
+	LinkedAt int64  `json:"linked_at"`
+}
+
+// OIDCLoginState is what the users service remembers between redirecting to a provider and its callback.
+type OIDCLoginState struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+	// LinkUserID is set when a signed-in user started the flow to link another identity.
+	LinkUserID string `json:"link_user_id"`
+	ExpiresAt  int64  `json:"expires_at"`
+}
@@ -1,7 +1,46 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"reflect"
+)
 
 func MakeKey(field, value string) []byte {
 	return fmt.Appendf([]byte{}, fmt.Sprintf("%s:%s", field, value))
 }
+
+// uniqueFields holds the json names of User fields tagged index:"unique".
+var uniqueFields = func() map[string]bool {
+	fields := make(map[string]bool)
+	typ := reflect.TypeOf(User{})
+	for i := range typ.NumField() {
+		if typ.Field(i).Tag.Get("index") == "unique" {
+			fields[typ.Field(i).Tag.Get("json")] = true
+		}
+	}
+	return fields
+}()
+
+// setTokens holds the token names of User fields tagged index:"set", e.g. "skill".
+var setTokens = func() map[string]bool {
+	tokens := make(map[string]bool)
+	typ := reflect.TypeOf(User{})
+	for i := range typ.NumField() {
+		if typ.Field(i).Tag.Get("index") == "set" {
+			tokens[typ.Field(i).Tag.Get("token")] = true
+		}
+	}
+	return tokens
+}()
+
+// IndexKey builds the bitmap token key for a query on field, normalizing the value
+// the same way Tokenize does for unique and set fields.
+func IndexKey(field, value string) []byte {
+	switch {
+	case uniqueFields[field]:
+		value = NormalizeUnique(value)
+	case setTokens[field]:
+		value = NormalizeTag(value)
+	}
+	return MakeKey(field, value)
+}
@@ -0,0 +1,8 @@
+package models
+
+// LoginAttempts tracks recent failed logins for one username or client IP.
+type LoginAttempts struct {
+	Failures    int   `json:"failures"`
+	LastFailure int64 `json:"last_failure"`
+	LockedUntil int64 `json:"locked_until"`
+}
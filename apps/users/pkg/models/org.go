@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// OrgPolicy holds the security settings an organization applies to its members.
+type OrgPolicy struct {
+	OrgID string `json:"org_id"`
+	// RequireAdminTOTP forces members with the admin role to enroll in TOTP before they can sign in.
+	RequireAdminTOTP bool  `json:"require_admin_totp"`
+	UpdatedAt        int64 `json:"updated_at"`
+}
+
+// NewOrgPolicy returns the default (most permissive) policy for orgID.
+func NewOrgPolicy(orgID string) *OrgPolicy {
+	return &OrgPolicy{
+		OrgID:     orgID,
+		UpdatedAt: time.Now().Unix(),
+	}
+}
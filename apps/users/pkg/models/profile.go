@@ -0,0 +1,138 @@
+package models
+
+import "strings"
+
+// Profile is the account owner's view of a User. Credentials (password hash, TOTP
+// secrets, recovery codes) and the date of birth are stored but never serialized.
+type Profile struct {
+	PublicProfile
+	Contact          string           `json:"contact"`
+	ContactMethod    string           `json:"contact_method"`
+	ContactVerified  bool             `json:"contact_verified"`
+	OrgID            string           `json:"org_id"`
+	Role             string           `json:"role"`
+	TOTPEnabled      bool             `json:"totp_enabled"`
+	HasPassword      bool             `json:"has_password"`
+	Identities       []Identity       `json:"identities"`
+	HomeLatitude     float64          `json:"home_latitude"`
+	HomeLongitude    float64          `json:"home_longitude"`
+	EmergencyContact EmergencyContact `json:"emergency_contact"`
+	UpdatedAt        int64            `json:"updated_at"`
+}
+
+// PublicProfile is what any caller may see about a user: enough for an org to
+// match volunteers to trips, but no contact details or exact location.
+type PublicProfile struct {
+	ID             string               `json:"id"`
+	Username       string               `json:"username"`
+	Skills         []string             `json:"skills"`
+	Languages      []string             `json:"languages"`
+	Certifications []Certification      `json:"certifications"`
+	HomeCity       string               `json:"home_city"`
+	HomeCountry    string               `json:"home_country"`
+	Availability   []AvailabilityWindow `json:"availability"`
+	AvatarURL      string               `json:"avatar_url,omitempty"`
+	CreatedAt      int64                `json:"created_at"`
+}
+
+// Profile returns the owner's view of t.
+func (t *User) Profile() *Profile {
+	return &Profile{
+		PublicProfile:    *t.PublicProfile(),
+		Contact:          t.Contact,
+		ContactMethod:    t.ContactMethod,
+		ContactVerified:  t.ContactVerified,
+		OrgID:            t.OrgID,
+		Role:             t.Role,
+		TOTPEnabled:      t.TOTPEnabled,
+		HasPassword:      t.Hash != "",
+		Identities:       t.Identities,
+		HomeLatitude:     t.HomeLatitude,
+		HomeLongitude:    t.HomeLongitude,
+		EmergencyContact: t.EmergencyContact,
+		UpdatedAt:        t.UpdatedAt,
+	}
+}
+
+// PublicProfile returns the view of t that is safe to show other users.
+func (t *User) PublicProfile() *PublicProfile {
+	p := &PublicProfile{
+		ID:             t.ID,
+		Username:       t.Username,
+		Skills:         t.Skills,
+		Languages:      t.Languages,
+		Certifications: t.Certifications,
+		HomeCity:       t.HomeCity,
+		HomeCountry:    t.HomeCountry,
+		Availability:   t.Availability,
+		CreatedAt:      t.CreatedAt,
+	}
+	if t.AvatarType != "" {
+		p.AvatarURL = "/v1/users/" + t.ID + "/avatar"
+	}
+	return p
+}
+
+// ProfileUpdate holds the fields a user may change on their own account. Nil fields are left alone.
+type ProfileUpdate struct {
+	Username      *string `json:"username" form:"username"`
+	Contact       *string `json:"contact" form:"contact"`
+	ContactMethod *string `json:"contact_method" form:"contact_method"`
+	DOB           *string `json:"dob" form:"dob"`
+
+	Skills           *[]string             `json:"skills"`
+	Languages        *[]string             `json:"languages"`
+	Certifications   *[]Certification      `json:"certifications"`
+	HomeCity         *string               `json:"home_city" form:"home_city"`
+	HomeCountry      *string               `json:"home_country" form:"home_country"`
+	HomeLatitude     *float64              `json:"home_latitude" form:"home_latitude"`
+	HomeLongitude    *float64              `json:"home_longitude" form:"home_longitude"`
+	Availability     *[]AvailabilityWindow `json:"availability"`
+	EmergencyContact *EmergencyContact     `json:"emergency_contact"`
+}
+
+// Apply copies the set fields onto t and reports whether the contact changed.
+func (p *ProfileUpdate) Apply(t *User) (contactChanged bool) {
+	if p.Username != nil {
+		t.Username = *p.Username
+	}
+	if p.Contact != nil && *p.Contact != t.Contact {
+		t.Contact = *p.Contact
+		contactChanged = true
+	}
+	if p.ContactMethod != nil && *p.ContactMethod != t.ContactMethod {
+		t.ContactMethod = *p.ContactMethod
+		contactChanged = true
+	}
+	if p.DOB != nil {
+		t.DOB = *p.DOB
+	}
+	if p.Skills != nil {
+		t.Skills = normalizeTags(*p.Skills)
+	}
+	if p.Languages != nil {
+		t.Languages = normalizeTags(*p.Languages)
+	}
+	if p.Certifications != nil {
+		t.Certifications = mergeCertifications(t.Certifications, *p.Certifications)
+	}
+	if p.HomeCity != nil {
+		t.HomeCity = *p.HomeCity
+	}
+	if p.HomeCountry != nil {
+		t.HomeCountry = strings.ToUpper(*p.HomeCountry)
+	}
+	if p.HomeLatitude != nil {
+		t.HomeLatitude = *p.HomeLatitude
+	}
+	if p.HomeLongitude != nil {
+		t.HomeLongitude = *p.HomeLongitude
+	}
+	if p.Availability != nil {
+		t.Availability = *p.Availability
+	}
+	if p.EmergencyContact != nil {
+		t.EmergencyContact = *p.EmergencyContact
+	}
+	return contactChanged
+}
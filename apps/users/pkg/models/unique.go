@@ -0,0 +1,25 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrConflict reports a unique field whose value another user already holds.
+type ErrConflict struct {
+	Field string
+}
+
+func (e *ErrConflict) Error() string {
+	return fmt.Sprintf("%s already exists", e.Field)
+}
+
+// NormalizeUnique case-folds and NFKC-normalizes s so visually identical or
+// differently cased values compare equal ("Ａlice", "ALICE" and "alice" all collide).
+func NormalizeUnique(s string) string {
+	s = norm.NFKC.String(strings.TrimSpace(s))
+	return norm.NFKC.String(cases.Fold().String(s))
+}
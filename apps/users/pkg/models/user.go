@@ -5,7 +5,6 @@ import (
 	"reflect"
 	"time"
 
-	validate "github.com/go-playground/validator/v10"
 	"github.com/oklog/ulid/v2"
 )
 
@@ -15,15 +14,45 @@ var (
 	ErrInvalidOrgID   = fmt.Errorf("OrgID is required as a string")
 	ErrUserNotFound   = fmt.Errorf("User not found")
 	ErrOrgNotFound    = fmt.Errorf("OrgID not found")
+	ErrIdentityLinked = fmt.Errorf("Identity is linked to another user")
+)
+
+const (
+	RoleAdmin  = "admin"
+	RoleMember = "member"
 )
 
 type User struct {
-	ID            string `json:"id"`
-    Username      string `json:"username" db:"username" index:"equality"`
-    Hash          string `json:"hash" db:"hash"`
-    Contact       string `json:"contact" db:"contact" index:"equality"`
-    ContactMethod string `json:"contact_method" db:"contact_method"`
-	DOB           string `json:"dob" db:"dob"`
+	ID              string `json:"id"`
+	Username        string `json:"username" db:"username" index:"unique"`
+	Hash            string `json:"hash" db:"hash"`
+	Contact         string `json:"contact" db:"contact" index:"unique"`
+	ContactMethod   string `json:"contact_method" db:"contact_method"`
+	ContactVerified bool   `json:"contact_verified" db:"contact_verified"`
+	DOB             string `json:"dob" db:"dob"`
+
+	OrgID string `json:"org_id" db:"org_id" index:"equality"`
+	Role  string `json:"role" db:"role"`
+
+	TOTPEnabled        bool     `json:"totp_enabled" db:"totp_enabled"`
+	TOTPSecret         string   `json:"totp_secret" db:"totp_secret"`
+	TOTPPendingSecret  string   `json:"totp_pending_secret" db:"totp_pending_secret"`
+	TOTPLastStep       int64    `json:"totp_last_step" db:"totp_last_step"`
+	RecoveryCodeHashes []string `json:"recovery_code_hashes" db:"recovery_code_hashes"`
+
+	Identities []Identity `json:"identities" db:"identities"`
+
+	// volunteer profile; skills and languages are indexed one token per entry
+	Skills           []string             `json:"skills" db:"skills" index:"set" token:"skill" validate:"max=50,dive,max=64"`
+	Languages        []string             `json:"languages" db:"languages" index:"set" token:"language" validate:"max=20,dive,bcp47_language_tag"`
+	Certifications   []Certification      `json:"certifications" db:"certifications" validate:"max=50,dive"`
+	HomeCity         string               `json:"home_city" db:"home_city" index:"equality" validate:"max=128"`
+	HomeCountry      string               `json:"home_country" db:"home_country" index:"equality" validate:"omitempty,iso3166_1_alpha2"`
+	HomeLatitude     float64              `json:"home_latitude" db:"home_latitude" index:"geoposition" validate:"omitempty,latitude"`
+	HomeLongitude    float64              `json:"home_longitude" db:"home_longitude" index:"geoposition" validate:"omitempty,longitude"`
+	Availability     []AvailabilityWindow `json:"availability" db:"availability" validate:"max=50,dive"`
+	EmergencyContact EmergencyContact     `json:"emergency_contact" db:"emergency_contact"`
+	AvatarType       string               `json:"avatar_type" db:"avatar_type"`
 
 	CreatedAt int64 `json:"created_at" validate:"required" index:"time"`
 	UpdatedAt int64 `json:"updated_at" updateable:"true" index:"time"`
@@ -42,16 +71,16 @@ func NewUser() *User {
 
 // Validate validates the User struct
 func (t *User) Validate() error {
-	validate := validate.New()
-	return validate.Struct(t)
+	return validation.Struct(t)
 }
 
 // Accessors to match storage expectations (mirror trips model style)
-func (t *User) GetID() string { return t.ID }
-func (t *User) GetUpdatedAt() int64 { return t.UpdatedAt }
+func (t *User) GetID() string         { return t.ID }
+func (t *User) GetUpdatedAt() int64   { return t.UpdatedAt }
 func (t *User) SetUpdatedAt(ts int64) { t.UpdatedAt = ts }
-func (t *User) GetUsername() string { return t.Username }
-func (t *User) GetHash() string { return t.Hash }
+func (t *User) GetUsername() string   { return t.Username }
+func (t *User) GetHash() string       { return t.Hash }
+func (t *User) IsOrgAdmin() bool      { return t.OrgID != "" && t.Role == RoleAdmin }
 
 func GetDailyBucket(timestamp int64) int64 {
 	return timestamp - (timestamp % 86400)
@@ -78,7 +107,39 @@ func (t *User) Tokenize() [][]byte {
 			}
 			token := MakeKey(field.Tag.Get("json"), fmt.Sprintf("%v", value))
 			tokens = append(tokens, token)
+		case "set":
+			for _, value := range v.Field(i).Interface().([]string) {
+				if value = NormalizeTag(value); value != "" {
+					tokens = append(tokens, MakeKey(field.Tag.Get("token"), value))
+				}
+			}
+		case "unique":
+			value := v.Field(i).String()
+			if value == "" {
+				continue
+			}
+			tokens = append(tokens, MakeKey(field.Tag.Get("json"), NormalizeUnique(value)))
 		}
 	}
 	return tokens
 }
+
+// UniqueClaims returns the claim key for every non-empty field tagged index:"unique",
+// keyed by the field's json name. Storage owns one claim key per value so two users
+// can never hold the same normalized username or contact.
+func (t *User) UniqueClaims() map[string][]byte {
+	claims := make(map[string][]byte)
+	typ := reflect.TypeOf(*t)
+	v := reflect.ValueOf(*t)
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if field.Tag.Get("index") != "unique" {
+			continue
+		}
+		if value := v.Field(i).String(); value != "" {
+			name := field.Tag.Get("json")
+			claims[name] = MakeKey("unique", name+":"+NormalizeUnique(value))
+		}
+	}
+	return claims
+}
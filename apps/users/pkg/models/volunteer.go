@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInvalidAvailability   = fmt.Errorf("Availability window must end after it starts")
+	ErrCertificationNotFound = fmt.Errorf("Certification not found")
+)
+
+// Certification is a qualification such as first aid or a teaching license. Verified is
+// only set by an admin of the volunteer's org after checking the document.
+type Certification struct {
+	Name       string `json:"name" validate:"required,max=64"`
+	Issuer     string `json:"issuer" validate:"max=128"`
+	ExpiresAt  int64  `json:"expires_at"`
+	Verified   bool   `json:"verified"`
+	VerifiedBy string `json:"verified_by,omitempty"`
+	VerifiedAt int64  `json:"verified_at,omitempty"`
+}
+
+// AvailabilityWindow is a span of UNIX seconds during which a volunteer can travel.
+type AvailabilityWindow struct {
+	Start int64 `json:"start" validate:"required"`
+	End   int64 `json:"end" validate:"required,gtfield=Start"`
+}
+
+// EmergencyContact is who an org calls if something happens on a trip. It is never public.
+type EmergencyContact struct {
+	Name         string `json:"name" validate:"max=128"`
+	Phone        string `json:"phone" validate:"max=32"`
+	Relationship string `json:"relationship" validate:"max=64"`
+}
+
+// NormalizeTag lowercases a skill or language and turns spaces into underscores so
+// "First Aid" and "first_aid" index to the same token.
+func NormalizeTag(s string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), " ", "_")
+}
+
+// normalizeTags normalizes, drops empties and de-duplicates tags, keeping their order.
+func normalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = NormalizeTag(tag); tag != "" && !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// mergeCertifications replaces a user's certifications with next. Verification is kept
+// only for entries whose name, issuer and expiry are unchanged, so a volunteer can't
+// edit a document after an admin has checked it.
+func mergeCertifications(prev, next []Certification) []Certification {
+	out := make([]Certification, 0, len(next))
+	for _, cert := range next {
+		cert.Verified, cert.VerifiedBy, cert.VerifiedAt = false, "", 0
+		for _, old := range prev {
+			if old.Verified && old.Name == cert.Name && old.Issuer == cert.Issuer && old.ExpiresAt == cert.ExpiresAt {
+				cert.Verified, cert.VerifiedBy, cert.VerifiedAt = true, old.VerifiedBy, old.VerifiedAt
+				break
+			}
+		}
+		out = append(out, cert)
+	}
+	return out
+}
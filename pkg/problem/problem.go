@@ -0,0 +1,254 @@
+// Package problem answers failed requests with RFC 9457 problem details, so every error
+// the service returns has one shape: a stable code for programs, a detail for people,
+// the fields that failed validation, and the request's ID to find it in the logs.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	validate "github.com/go-playground/validator/v10"
+	"github.com/labstack/echo"
+)
+
+// MIMEApplicationProblemJSON is the content type of every error response.
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// The codes errors get from their status when nothing more specific was registered.
+const (
+	CodeBadRequest           = "bad_request"
+	CodeValidationFailed     = "validation_failed"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeConflict             = "conflict"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeRateLimited          = "rate_limited"
+	CodeInternal             = "internal_error"
+	CodeUnavailable          = "service_unavailable"
+)
+
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnprocessableEntity:   CodeValidationFailed,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusMethodNotAllowed:      CodeMethodNotAllowed,
+	http.StatusConflict:              CodeConflict,
+	http.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	http.StatusUnsupportedMediaType:  CodeUnsupportedMediaType,
+	http.StatusTooManyRequests:       CodeRateLimited,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+}
+
+// serverDetails stand in for the detail of server errors, whose own text is about our
+// internals; serverDetail covers the statuses not listed.
+var serverDetails = map[int]string{
+	http.StatusBadGateway:         "A service we depend on failed",
+	http.StatusServiceUnavailable: "The service is unavailable; try again later",
+	http.StatusGatewayTimeout:     "A service we depend on took too long",
+}
+
+const serverDetail = "Something went wrong on our side"
+
+// Problem is the body of every error response.
+type Problem struct {
+	// Type is always about:blank; Code says what kind of problem it is
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Code   string `json:"code"`
+	Detail string `json:"detail,omitempty"`
+	// Errors are the fields that failed, for validation_failed
+	Errors    []FieldError `json:"errors,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// FieldError is one field of a request that failed validation.
+type FieldError struct {
+	// Field is the field's JSON path, such as questions[0].prompt
+	Field string `json:"field"`
+	// Rule is the check the field failed, such as required or max
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Error is an error with the code clients see for it, for errors no registered code
+// covers.
+type Error struct {
+	Code   string
+	Err    error
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Invalid is a validation_failed error for the given fields.
+func Invalid(err error, fields ...FieldError) *Error {
+	return &Error{Code: CodeValidationFailed, Err: err, Fields: fields}
+}
+
+// fieldError is an error about one field of a request, such as an unknown enum name.
+type fieldError interface {
+	InvalidField() (field, rule string)
+}
+
+var registered []struct {
+	err  error
+	code string
+}
+
+// Register gives errors, such as a package's sentinel errors, the code clients see for
+// them. Call it from init, before the service answers requests.
+func Register(code string, errs ...error) {
+	for _, err := range errs {
+		registered = append(registered, struct {
+			err  error
+			code string
+		}{err, code})
+	}
+}
+
+// New builds the problem for answering with err at status. Codes come from an *Error in
+// err's chain, then validator errors and errors about one field, then Register, then
+// the status. Server errors keep their detail out of the response; the request ID
+// finds it in the logs.
+func New(status int, err error) Problem {
+	p := Problem{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: err.Error()}
+	var (
+		coded   *Error
+		invalid validate.ValidationErrors
+		field   fieldError
+	)
+	switch {
+	case errors.As(err, &coded):
+		p.Code, p.Errors = coded.Code, coded.Fields
+	case errors.As(err, &invalid):
+		p.Code, p.Detail, p.Errors = CodeValidationFailed, "Some fields are invalid", FieldErrors(invalid)
+	case errors.As(err, &field):
+		name, rule := field.InvalidField()
+		p.Code, p.Errors = CodeValidationFailed, []FieldError{{Field: name, Rule: rule, Message: err.Error()}}
+	default:
+		for _, r := range registered {
+			if errors.Is(err, r.err) {
+				p.Code = r.code
+				break
+			}
+		}
+	}
+	if p.Code == "" {
+		p.Code = statusCodes[status]
+	}
+	if p.Code == "" {
+		p.Code = CodeInternal
+		if status < 500 {
+			p.Code = CodeBadRequest
+		}
+	}
+	if status >= 500 {
+		p.Detail = serverDetails[status]
+		if p.Detail == "" {
+			p.Detail = serverDetail
+		}
+	}
+	return p
+}
+
+// JSON answers with err as a problem; see New.
+func JSON(c echo.Context, status int, err error) error {
+	p := New(status, err)
+	p.RequestID = c.Response().Header().Get(echo.HeaderXRequestID)
+	if status >= 500 {
+		c.Logger().Errorf("request %s: %v", p.RequestID, err)
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return c.Blob(status, MIMEApplicationProblemJSON, b)
+}
+
+// Message answers with a problem whose detail is msg and whose code is the status's.
+func Message(c echo.Context, status int, msg string) error {
+	return JSON(c, status, errors.New(msg))
+}
+
+// HTTPErrorHandler answers errors handlers return, rather than write, as problems: the
+// router's 404s and 405s, middleware rejections and panics.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+	status, cause := http.StatusInternalServerError, err
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		status, cause = he.Code, fmt.Errorf("%v", he.Message)
+		if he.Internal != nil {
+			cause = fmt.Errorf("%v: %w", he.Message, he.Internal)
+		}
+	}
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(status)
+	} else {
+		err = JSON(c, status, cause)
+	}
+	if err != nil {
+		c.Logger().Error(err)
+	}
+}
+
+// FieldErrors lists the fields a validator rejected. Fields are named by their JSON
+// tags when the validator was given a tag name function for them.
+func FieldErrors(invalid validate.ValidationErrors) []FieldError {
+	fields := make([]FieldError, 0, len(invalid))
+	for _, fe := range invalid {
+		// namespaces start with the struct's type, such as TripBase.questions[0].prompt
+		_, field, ok := strings.Cut(fe.Namespace(), ".")
+		if !ok {
+			field = fe.Field()
+		}
+		fields = append(fields, FieldError{Field: field, Rule: fe.Tag(), Message: fieldMessage(fe)})
+	}
+	return fields
+}
+
+func fieldMessage(fe validate.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_with", "required_without", "required_if":
+		return "is required"
+	case "min", "gte":
+		return "must be at least " + fe.Param()
+	case "max", "lte":
+		return "must be at most " + fe.Param()
+	case "gt":
+		return "must be more than " + fe.Param()
+	case "lt":
+		return "must be less than " + fe.Param()
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "iso4217":
+		return "must be an ISO 4217 currency code such as USD"
+	case "iso3166_1_alpha2":
+		return "must be a two-letter country code such as US"
+	case "email":
+		return "must be an email address"
+	case "latitude":
+		return "must be a latitude from -90 to 90"
+	case "longitude":
+		return "must be a longitude from -180 to 180"
+	default:
+		return fmt.Sprintf("failed %s validation", fe.Tag())
+	}
+}
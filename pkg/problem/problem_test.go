@@ -0,0 +1,39 @@
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+var errTaken = errors.New("username taken")
+
+func init() {
+	Register("username_taken", errTaken)
+}
+
+func TestNew(t *testing.T) {
+	internal := errors.New(`oidc: token endpoint returned 500: {"secret": "x"}`)
+	for _, tc := range []struct {
+		status int
+		err    error
+		code   string
+		detail string
+	}{
+		{http.StatusNotFound, errors.New("trip not found"), CodeNotFound, "trip not found"},
+		{http.StatusConflict, fmt.Errorf("signing up: %w", errTaken), "username_taken", "signing up: username taken"},
+		{http.StatusBadRequest, Invalid(errors.New("bad role"), FieldError{Field: "role", Rule: "oneof"}), CodeValidationFailed, "bad role"},
+		{http.StatusTeapot, errors.New("short and stout"), CodeBadRequest, "short and stout"},
+		{http.StatusInternalServerError, internal, CodeInternal, "Something went wrong on our side"},
+		{http.StatusBadGateway, internal, CodeInternal, "A service we depend on failed"},
+		{http.StatusServiceUnavailable, internal, CodeUnavailable, "The service is unavailable; try again later"},
+		{http.StatusGatewayTimeout, internal, CodeInternal, "A service we depend on took too long"},
+		{http.StatusNotImplemented, internal, CodeInternal, "Something went wrong on our side"},
+	} {
+		p := New(tc.status, tc.err)
+		if p.Status != tc.status || p.Code != tc.code || p.Detail != tc.detail {
+			t.Errorf("New(%d, %q) = %d %s %q; want %s %q", tc.status, tc.err, p.Status, p.Code, p.Detail, tc.code, tc.detail)
+		}
+	}
+}